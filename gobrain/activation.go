@@ -0,0 +1,129 @@
+package gobrain
+
+import (
+	"fmt"
+	"math"
+)
+
+// Activation is the activation function of a layer, applied to the
+// layer's weighted sums.
+type Activation interface {
+	// Forward applies the function to the weighted sum x.
+	Forward(e Evaluator, x Ciphertext) (Ciphertext, error)
+	// Derivative returns the derivative at the weighted sum x times grad.
+	// Taking grad lets linear functions skip a multiplication.
+	Derivative(e Evaluator, x, grad Ciphertext) (Ciphertext, error)
+	// Depth is the number of levels Forward consumes.
+	Depth() int
+}
+
+// Identity is the linear activation f(x) = x.
+type Identity struct{}
+
+func (Identity) Forward(e Evaluator, x Ciphertext) (Ciphertext, error) {
+	return x, nil
+}
+
+func (Identity) Derivative(e Evaluator, x, grad Ciphertext) (Ciphertext, error) {
+	return grad, nil
+}
+
+func (Identity) Depth() int {
+	return 0
+}
+
+// Square is the activation f(x) = x^2, the cheapest nonlinear one.
+type Square struct{}
+
+func (Square) Forward(e Evaluator, x Ciphertext) (Ciphertext, error) {
+	return e.Square(x)
+}
+
+func (Square) Derivative(e Evaluator, x, grad Ciphertext) (Ciphertext, error) {
+	d, err := e.MulConst(x, 2)
+	if err != nil {
+		return nil, err
+	}
+	return e.Mul(d, grad)
+}
+
+func (Square) Depth() int {
+	return 1
+}
+
+// PolyActivation approximates a function by a polynomial over
+// [-Bound, Bound]. Outside the interval the polynomial quickly diverges, so
+// the weighted sums fed to it must stay inside.
+type PolyActivation struct {
+	Bound float64
+	// Poly approximates the function and Deriv is its exact derivative, so
+	// training follows the function the network computes.
+	Poly, Deriv *Polynomial
+}
+
+// NewPolyActivation fits f with a polynomial of the given degree over
+// [-bound, bound].
+func NewPolyActivation(f func(float64) float64, degree int, bound float64, fit Fit) (*PolyActivation, error) {
+	if degree < 1 {
+		return nil, fmt.Errorf("gobrain: activation degree %d is below 1", degree)
+	}
+	if bound <= 0 {
+		return nil, fmt.Errorf("gobrain: activation bound %g is not positive", bound)
+	}
+	p, err := Approximate(f, degree, -bound, bound, fit)
+	if err != nil {
+		return nil, err
+	}
+	return &PolyActivation{Bound: bound, Poly: p, Deriv: p.Derivative()}, nil
+}
+
+// NewTanh approximates the hyperbolic tangent.
+func NewTanh(degree int, bound float64, fit Fit) (*PolyActivation, error) {
+	return NewPolyActivation(math.Tanh, degree, bound, fit)
+}
+
+// NewReLU approximates max(0, x). Its kink at zero makes it converge much
+// more slowly with the degree than the smooth functions.
+func NewReLU(degree int, bound float64, fit Fit) (*PolyActivation, error) {
+	return NewPolyActivation(func(x float64) float64 { return math.Max(0, x) }, degree, bound, fit)
+}
+
+func (a *PolyActivation) Forward(e Evaluator, x Ciphertext) (Ciphertext, error) {
+	return e.EvaluatePolynomial(x, a.Poly)
+}
+
+func (a *PolyActivation) Derivative(e Evaluator, x, grad Ciphertext) (Ciphertext, error) {
+	d, err := e.EvaluatePolynomial(x, a.Deriv)
+	if err != nil {
+		return nil, err
+	}
+	return e.Mul(d, grad)
+}
+
+func (a *PolyActivation) Depth() int {
+	return a.Poly.Depth()
+}
+
+// defaultActivations sets missing activations to the default sigmoid, or
+// Identity for a regression output, and rejects a nonlinear regression
+// output.
+func defaultActivations(hidden, output *Activation, regression bool) error {
+	var err error
+	if *hidden == nil {
+		if *hidden, err = NewSigmoid(DefaultSigmoidDegree, DefaultSigmoidBound, ChebyshevFit); err != nil {
+			return err
+		}
+	}
+	if regression {
+		if *output == nil {
+			*output = Identity{}
+		} else if _, ok := (*output).(Identity); !ok {
+			return fmt.Errorf("gobrain: regression needs an Identity output, not %T", *output)
+		}
+	} else if *output == nil {
+		if *output, err = NewSigmoid(DefaultSigmoidDegree, DefaultSigmoidBound, ChebyshevFit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
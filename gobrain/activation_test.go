@@ -0,0 +1,88 @@
+package gobrain
+
+import (
+	"math"
+	"testing"
+
+	"github.com/d4l3k/go-fheml/sim"
+)
+
+func TestActivations(t *testing.T) {
+	b, d := newSimBackend(t)
+	encrypt := func(v float64) Ciphertext {
+		p, err := b.Encode(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := b.Encrypt(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	decrypt := func(c Ciphertext) float64 {
+		p, err := d.Decrypt(c.(*sim.Ciphertext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := b.Encoder.Decode(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	sigmoid, err := NewSigmoid(DefaultSigmoidDegree, DefaultSigmoidBound, ChebyshevFit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tanh, err := NewTanh(11, 4, ChebyshevFit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	relu, err := NewReLU(16, 4, LeastSquaresFit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		a        Activation
+		f, df    func(float64) float64
+		maxError float64
+	}{
+		{"identity", Identity{}, func(x float64) float64 { return x }, func(float64) float64 { return 1 }, 1e-9},
+		{"square", Square{}, func(x float64) float64 { return x * x }, func(x float64) float64 { return 2 * x }, 1e-9},
+		{"sigmoid", sigmoid, func(x float64) float64 { return 1 / (1 + math.Exp(-x)) }, nil, 0.035},
+		{"tanh", tanh, math.Tanh, nil, 0.02},
+		{"relu", relu, func(x float64) float64 { return math.Max(0, x) }, nil, 0.1},
+	}
+	for _, tt := range tests {
+		for _, x := range []float64{-3.5, -1, 0.25, 2} {
+			in := encrypt(x)
+			out, err := tt.a.Forward(b, in)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if got := decrypt(out); math.Abs(got-tt.f(x)) > tt.maxError {
+				t.Errorf("%s(%g) = %g; want %g within %g", tt.name, x, got, tt.f(x), tt.maxError)
+			}
+			if used := in.(*sim.Ciphertext).Level() - out.(*sim.Ciphertext).Level(); used != tt.a.Depth() {
+				t.Errorf("%s: Forward used %d levels; Depth() = %d", tt.name, used, tt.a.Depth())
+			}
+
+			grad, err := tt.a.Derivative(b, in, encrypt(0.5))
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			want := 0.0
+			if tt.df != nil {
+				want = 0.5 * tt.df(x)
+			} else {
+				want = 0.5 * tt.a.(*PolyActivation).Deriv.Evaluate(x)
+			}
+			if got := decrypt(grad); math.Abs(got-want) > 1e-6 {
+				t.Errorf("%s'(%g) * 0.5 = %g; want %g", tt.name, x, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package gobrain
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrBackendMismatch is returned when a backend is handed a value created by
+// a different backend.
+var ErrBackendMismatch = errors.New("gobrain: value belongs to a different backend")
+
+// Ciphertext is an encrypted value. Its dynamic type is defined by the
+// backend that created it, e.g. *seal.Ciphertext.
+type Ciphertext interface{}
+
+// Plaintext is an encoded, unencrypted value of a backend.
+type Plaintext interface{}
+
+// Encoder encodes constants.
+type Encoder interface {
+	Encode(v float64) (Plaintext, error)
+}
+
+// Encryptor encrypts encoded values.
+type Encryptor interface {
+	Encrypt(p Plaintext) (Ciphertext, error)
+}
+
+// Evaluator computes on ciphertexts. Its methods must not modify their
+// arguments, and Add and Sub must accept any two ciphertexts produced by the
+// evaluator, whatever their level or scale.
+type Evaluator interface {
+	Add(a, b Ciphertext) (Ciphertext, error)
+	Sub(a, b Ciphertext) (Ciphertext, error)
+	// Mul and Square return finished products, relinearized and
+	// rescaled.
+	Mul(a, b Ciphertext) (Ciphertext, error)
+	Square(a Ciphertext) (Ciphertext, error)
+	Negate(a Ciphertext) (Ciphertext, error)
+	AddConst(a Ciphertext, v float64) (Ciphertext, error)
+	MulConst(a Ciphertext, v float64) (Ciphertext, error)
+	// EvaluatePolynomial evaluates p on a, relinearizing and rescaling as
+	// it goes.
+	EvaluatePolynomial(a Ciphertext, p *Polynomial) (Ciphertext, error)
+	// Level returns the number of rescales a has left.
+	Level(a Ciphertext) (int, error)
+}
+
+// Packer is implemented by backends that can hold a vector in the slots of
+// one ciphertext, which packed inference needs. Encode puts the same value
+// in every slot, and AddConst and MulConst act on every slot, so packed
+// ciphertexts go through the activations like scalar ones.
+type Packer interface {
+	// Slots returns the number of values a ciphertext holds.
+	Slots() int
+	EncodeVector(v []float64) (Plaintext, error)
+	// AddVector and MulVector combine the slots of a with the values of
+	// v, missing values being zero. MulVector rescales like MulConst.
+	AddVector(a Ciphertext, v []float64) (Ciphertext, error)
+	MulVector(a Ciphertext, v []float64) (Ciphertext, error)
+	// Rotate rotates the slots of a left by steps; negative steps rotate
+	// right.
+	Rotate(a Ciphertext, steps int) (Ciphertext, error)
+}
+
+// Decrypter recovers the value of a ciphertext. It needs the secret key, so
+// it is kept apart from the backend and only its holder can monitor
+// training with it.
+type Decrypter interface {
+	Decrypt(c Ciphertext) (float64, error)
+}
+
+// Serializer is implemented by backends whose ciphertexts can be saved,
+// which FeedForward.Save and Load need.
+type Serializer interface {
+	SaveCiphertext(w io.Writer, c Ciphertext) error
+	// LoadCiphertext reads a ciphertext written by SaveCiphertext and
+	// checks that it was made under the backend's encryption parameters.
+	LoadCiphertext(r io.Reader) (Ciphertext, error)
+}
+
+func mismatch(op string, v interface{}) error {
+	return fmt.Errorf("gobrain: %s: unexpected %T: %w", op, v, ErrBackendMismatch)
+}
@@ -2,31 +2,44 @@
 package gobrain
 
 import (
+	"errors"
 	"fmt"
-	"log"
-
-	"github.com/d4l3k/go-fheml/seal"
+	"math/rand"
 )
 
+// ErrShape is returned for inputs or targets that do not match the layer
+// sizes of a network.
+var ErrShape = errors.New("gobrain: wrong number of values")
+
 // FeedForwad struct is used to represent a simple neural network
 type FeedForward struct {
-	Encryptor *seal.Encryptor
-	Evaluator *seal.Evaluator
-	Encoder   *seal.CKKSEncoder
-	RelinKeys *seal.RelinKeys
+	// Backend operations; see Sim and package sealbackend.
+	Encryptor Encryptor
+	Evaluator Evaluator
+	Encoder   Encoder
 
 	// Number of input, hidden and output nodes
 	NInputs, NHiddens, NOutputs int
-	// Whether it is regression or not
+	// Whether it is regression or not; regression uses a linear output
 	Regression bool
+	// Activation functions of the hidden and output layers; Init picks
+	// the default sigmoid, or Identity for a regression output, if nil
+	HiddenFunc, OutputFunc Activation
 	// Activations for nodes
-	InputActivations, HiddenActivations, OutputActivations []*seal.Ciphertext
+	InputActivations, HiddenActivations, OutputActivations []Ciphertext
+	// Cleartext inputs of the last UpdatePlain, bias included, which
+	// BackPropagate uses in place of InputActivations; nil after Update
+	PlainInputs []float64
+	// Weighted sums fed to the activations, kept for BackPropagate
+	HiddenSums, OutputSums []Ciphertext
 	// ElmanRNN contexts
-	Contexts [][]*seal.Ciphertext
+	Contexts [][]Ciphertext
 	// Weights
-	InputWeights, OutputWeights [][]*seal.Ciphertext
+	InputWeights, OutputWeights [][]Ciphertext
 	// Last change in weights for momentum
-	InputChanges, OutputChanges [][]*seal.Ciphertext
+	InputChanges, OutputChanges [][]Ciphertext
+	// Logger traces updates and training; nil is silent
+	Logger Logger
 }
 
 /*
@@ -36,33 +49,115 @@ the 'inputs' value is the number of inputs the network will have,
 the 'hiddens' value is the number of hidden nodes and
 the 'outputs' value is the number of the outputs of the network.
 */
-func (nn *FeedForward) Init(inputs, hiddens, outputs int) {
+func (nn *FeedForward) Init(inputs, hiddens, outputs int) error {
+	random := func(i, j int) float64 {
+		return 2*rand.Float64() - 1
+	}
+	return nn.init(inputs, hiddens, outputs, random, random)
+}
+
+/*
+InitWeights initializes the network with weights trained in the clear, which
+are encrypted.
+
+The input weights have a row per input plus a last one for the bias, and a
+column per hidden node; a further bias column, as the upstream package
+keeps, is accepted and never used. The output weights have a row per hidden
+node plus the bias row, and a column per output. The weighted sums must stay
+within the bounds of the activations, which only approximate their
+functions there.
+*/
+func (nn *FeedForward) InitWeights(inputWeights, outputWeights [][]float64) error {
+	if len(inputWeights) < 2 || len(outputWeights) < 2 || len(outputWeights[0]) < 1 {
+		return fmt.Errorf("gobrain: weights need a row per input and one for the bias")
+	}
+	inputs, hiddens, outputs := len(inputWeights)-1, len(outputWeights)-1, len(outputWeights[0])
+	for i, row := range inputWeights {
+		if len(row) != hiddens && len(row) != hiddens+1 {
+			return fmt.Errorf("gobrain: input weight row %d has %d columns for %d hidden nodes", i, len(row), hiddens)
+		}
+	}
+	for i, row := range outputWeights {
+		if len(row) != outputs {
+			return fmt.Errorf("gobrain: output weight row %d has %d columns for %d outputs", i, len(row), outputs)
+		}
+	}
+	input := func(i, j int) float64 {
+		if j < len(inputWeights[i]) {
+			return inputWeights[i][j]
+		}
+		return 0
+	}
+	output := func(i, j int) float64 {
+		return outputWeights[i][j]
+	}
+	return nn.init(inputs, hiddens, outputs, input, output)
+}
+
+// init sets up a network whose weights are the encrypted values of the
+// given functions.
+func (nn *FeedForward) init(inputs, hiddens, outputs int, inputWeight, outputWeight func(i, j int) float64) error {
+	var err error
 
 	nn.NInputs = inputs + 1   // +1 for bias
 	nn.NHiddens = hiddens + 1 // +1 for bias
 	nn.NOutputs = outputs
 
-	nn.InputActivations = nn.vector(nn.NInputs, 1.0)
-	nn.HiddenActivations = nn.vector(nn.NHiddens, 1.0)
-	nn.OutputActivations = nn.vector(nn.NOutputs, 1.0)
+	if err = defaultActivations(&nn.HiddenFunc, &nn.OutputFunc, nn.Regression); err != nil {
+		return err
+	}
 
-	nn.InputWeights = nn.matrix(nn.NInputs, nn.NHiddens)
-	nn.OutputWeights = nn.matrix(nn.NHiddens, nn.NOutputs)
+	if err = nn.initActivations(); err != nil {
+		return err
+	}
+
+	if nn.InputWeights, err = nn.matrix(nn.NInputs, nn.NHiddens); err != nil {
+		return err
+	}
+	if nn.OutputWeights, err = nn.matrix(nn.NHiddens, nn.NOutputs); err != nil {
+		return err
+	}
 
 	for i := 0; i < nn.NInputs; i++ {
 		for j := 0; j < nn.NHiddens; j++ {
-			nn.InputWeights[i][j] = nn.random(-1, 1)
+			if nn.InputWeights[i][j], err = nn.encrypt(inputWeight(i, j)); err != nil {
+				return err
+			}
 		}
 	}
 
 	for i := 0; i < nn.NHiddens; i++ {
 		for j := 0; j < nn.NOutputs; j++ {
-			nn.OutputWeights[i][j] = nn.random(-1, 1)
+			if nn.OutputWeights[i][j], err = nn.encrypt(outputWeight(i, j)); err != nil {
+				return err
+			}
 		}
 	}
 
-	nn.InputChanges = nn.matrix(nn.NInputs, nn.NHiddens)
-	nn.OutputChanges = nn.matrix(nn.NHiddens, nn.NOutputs)
+	if nn.InputChanges, err = nn.matrix(nn.NInputs, nn.NHiddens); err != nil {
+		return err
+	}
+	if nn.OutputChanges, err = nn.matrix(nn.NHiddens, nn.NOutputs); err != nil {
+		return err
+	}
+	return nil
+}
+
+// initActivations allocates the activations and sums for the layer sizes.
+func (nn *FeedForward) initActivations() error {
+	var err error
+	if nn.InputActivations, err = nn.vector(nn.NInputs, 1.0); err != nil {
+		return err
+	}
+	if nn.HiddenActivations, err = nn.vector(nn.NHiddens, 1.0); err != nil {
+		return err
+	}
+	if nn.OutputActivations, err = nn.vector(nn.NOutputs, 1.0); err != nil {
+		return err
+	}
+	nn.HiddenSums = make([]Ciphertext, nn.NHiddens-1)
+	nn.OutputSums = make([]Ciphertext, nn.NOutputs)
+	return nil
 }
 
 /*
@@ -79,16 +174,21 @@ the contexts provided in 'initValues' are used.
 
 When using 'initValues' note that contexts must have the same size of hidden nodes + 1 (bias node).
 */
-func (nn *FeedForward) SetContexts(nContexts int, initValues [][]*seal.Ciphertext) {
+func (nn *FeedForward) SetContexts(nContexts int, initValues [][]Ciphertext) error {
 	if initValues == nil {
-		initValues = make([][]*seal.Ciphertext, nContexts)
+		initValues = make([][]Ciphertext, nContexts)
 
 		for i := 0; i < nContexts; i++ {
-			initValues[i] = nn.vector(nn.NHiddens, 0.5)
+			v, err := nn.vector(nn.NHiddens, 0.5)
+			if err != nil {
+				return err
+			}
+			initValues[i] = v
 		}
 	}
 
 	nn.Contexts = initValues
+	return nil
 }
 
 /*
@@ -96,42 +196,96 @@ The Update method is used to activate the Neural Network.
 
 Given an array of inputs, it returns an array, of length equivalent of number of outputs, with values ranging from 0 to 1.
 */
-func (nn *FeedForward) Update(inputs []*seal.Ciphertext) []*seal.Ciphertext {
+func (nn *FeedForward) Update(inputs []Ciphertext) ([]Ciphertext, error) {
 	if len(inputs) != nn.NInputs-1 {
-		log.Fatal("Error: wrong number of inputs")
+		return nil, fmt.Errorf("%w: %d inputs for %d input nodes", ErrShape, len(inputs), nn.NInputs-1)
 	}
 
 	for i := 0; i < nn.NInputs-1; i++ {
 		nn.InputActivations[i] = inputs[i]
 	}
+	nn.PlainInputs = nil
 
-	for i := 0; i < nn.NHiddens-1; i++ {
-		var sum *seal.Ciphertext
-
+	return nn.activate(func(i int) (Ciphertext, error) {
+		var sum Ciphertext
 		for j := 0; j < nn.NInputs; j++ {
-			elem := nn.Evaluator.Multiply(nn.InputActivations[j], nn.InputWeights[j][i])
+			elem, err := nn.Evaluator.Mul(nn.InputActivations[j], nn.InputWeights[j][i])
+			if err != nil {
+				return nil, err
+			}
 			if sum == nil {
 				sum = elem
-			} else {
-				nn.Evaluator.AddInplace(sum, elem)
+			} else if sum, err = nn.Evaluator.Add(sum, elem); err != nil {
+				return nil, err
+			}
+		}
+		return sum, nil
+	})
+}
+
+/*
+UpdatePlain activates the network on cleartext inputs, which lets a model
+owner keep the weights encrypted while a partner supplies the features.
+
+Each input is encoded at its weight's parameters and scale and applied with
+a plaintext multiplication, so the inputs are never encrypted. The inputs
+are kept in PlainInputs, and a following BackPropagate multiplies by them
+the same way.
+*/
+func (nn *FeedForward) UpdatePlain(inputs []float64) ([]Ciphertext, error) {
+	if len(inputs) != nn.NInputs-1 {
+		return nil, fmt.Errorf("%w: %d inputs for %d input nodes", ErrShape, len(inputs), nn.NInputs-1)
+	}
+	nn.PlainInputs = append(append(nn.PlainInputs[:0], inputs...), 1)
+
+	bias := nn.NInputs - 1
+	return nn.activate(func(i int) (Ciphertext, error) {
+		// The bias input is one, so its weight is added as is.
+		sum := nn.InputWeights[bias][i]
+		for j, v := range inputs {
+			elem, err := nn.Evaluator.MulConst(nn.InputWeights[j][i], v)
+			if err != nil {
+				return nil, err
 			}
+			if sum, err = nn.Evaluator.Add(sum, elem); err != nil {
+				return nil, err
+			}
+		}
+		return sum, nil
+	})
+}
+
+// activate computes the hidden and output layers, given the weighted input
+// sum of each hidden node.
+func (nn *FeedForward) activate(inputSum func(i int) (Ciphertext, error)) ([]Ciphertext, error) {
+	for i := 0; i < nn.NHiddens-1; i++ {
+		sum, err := inputSum(i)
+		if err != nil {
+			return nil, err
 		}
 
 		// compute contexts sum
 		for k := 0; k < len(nn.Contexts); k++ {
 			for j := 0; j < nn.NHiddens-1; j++ {
-				nn.Evaluator.AddInplace(sum, nn.Contexts[k][j])
+				var err error
+				if sum, err = nn.Evaluator.Add(sum, nn.Contexts[k][j]); err != nil {
+					return nil, err
+				}
 			}
 		}
 
-		nn.HiddenActivations[i] = nn.sigmoid(sum)
+		activation, err := nn.HiddenFunc.Forward(nn.Evaluator, sum)
+		if err != nil {
+			return nil, err
+		}
+		nn.HiddenSums[i] = sum
+		nn.HiddenActivations[i] = activation
 	}
 
-	nn.HiddenActivations[nn.NHiddens-1] = nn.Encryptor.Encrypt(
-		nn.Encoder.EncodeScale(0, nn.HiddenActivations[0].Scale()))
-
-	for i, ia := range nn.HiddenActivations {
-		log.Println(i, ia.Scale())
+	// bias node
+	var err error
+	if nn.HiddenActivations[nn.NHiddens-1], err = nn.encrypt(1); err != nil {
+		return nil, err
 	}
 
 	// update the contexts
@@ -143,138 +297,142 @@ func (nn *FeedForward) Update(inputs []*seal.Ciphertext) []*seal.Ciphertext {
 	}
 
 	for i := 0; i < nn.NOutputs; i++ {
-		var sum *seal.Ciphertext
+		var sum Ciphertext
 		for j := 0; j < nn.NHiddens; j++ {
-			activation := nn.HiddenActivations[j]
-			output := nn.OutputWeights[j][i]
-			log.Println(i, j, output, activation)
-			nn.Evaluator.RescaleToInplace(output, activation.ParmsID())
-			elem := nn.Evaluator.Multiply(activation, output)
+			elem, err := nn.Evaluator.Mul(nn.HiddenActivations[j], nn.OutputWeights[j][i])
+			if err != nil {
+				return nil, err
+			}
 			if sum == nil {
 				sum = elem
-			} else {
-				log.Println(sum.Scale(), elem.Scale())
-				nn.Evaluator.RelinearizeInplace(elem, nn.RelinKeys)
-				nn.Evaluator.RescaleToInplace(elem, sum.ParmsID())
-				log.Println(sum.Scale(), elem.Scale())
-				nn.Evaluator.AddInplace(sum, elem)
+			} else if sum, err = nn.Evaluator.Add(sum, elem); err != nil {
+				return nil, err
 			}
 		}
 
-		nn.OutputActivations[i] = nn.sigmoid(sum)
+		nn.OutputSums[i] = sum
+		if nn.OutputActivations[i], err = nn.OutputFunc.Forward(nn.Evaluator, sum); err != nil {
+			return nil, err
+		}
 	}
 
-	return nn.OutputActivations
+	nn.trace("update", nn.OutputActivations[0], "outputs", nn.NOutputs)
+	return nn.OutputActivations, nil
 }
 
 /*
 The BackPropagate method is used, when training the Neural Network,
 to back propagate the errors from network activation.
+
+After UpdatePlain the input weights are updated with the cleartext inputs.
 */
-func (nn *FeedForward) BackPropagate(targets []*seal.Ciphertext, lRate, mFactor float64) *seal.Ciphertext {
+func (nn *FeedForward) BackPropagate(targets []Ciphertext, lRate, mFactor float64) (Ciphertext, error) {
 	if len(targets) != nn.NOutputs {
-		log.Fatal("Error: wrong number of target values")
+		return nil, fmt.Errorf("%w: %d targets for %d output nodes", ErrShape, len(targets), nn.NOutputs)
 	}
 
-	outputDeltas := nn.vector(nn.NOutputs, 0.0)
+	outputDeltas := make([]Ciphertext, nn.NOutputs)
 	for i := 0; i < nn.NOutputs; i++ {
-		target := targets[i]
-		activation := nn.OutputActivations[i]
-		nn.Evaluator.RescaleToInplace(target, activation.ParmsID())
-		outputDeltas[i] = nn.Evaluator.Multiply(
-			nn.dsigmoid(activation),
-			nn.Evaluator.Sub(target, activation))
+		diff, err := nn.Evaluator.Sub(targets[i], nn.OutputActivations[i])
+		if err != nil {
+			return nil, err
+		}
+		if outputDeltas[i], err = nn.OutputFunc.Derivative(nn.Evaluator, nn.OutputSums[i], diff); err != nil {
+			return nil, err
+		}
 	}
 
-	hiddenDeltas := nn.vector(nn.NHiddens, 0.0)
-	for i := 0; i < nn.NHiddens; i++ {
-		var e *seal.Ciphertext
+	// The bias node has no inputs, so it gets no delta.
+	hiddenDeltas := make([]Ciphertext, nn.NHiddens-1)
+	for i := 0; i < nn.NHiddens-1; i++ {
+		var e Ciphertext
 
 		for j := 0; j < nn.NOutputs; j++ {
-			delta := outputDeltas[j]
-			weight := nn.OutputWeights[i][j]
-			nn.Evaluator.RelinearizeInplace(weight, nn.RelinKeys)
-			nn.Evaluator.RescaleToInplace(weight, delta.ParmsID())
-			entry := nn.Evaluator.Multiply(delta, weight)
+			entry, err := nn.Evaluator.Mul(outputDeltas[j], nn.OutputWeights[i][j])
+			if err != nil {
+				return nil, err
+			}
 			if e == nil {
 				e = entry
-			} else {
-				nn.Evaluator.AddInplace(e, entry)
+			} else if e, err = nn.Evaluator.Add(e, entry); err != nil {
+				return nil, err
 			}
 		}
-		activation := nn.dsigmoid(nn.HiddenActivations[i])
-		nn.Evaluator.RelinearizeInplace(activation, nn.RelinKeys)
-		nn.Evaluator.RescaleToInplace(activation, e.ParmsID())
-		hiddenDeltas[i] = nn.Evaluator.Multiply(activation, e)
+		var err error
+		if hiddenDeltas[i], err = nn.HiddenFunc.Derivative(nn.Evaluator, nn.HiddenSums[i], e); err != nil {
+			return nil, err
+		}
 	}
 
-	var lRatePlain *seal.Plaintext
-	mFactorPlain := nn.Encoder.Encode(mFactor)
+	// addChange returns weight + lRate*change + mFactor*prev.
+	addChange := func(weight, change, prev Ciphertext) (Ciphertext, error) {
+		step, err := nn.Evaluator.MulConst(change, lRate)
+		if err != nil {
+			return nil, err
+		}
+		if weight, err = nn.Evaluator.Add(weight, step); err != nil {
+			return nil, err
+		}
+		momentum, err := nn.Evaluator.MulConst(prev, mFactor)
+		if err != nil {
+			return nil, err
+		}
+		return nn.Evaluator.Add(weight, momentum)
+	}
 
 	for i := 0; i < nn.NHiddens; i++ {
 		for j := 0; j < nn.NOutputs; j++ {
-			change := nn.Evaluator.Multiply(outputDeltas[j], nn.HiddenActivations[i])
-			if lRatePlain == nil {
-				lRatePlain = nn.Encoder.EncodeParmsIDScale(lRate, change.ParmsID(), change.Scale())
-				log.Println("lRate", lRate, nn.Encoder.Decode(lRatePlain), change.Scale())
+			change, err := nn.Evaluator.Mul(outputDeltas[j], nn.HiddenActivations[i])
+			if err != nil {
+				return nil, err
+			}
+			if nn.OutputWeights[i][j], err = addChange(nn.OutputWeights[i][j], change, nn.OutputChanges[i][j]); err != nil {
+				return nil, err
 			}
-			nn.Evaluator.AddInplace(nn.OutputWeights[i][j],
-				nn.Evaluator.MultiplyPlain(change, lRatePlain))
-			nn.Evaluator.AddInplace(nn.OutputWeights[i][j],
-				nn.Evaluator.MultiplyPlain(nn.OutputChanges[i][j], mFactorPlain))
 			nn.OutputChanges[i][j] = change
 		}
 	}
 
 	for i := 0; i < nn.NInputs; i++ {
-		for j := 0; j < nn.NHiddens; j++ {
-			change := nn.Evaluator.Multiply(hiddenDeltas[j], nn.InputActivations[i])
-			nn.Evaluator.AddInplace(nn.InputWeights[i][j],
-				nn.Evaluator.MultiplyPlain(change, lRatePlain))
-			nn.Evaluator.AddInplace(nn.InputWeights[i][j],
-				nn.Evaluator.MultiplyPlain(nn.InputChanges[i][j], mFactorPlain))
+		for j := 0; j < nn.NHiddens-1; j++ {
+			var change Ciphertext
+			var err error
+			if nn.PlainInputs != nil {
+				change, err = nn.Evaluator.MulConst(hiddenDeltas[j], nn.PlainInputs[i])
+			} else {
+				change, err = nn.Evaluator.Mul(hiddenDeltas[j], nn.InputActivations[i])
+			}
+			if err != nil {
+				return nil, err
+			}
+			if nn.InputWeights[i][j], err = addChange(nn.InputWeights[i][j], change, nn.InputChanges[i][j]); err != nil {
+				return nil, err
+			}
 			nn.InputChanges[i][j] = change
 		}
 	}
 
-	e := nn.Encryptor.Encrypt(nn.Encoder.Encode(0))
-
-	halve := nn.Encoder.Encode(0.5)
-
-	for i := 0; i < len(targets); i++ {
-		v := nn.Evaluator.Sub(targets[i], nn.OutputActivations[i])
-		nn.Evaluator.SquareInplace(v)
-		nn.Evaluator.MultiplyPlainInplace(v, halve)
-		nn.Evaluator.AddInplace(e, v)
+	e, err := nn.encrypt(0)
+	if err != nil {
+		return nil, err
 	}
 
-	return e
-}
-
-/*
-This method is used to train the Network, it will run the training operation for 'iterations' times
-and return the computed errors when training.
-*/
-func (nn *FeedForward) Train(patterns [][][]*seal.Ciphertext, iterations int, lRate, mFactor float64) []*seal.Ciphertext {
-	errors := make([]*seal.Ciphertext, iterations)
-
-	for i := 0; i < iterations; i++ {
-		e := nn.Encryptor.Encrypt(nn.Encoder.Encode(0))
-		for _, p := range patterns {
-			nn.Update(p[0])
-
-			tmp := nn.BackPropagate(p[1], lRate, mFactor)
-			nn.Evaluator.AddInplace(e, tmp)
+	for i := 0; i < len(targets); i++ {
+		v, err := nn.Evaluator.Sub(targets[i], nn.OutputActivations[i])
+		if err != nil {
+			return nil, err
+		}
+		if v, err = nn.Evaluator.Square(v); err != nil {
+			return nil, err
+		}
+		if v, err = nn.Evaluator.MulConst(v, 0.5); err != nil {
+			return nil, err
+		}
+		if e, err = nn.Evaluator.Add(e, v); err != nil {
+			return nil, err
 		}
-
-		errors[i] = e
 	}
 
-	return errors
-}
-
-func (nn *FeedForward) Test(patterns [][][]*seal.Ciphertext) {
-	for _, p := range patterns {
-		fmt.Println(p[0], "->", nn.Update(p[0]), " : ", p[1])
-	}
+	nn.trace("backpropagate", e, "rate", lRate, "momentum", mFactor)
+	return e, nil
 }
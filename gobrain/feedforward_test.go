@@ -1,42 +1,75 @@
 package gobrain
 
 import (
-	// "testing"
 	"fmt"
+	"log"
+	"math"
 	"math/rand"
+	"testing"
 
-	"github.com/d4l3k/go-fheml/seal"
+	"github.com/d4l3k/go-fheml/sim"
 )
 
-func ExampleSimpleFeedForward() {
+func ExampleFeedForward() {
 	// set the random seed to 0
 	rand.Seed(0)
 
-	params := seal.NewEncryptionParamsCKKS()
-	c := seal.NewContext(params)
-	g := seal.NewKeyGenerator(c)
-	pub := g.PublicKey()
-	sec := g.SecretKey()
-	relin := g.RelinKeys(60, 2)
+	check := func(err error) {
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// The simulator stands in for SEAL here; package sealbackend runs the
+	// same network on SEAL. Training needs a deep modulus chain.
+	bits := make([]int, 64)
+	for i := range bits {
+		bits[i] = 60
+	}
+	c, err := sim.NewContext(sim.Params{PolyModulusDegree: 64, CoeffModulusBits: bits, NoiseStdDev: 3.19})
+	check(err)
+	g, err := sim.NewKeyGenerator(c)
+	check(err)
+	pub, err := g.PublicKey()
+	check(err)
+	sec, err := g.SecretKey()
+	check(err)
+	relin, err := g.RelinKeys(60, 2)
+	check(err)
 
-	encr := seal.NewEncryptor(c, pub)
-	enco := seal.NewCKKSEncoder(c)
-	decr := seal.NewDecryptor(c, sec)
+	encr, err := sim.NewEncryptor(c, pub)
+	check(err)
+	enco, err := sim.NewCKKSEncoder(c)
+	check(err)
+	decr, err := sim.NewDecryptor(c, sec)
+	check(err)
+	eval, err := sim.NewAutoEvaluator(c, relin)
+	check(err)
 
-	e := func(a float64) *seal.Ciphertext {
-		return encr.Encrypt(enco.Encode(a))
+	backend := &Sim{Encoder: enco, Encryptor: encr, Evaluator: eval}
+
+	e := func(a float64) Ciphertext {
+		p, err := backend.Encode(a)
+		check(err)
+		cipher, err := backend.Encrypt(p)
+		check(err)
+		return cipher
 	}
 
-	d := func(in []*seal.Ciphertext) []float64 {
+	d := func(in []Ciphertext) []float64 {
 		var out []float64
 		for _, cipher := range in {
-			out = append(out, enco.Decode(decr.Decrypt(cipher)))
+			p, err := decr.Decrypt(cipher.(*sim.Ciphertext))
+			check(err)
+			v, err := enco.Decode(p)
+			check(err)
+			out = append(out, v)
 		}
 		return out
 	}
 
 	// create the XOR representation patter to train the network
-	patterns := [][][]*seal.Ciphertext{
+	patterns := [][][]Ciphertext{
 		{{e(0), e(0)}, {e(0)}},
 		{{e(0), e(1)}, {e(1)}},
 		{{e(1), e(0)}, {e(1)}},
@@ -45,33 +78,182 @@ func ExampleSimpleFeedForward() {
 
 	// instantiate the Feed Forward
 	ff := &FeedForward{
-		Encryptor: encr,
-		Evaluator: seal.NewEvaluator(c),
-		Encoder:   enco,
-		RelinKeys: relin,
+		Encryptor: backend,
+		Evaluator: backend,
+		Encoder:   backend,
 	}
 
 	// initialize the Neural Network;
 	// the networks structure will contain:
 	// 2 inputs, 2 hidden nodes and 1 output.
-	ff.Init(2, 2, 1)
+	check(ff.Init(2, 2, 1))
 
 	// train the network using the XOR patterns
-	// the training will run for 1000 epochs
+	// the training will run for 1 epoch
 	// the learning rate is set to 0.6 and the momentum factor to 0.4
-	// use true in the last parameter to receive reports about the learning error
-	fmt.Println("Train", d(ff.Train(patterns, 1, 0.6, 0.4)))
+	res, err := ff.Train(patterns, TrainOptions{Epochs: 1, LearningRate: 0.6, Momentum: 0.4})
+	check(err)
+	fmt.Println("Train", d(res.Losses))
 
 	// testing the network
-	//ff.Test(patterns)
+	predictions, err := ff.Test(patterns)
+	check(err)
+	for _, p := range predictions {
+		fmt.Println(d(p.Outputs), " : ", d(p.Targets))
+	}
 
 	// predicting a value
-	inputs := []*seal.Ciphertext{e(1), e(1)}
-	fmt.Println("Predict", d(ff.Update(inputs)))
-
-	// Output:
-	// [0 0] -> [0.05750394570844524]  :  [0]
-	// [0 1] -> [0.9301006350712102]  :  [1]
-	// [1 0] -> [0.927809966227284]  :  [1]
-	// [1 1] -> [0.09740879532462095]  :  [0]
+	inputs := []Ciphertext{e(1), e(1)}
+	out, err := ff.Update(inputs)
+	check(err)
+	fmt.Println("Predict", d(out))
+}
+
+func TestRegressionOutputIsLinear(t *testing.T) {
+	b, d := newSimBackend(t)
+	value := func(c Ciphertext) float64 {
+		p, err := d.Decrypt(c.(*sim.Ciphertext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := b.Encoder.Decode(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	ff := &FeedForward{Encryptor: b, Evaluator: b, Encoder: b, Regression: true}
+	if err := ff.Init(2, 3, 1); err != nil {
+		t.Fatal(err)
+	}
+	in := []float64{0.5, -1}
+	var inputs []Ciphertext
+	for _, v := range in {
+		p, err := b.Encode(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := b.Encrypt(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, c)
+	}
+	out, err := ff.Update(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hidden := ff.HiddenFunc.(*PolyActivation).Poly
+	in = append(in, 1)
+	want := value(ff.OutputWeights[ff.NHiddens-1][0])
+	for j := 0; j < ff.NHiddens-1; j++ {
+		var sum float64
+		for i, v := range in {
+			sum += v * value(ff.InputWeights[i][j])
+		}
+		want += hidden.Evaluate(sum) * value(ff.OutputWeights[j][0])
+	}
+	if got := value(out[0]); math.Abs(got-want) > 1e-6 {
+		t.Errorf("regression output = %g; want %g", got, want)
+	}
+
+	bad := &FeedForward{Encryptor: b, Evaluator: b, Encoder: b, Regression: true, OutputFunc: Square{}}
+	if err := bad.Init(2, 2, 1); err == nil {
+		t.Error("Init(Regression, Square output) succeeded")
+	}
+}
+
+func TestUpdatePlain(t *testing.T) {
+	b, d := newSimBackend(t)
+	value := func(c Ciphertext) float64 {
+		p, err := d.Decrypt(c.(*sim.Ciphertext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := b.Encoder.Decode(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	ff := &FeedForward{Encryptor: b, Evaluator: b, Encoder: b}
+	if err := ff.Init(2, 3, 2); err != nil {
+		t.Fatal(err)
+	}
+	in := []float64{0.5, -1}
+	got, err := ff.UpdatePlain(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotValues := []float64{value(got[0]), value(got[1])}
+
+	var inputs []Ciphertext
+	for _, v := range in {
+		c, err := encrypt(b, b, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, c)
+	}
+	want, err := ff.Update(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range want {
+		if w := value(c); math.Abs(gotValues[i]-w) > 1e-6 {
+			t.Errorf("output %d = %g; want %g", i, gotValues[i], w)
+		}
+	}
+
+	if _, err := ff.UpdatePlain([]float64{1}); err == nil {
+		t.Error("UpdatePlain(too few inputs) succeeded")
+	}
+
+	// BackPropagate after UpdatePlain trains on the cleartext inputs, not
+	// on those of the earlier Update.
+	weights := [][]float64{{0.5, -0.25}, {0.75, 0.1}, {-0.5, 0.3}}
+	outputs := [][]float64{{0.2}, {-0.4}, {0.1}}
+	plain := &FeedForward{Encryptor: b, Evaluator: b, Encoder: b}
+	encrypted := &FeedForward{Encryptor: b, Evaluator: b, Encoder: b}
+	for _, nn := range []*FeedForward{plain, encrypted} {
+		if err := nn.InitWeights(weights, outputs); err != nil {
+			t.Fatal(err)
+		}
+	}
+	target, err := encrypt(b, b, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plain.Update(inputs); err != nil {
+		t.Fatal(err)
+	}
+	other := []float64{-0.5, 0.25}
+	if _, err := plain.UpdatePlain(other); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plain.BackPropagate([]Ciphertext{target}, 0.5, 0.1); err != nil {
+		t.Fatal(err)
+	}
+	var otherInputs []Ciphertext
+	for _, v := range other {
+		c, err := encrypt(b, b, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		otherInputs = append(otherInputs, c)
+	}
+	if _, err := encrypted.Update(otherInputs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := encrypted.BackPropagate([]Ciphertext{target}, 0.5, 0.1); err != nil {
+		t.Fatal(err)
+	}
+	for i, row := range encrypted.InputWeights {
+		for j := range row[:len(row)-1] {
+			if got, want := value(plain.InputWeights[i][j]), value(row[j]); math.Abs(got-want) > 1e-6 {
+				t.Errorf("input weight %d,%d = %g; want %g", i, j, got, want)
+			}
+		}
+	}
 }
@@ -0,0 +1,26 @@
+package gobrain
+
+// Logger receives trace events from a network: a message and alternating
+// keys and values. A *slog.Logger plugs in as LoggerFunc(logger.Debug).
+type Logger interface {
+	Log(msg string, keyvals ...interface{})
+}
+
+// LoggerFunc adapts a function to Logger.
+type LoggerFunc func(msg string, keyvals ...interface{})
+
+func (f LoggerFunc) Log(msg string, keyvals ...interface{}) {
+	f(msg, keyvals...)
+}
+
+// trace logs an event about c, adding its level when the backend reports
+// one. It does nothing without a Logger.
+func (nn *FeedForward) trace(msg string, c Ciphertext, keyvals ...interface{}) {
+	if nn.Logger == nil {
+		return
+	}
+	if level, err := nn.Evaluator.Level(c); err == nil {
+		keyvals = append(keyvals, "level", level)
+	}
+	nn.Logger.Log(msg, keyvals...)
+}
@@ -0,0 +1,65 @@
+package gobrain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestShapeErrors(t *testing.T) {
+	b, _ := newSimBackend(t)
+	ff := &FeedForward{Encryptor: b, Evaluator: b, Encoder: b}
+	if err := ff.Init(2, 2, 1); err != nil {
+		t.Fatal(err)
+	}
+	x, err := encrypt(b, b, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ff.Update([]Ciphertext{x}); !errors.Is(err, ErrShape) {
+		t.Errorf("Update(too few inputs) = %v; want ErrShape", err)
+	}
+	if _, err := ff.Update([]Ciphertext{x, x}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ff.BackPropagate([]Ciphertext{x, x}, 0.5, 0.1); !errors.Is(err, ErrShape) {
+		t.Errorf("BackPropagate(too many targets) = %v; want ErrShape", err)
+	}
+	if _, err := ff.UpdatePlain([]float64{1, 2, 3}); !errors.Is(err, ErrShape) {
+		t.Errorf("UpdatePlain(too many inputs) = %v; want ErrShape", err)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	b, _ := newSimBackend(t)
+	ff := &FeedForward{Encryptor: b, Evaluator: b, Encoder: b}
+	if err := ff.Init(2, 2, 1); err != nil {
+		t.Fatal(err)
+	}
+	x, err := encrypt(b, b, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patterns := [][][]Ciphertext{{{x, x}, {x}}}
+	events := map[string]int{}
+	ff.Logger = LoggerFunc(func(msg string, keyvals ...interface{}) {
+		if len(keyvals)%2 != 0 {
+			t.Errorf("%s event has odd keyvals %v", msg, keyvals)
+		}
+		if keyvals[len(keyvals)-2] != "level" {
+			t.Errorf("%s event has no level: %v", msg, keyvals)
+		}
+		events[msg]++
+	})
+	if _, err := ff.Train(patterns, TrainOptions{Epochs: 1, LearningRate: 0.5, Momentum: 0.1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ff.Test(patterns); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"update": 2, "backpropagate": 1, "epoch": 1, "test": 1}
+	for msg, n := range want {
+		if events[msg] != n {
+			t.Errorf("%d %q events; want %d", events[msg], msg, n)
+		}
+	}
+}
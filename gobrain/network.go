@@ -0,0 +1,459 @@
+package gobrain
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// ErrTooDeep is returned when a network needs more levels than fresh
+// ciphertexts have.
+var ErrTooDeep = errors.New("gobrain: network is too deep for the encryption parameters")
+
+// ErrNotInitialized is returned when a network is used before Init.
+var ErrNotInitialized = errors.New("gobrain: network is not initialized")
+
+// Dense is a fully connected layer.
+type Dense struct {
+	// Number of input and output nodes, not counting the bias input
+	NInputs, NOutputs int
+	// Activation of the layer; Init picks the default sigmoid if it is nil
+	Activation Activation
+	// Weights[i][j] connects input i to output j; row NInputs is the bias
+	Weights [][]Ciphertext
+	// Last change in weights for momentum
+	Changes [][]Ciphertext
+	// Inputs with the bias appended, weighted sums and outputs of the last
+	// Forward pass
+	Inputs, Sums, Outputs []Ciphertext
+}
+
+// NewDense returns a layer with the given number of inputs and outputs.
+func NewDense(inputs, outputs int, activation Activation) *Dense {
+	return &Dense{NInputs: inputs, NOutputs: outputs, Activation: activation}
+}
+
+// Network is a multilayer perceptron of Dense layers, each feeding the
+// next. Unlike FeedForward it supports any number of layers.
+type Network struct {
+	Encryptor Encryptor
+	Evaluator Evaluator
+	Encoder   Encoder
+
+	Layers []*Dense
+	// Levels fresh ciphertexts have, found by Init
+	Levels int
+}
+
+// Init initializes the weights with random values in [-1, 1] and checks
+// that a Forward pass fits in the levels of fresh ciphertexts.
+func (n *Network) Init() error {
+	if err := n.check(); err != nil {
+		return err
+	}
+
+	fresh, err := encrypt(n.Encoder, n.Encryptor, 0)
+	if err != nil {
+		return err
+	}
+	if n.Levels, err = n.Evaluator.Level(fresh); err != nil {
+		return err
+	}
+	// Plan from fresh weights, not those of an earlier Init.
+	for _, l := range n.Layers {
+		l.Weights, l.Changes = nil, nil
+	}
+	if err := n.CheckDepth(0); err != nil {
+		return err
+	}
+
+	random := func() (Ciphertext, error) {
+		return encrypt(n.Encoder, n.Encryptor, 2*rand.Float64()-1)
+	}
+	zero := func() (Ciphertext, error) {
+		return fresh, nil
+	}
+	for _, l := range n.Layers {
+		if l.Weights, err = newMatrix(l.NInputs+1, l.NOutputs, random); err != nil {
+			return err
+		}
+		if l.Changes, err = newMatrix(l.NInputs+1, l.NOutputs, zero); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check validates the layer sizes and defaults missing activations.
+func (n *Network) check() error {
+	if len(n.Layers) == 0 {
+		return fmt.Errorf("gobrain: network has no layers")
+	}
+	for i, l := range n.Layers {
+		if l.NInputs < 1 || l.NOutputs < 1 {
+			return fmt.Errorf("gobrain: layer %d has %d inputs and %d outputs", i, l.NInputs, l.NOutputs)
+		}
+		if i > 0 && l.NInputs != n.Layers[i-1].NOutputs {
+			return fmt.Errorf("gobrain: layer %d has %d inputs; layer %d has %d outputs",
+				i, l.NInputs, i-1, n.Layers[i-1].NOutputs)
+		}
+		if l.Activation == nil {
+			var err error
+			if l.Activation, err = NewSigmoid(DefaultSigmoidDegree, DefaultSigmoidBound, ChebyshevFit); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Depth returns the levels, counted from fresh ciphertexts, that the
+// network will have consumed after the given number of training steps,
+// each a Forward and a Backward pass, followed by one more Forward pass.
+// It starts from the levels the weights have already used, so weights get
+// deeper with every update and without bootstrapping every network
+// eventually runs out of levels.
+//
+// Depth assumes every product costs exactly one level, which holds while
+// the primes of the coefficient modulus are about as large as the scale.
+func (n *Network) Depth(steps int) (int, error) {
+	if err := n.check(); err != nil {
+		return 0, err
+	}
+	t := &depthTracker{}
+	shadow := &Network{Encryptor: t, Evaluator: t, Encoder: t}
+	zero := func() (Ciphertext, error) {
+		return 0, nil
+	}
+	for k, l := range n.Layers {
+		s := NewDense(l.NInputs, l.NOutputs, l.Activation)
+		var err error
+		if s.Weights, err = n.consumed(k, l.Weights); err != nil {
+			return 0, err
+		}
+		if s.Changes, err = n.consumed(k, l.Changes); err != nil {
+			return 0, err
+		}
+		shadow.Layers = append(shadow.Layers, s)
+	}
+	inputs, _ := newMatrix(1, n.Layers[0].NInputs, zero)
+	targets, _ := newMatrix(1, n.Layers[len(n.Layers)-1].NOutputs, zero)
+	for i := 0; i < steps; i++ {
+		if _, err := shadow.Forward(inputs[0]); err != nil {
+			return 0, err
+		}
+		if _, err := shadow.Backward(targets[0], 0, 0); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := shadow.Forward(inputs[0]); err != nil {
+		return 0, err
+	}
+	return t.max, nil
+}
+
+// consumed returns the levels each cell of m, a weight matrix of layer k,
+// has consumed since encryption. Before Init there are no weights yet and
+// every cell counts as fresh.
+func (n *Network) consumed(k int, m [][]Ciphertext) ([][]Ciphertext, error) {
+	l := n.Layers[k]
+	if m == nil {
+		return newMatrix(l.NInputs+1, l.NOutputs, func() (Ciphertext, error) {
+			return 0, nil
+		})
+	}
+	if err := l.checkWeights(k, m); err != nil {
+		return nil, err
+	}
+	out := make([][]Ciphertext, len(m))
+	for i, row := range m {
+		out[i] = make([]Ciphertext, len(row))
+		for j, c := range row {
+			level, err := n.Evaluator.Level(c)
+			if err != nil {
+				return nil, err
+			}
+			out[i][j] = n.Levels - level
+		}
+	}
+	return out, nil
+}
+
+// checkWeights checks that m has a row per input, plus the bias, and a
+// column per output.
+func (l *Dense) checkWeights(k int, m [][]Ciphertext) error {
+	if len(m) != l.NInputs+1 {
+		return fmt.Errorf("%w: layer %d has %d weight rows for %d inputs", ErrShape, k, len(m), l.NInputs)
+	}
+	for _, row := range m {
+		if len(row) != l.NOutputs {
+			return fmt.Errorf("%w: layer %d has %d weight columns for %d outputs", ErrShape, k, len(row), l.NOutputs)
+		}
+	}
+	return nil
+}
+
+// ready returns ErrNotInitialized unless the network has layers with
+// weights and activations.
+func (n *Network) ready() error {
+	if len(n.Layers) == 0 {
+		return fmt.Errorf("%w: network has no layers", ErrNotInitialized)
+	}
+	for k, l := range n.Layers {
+		if l.Weights == nil || l.Changes == nil || l.Activation == nil {
+			return fmt.Errorf("%w: layer %d has no weights or activation", ErrNotInitialized, k)
+		}
+		if err := l.checkWeights(k, l.Weights); err != nil {
+			return err
+		}
+		if err := l.checkWeights(k, l.Changes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckDepth returns ErrTooDeep if the given number of training steps
+// followed by a Forward pass would take the network past n.Levels levels.
+//
+// Training needs a much longer modulus chain than inference. Two Dense
+// layers with the default sigmoid need 8 levels for a Forward pass and 13
+// more for every training step, so even their Init fails on the 7 levels
+// of the default parameters of packages seal and sim; s steps need a chain
+// of 8+13s levels.
+func (n *Network) CheckDepth(steps int) error {
+	d, err := n.Depth(steps)
+	if err != nil {
+		return err
+	}
+	if d > n.Levels {
+		return fmt.Errorf("gobrain: %d training steps and a forward pass need %d levels, have %d: %w",
+			steps, d, n.Levels, ErrTooDeep)
+	}
+	return nil
+}
+
+// Forward activates the network on inputs and returns the outputs of the
+// last layer.
+func (n *Network) Forward(inputs []Ciphertext) ([]Ciphertext, error) {
+	if err := n.ready(); err != nil {
+		return nil, err
+	}
+	if len(inputs) != n.Layers[0].NInputs {
+		return nil, fmt.Errorf("%w: %d inputs for %d input nodes", ErrShape, len(inputs), n.Layers[0].NInputs)
+	}
+	bias, err := encrypt(n.Encoder, n.Encryptor, 1)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range n.Layers {
+		l.Inputs = append(append(l.Inputs[:0], inputs...), bias)
+		l.Sums = make([]Ciphertext, l.NOutputs)
+		l.Outputs = make([]Ciphertext, l.NOutputs)
+		for j := 0; j < l.NOutputs; j++ {
+			var sum Ciphertext
+			for i, in := range l.Inputs {
+				elem, err := n.Evaluator.Mul(in, l.Weights[i][j])
+				if err != nil {
+					return nil, err
+				}
+				if sum == nil {
+					sum = elem
+				} else if sum, err = n.Evaluator.Add(sum, elem); err != nil {
+					return nil, err
+				}
+			}
+			l.Sums[j] = sum
+			if l.Outputs[j], err = l.Activation.Forward(n.Evaluator, sum); err != nil {
+				return nil, err
+			}
+		}
+		inputs = l.Outputs
+	}
+	return inputs, nil
+}
+
+// Backward back propagates the error of the last Forward pass against
+// targets, updates the weights and returns the encrypted loss, half the
+// sum of squared errors.
+func (n *Network) Backward(targets []Ciphertext, lRate, mFactor float64) (Ciphertext, error) {
+	if err := n.ready(); err != nil {
+		return nil, err
+	}
+	for k, l := range n.Layers {
+		if len(l.Inputs) != l.NInputs+1 || len(l.Sums) != l.NOutputs || len(l.Outputs) != l.NOutputs {
+			return nil, fmt.Errorf("gobrain: layer %d has no Forward pass to back propagate", k)
+		}
+	}
+	last := n.Layers[len(n.Layers)-1]
+	if len(targets) != last.NOutputs {
+		return nil, fmt.Errorf("%w: %d targets for %d output nodes", ErrShape, len(targets), last.NOutputs)
+	}
+
+	var loss Ciphertext
+	grads := make([]Ciphertext, last.NOutputs)
+	for j := range grads {
+		var err error
+		if grads[j], err = n.Evaluator.Sub(targets[j], last.Outputs[j]); err != nil {
+			return nil, err
+		}
+		v, err := n.Evaluator.Square(grads[j])
+		if err != nil {
+			return nil, err
+		}
+		if v, err = n.Evaluator.MulConst(v, 0.5); err != nil {
+			return nil, err
+		}
+		if loss == nil {
+			loss = v
+		} else if loss, err = n.Evaluator.Add(loss, v); err != nil {
+			return nil, err
+		}
+	}
+
+	for k := len(n.Layers) - 1; k >= 0; k-- {
+		l := n.Layers[k]
+		deltas := make([]Ciphertext, l.NOutputs)
+		for j := range deltas {
+			var err error
+			if deltas[j], err = l.Activation.Derivative(n.Evaluator, l.Sums[j], grads[j]); err != nil {
+				return nil, err
+			}
+		}
+
+		// Gradients for the previous layer use the weights before the update.
+		if k > 0 {
+			grads = make([]Ciphertext, l.NInputs)
+			for i := range grads {
+				for j, d := range deltas {
+					entry, err := n.Evaluator.Mul(d, l.Weights[i][j])
+					if err != nil {
+						return nil, err
+					}
+					if grads[i] == nil {
+						grads[i] = entry
+					} else if grads[i], err = n.Evaluator.Add(grads[i], entry); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+
+		for i, in := range l.Inputs {
+			for j, d := range deltas {
+				change, err := n.Evaluator.Mul(d, in)
+				if err != nil {
+					return nil, err
+				}
+				if l.Weights[i][j], err = applyChange(n.Evaluator, l.Weights[i][j], change, l.Changes[i][j], lRate, mFactor); err != nil {
+					return nil, err
+				}
+				l.Changes[i][j] = change
+			}
+		}
+	}
+	return loss, nil
+}
+
+// Train runs Forward and Backward over every pattern, each a pair of
+// inputs and targets, for opts.Epochs epochs, like FeedForward.Train. It
+// refuses to start if that many epochs would run out of levels; see
+// CheckDepth for the chain length training needs.
+func (n *Network) Train(patterns [][][]Ciphertext, opts TrainOptions) (*TrainResult, error) {
+	if err := n.CheckDepth(opts.Epochs * len(patterns)); err != nil {
+		return nil, err
+	}
+	zero, err := encrypt(n.Encoder, n.Encryptor, 0)
+	if err != nil {
+		return nil, err
+	}
+	step := func(p [][]Ciphertext) (Ciphertext, error) {
+		if _, err := n.Forward(p[0]); err != nil {
+			return nil, err
+		}
+		return n.Backward(p[1], opts.LearningRate, opts.Momentum)
+	}
+	return train(n.Evaluator, zero, patterns, opts, step, nil)
+}
+
+// applyChange returns weight + lRate*change + mFactor*prev.
+func applyChange(e Evaluator, weight, change, prev Ciphertext, lRate, mFactor float64) (Ciphertext, error) {
+	step, err := e.MulConst(change, lRate)
+	if err != nil {
+		return nil, err
+	}
+	if weight, err = e.Add(weight, step); err != nil {
+		return nil, err
+	}
+	momentum, err := e.MulConst(prev, mFactor)
+	if err != nil {
+		return nil, err
+	}
+	return e.Add(weight, momentum)
+}
+
+// depthTracker is a backend whose ciphertexts are the number of levels
+// consumed so far, following the level accounting of seal.AutoEvaluator.
+type depthTracker struct {
+	max int
+}
+
+func (t *depthTracker) track(d int) (Ciphertext, error) {
+	if d > t.max {
+		t.max = d
+	}
+	return d, nil
+}
+
+func (t *depthTracker) Encode(v float64) (Plaintext, error)     { return nil, nil }
+func (t *depthTracker) Encrypt(p Plaintext) (Ciphertext, error) { return 0, nil }
+
+func (t *depthTracker) Add(a, b Ciphertext) (Ciphertext, error) {
+	return t.track(maxInt(a.(int), b.(int)))
+}
+
+func (t *depthTracker) Sub(a, b Ciphertext) (Ciphertext, error) {
+	return t.track(maxInt(a.(int), b.(int)))
+}
+
+func (t *depthTracker) Mul(a, b Ciphertext) (Ciphertext, error) {
+	return t.track(maxInt(a.(int), b.(int)) + 1)
+}
+
+func (t *depthTracker) Square(a Ciphertext) (Ciphertext, error) {
+	return t.track(a.(int) + 1)
+}
+
+func (t *depthTracker) Negate(a Ciphertext) (Ciphertext, error) {
+	return a, nil
+}
+
+func (t *depthTracker) AddConst(a Ciphertext, v float64) (Ciphertext, error) {
+	return a, nil
+}
+
+func (t *depthTracker) MulConst(a Ciphertext, v float64) (Ciphertext, error) {
+	return t.track(a.(int) + 1)
+}
+
+func (t *depthTracker) EvaluatePolynomial(a Ciphertext, p *Polynomial) (Ciphertext, error) {
+	return t.track(a.(int) + p.Depth())
+}
+
+func (t *depthTracker) Level(a Ciphertext) (int, error) {
+	return 0, nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
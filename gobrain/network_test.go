@@ -0,0 +1,270 @@
+package gobrain
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/d4l3k/go-fheml/sim"
+)
+
+func TestNetworkForward(t *testing.T) {
+	b, d := newSimBackend(t)
+	value := func(c Ciphertext) float64 {
+		p, err := d.Decrypt(c.(*sim.Ciphertext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := b.Encoder.Decode(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	n := &Network{
+		Encryptor: b, Evaluator: b, Encoder: b,
+		Layers: []*Dense{
+			NewDense(2, 3, nil),
+			NewDense(3, 2, Square{}),
+			NewDense(2, 1, Identity{}),
+		},
+	}
+	if err := n.Init(); err != nil {
+		t.Fatal(err)
+	}
+	in := []float64{0.5, -1}
+	var inputs []Ciphertext
+	for _, v := range in {
+		c, err := encrypt(b, b, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, c)
+	}
+	out, err := n.Forward(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sigmoid := n.Layers[0].Activation.(*PolyActivation).Poly
+	funcs := []func(float64) float64{
+		sigmoid.Evaluate,
+		func(x float64) float64 { return x * x },
+		func(x float64) float64 { return x },
+	}
+	want := in
+	for k, l := range n.Layers {
+		next := make([]float64, l.NOutputs)
+		for j := range next {
+			sum := value(l.Weights[l.NInputs][j])
+			for i, v := range want {
+				sum += v * value(l.Weights[i][j])
+			}
+			next[j] = funcs[k](sum)
+		}
+		want = next
+	}
+	if got := value(out[0]); math.Abs(got-want[0]) > 1e-6 {
+		t.Errorf("Forward() = %g; want %g", got, want[0])
+	}
+
+	levels, err := b.Level(out[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	depth, err := n.Depth(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used := n.Levels - levels; used != depth {
+		t.Errorf("Forward() used %d levels; Depth(0) = %d", used, depth)
+	}
+}
+
+func TestNetworkTrain(t *testing.T) {
+	b, d := newSimBackend(t)
+	e := func(v float64) Ciphertext {
+		c, err := encrypt(b, b, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	n := &Network{
+		Encryptor: b, Evaluator: b, Encoder: b,
+		Layers: []*Dense{NewDense(2, 2, Square{}), NewDense(2, 1, Identity{})},
+	}
+	if err := n.Init(); err != nil {
+		t.Fatal(err)
+	}
+	patterns := [][][]Ciphertext{
+		{{e(0), e(1)}, {e(1)}},
+		{{e(1), e(1)}, {e(0)}},
+	}
+	res, err := n.Train(patterns, TrainOptions{Epochs: 2, LearningRate: 0.1, Momentum: 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Losses) != 2 {
+		t.Fatalf("Train() returned %d losses; want 2", len(res.Losses))
+	}
+	for i, l := range res.Losses {
+		p, err := d.Decrypt(l.(*sim.Ciphertext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v, _ := b.Encoder.Decode(p); v < 0 || math.IsNaN(v) {
+			t.Errorf("loss %d = %f; want a non-negative number", i, v)
+		}
+	}
+
+	// The chain has room for a few passes but not for a hundred.
+	if _, err := n.Train(patterns, TrainOptions{Epochs: 100, LearningRate: 0.1, Momentum: 0.1}); !errors.Is(err, ErrTooDeep) {
+		t.Errorf("Train(100 epochs) = %v; want ErrTooDeep", err)
+	}
+}
+
+func TestNetworkTooDeep(t *testing.T) {
+	b, _ := newSimBackend(t)
+	var layers []*Dense
+	for i := 0; i < 20; i++ {
+		layers = append(layers, NewDense(1, 1, nil))
+	}
+	n := &Network{Encryptor: b, Evaluator: b, Encoder: b, Layers: layers}
+	if err := n.Init(); !errors.Is(err, ErrTooDeep) {
+		t.Errorf("Init(20 sigmoid layers) = %v; want ErrTooDeep", err)
+	}
+
+	n.Layers = []*Dense{NewDense(2, 3, nil), NewDense(2, 1, nil)}
+	if err := n.Init(); err == nil {
+		t.Error("Init(mismatched layers) succeeded")
+	}
+}
+
+// TestNetworkDefaultDepth checks the chain lengths CheckDepth documents for
+// two layers with the default sigmoid.
+func TestNetworkDefaultDepth(t *testing.T) {
+	layers := func() []*Dense {
+		return []*Dense{NewDense(2, 2, nil), NewDense(2, 1, nil)}
+	}
+	b, _ := newSimBackendParams(t, sim.DefaultParams())
+	n := &Network{Encryptor: b, Evaluator: b, Encoder: b, Layers: layers()}
+	if err := n.Init(); !errors.Is(err, ErrTooDeep) {
+		t.Errorf("Init(default parameters) = %v; want ErrTooDeep", err)
+	}
+
+	b, d := newSimBackend(t)
+	n = &Network{Encryptor: b, Evaluator: b, Encoder: b, Layers: layers()}
+	if err := n.Init(); err != nil {
+		t.Fatal(err)
+	}
+	for steps, want := range []int{8, 21, 34} {
+		if got, err := n.Depth(steps); err != nil || got != want {
+			t.Errorf("Depth(%d) = %d, %v; want %d", steps, got, err, want)
+		}
+	}
+	e := func(v float64) Ciphertext {
+		c, err := encrypt(b, b, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	patterns := [][][]Ciphertext{
+		{{e(0), e(1)}, {e(1)}},
+		{{e(1), e(1)}, {e(0)}},
+	}
+	// 64 levels hold 4 steps.
+	res, err := n.Train(patterns, TrainOptions{Epochs: 2, LearningRate: 0.6, Momentum: 0.4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := d.Decrypt(res.Losses[1].(*sim.Ciphertext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := b.Encoder.Decode(p); v < 0 || math.IsNaN(v) {
+		t.Errorf("loss = %f; want a non-negative number", v)
+	}
+	if _, err := n.Train(patterns[:1], TrainOptions{Epochs: 1}); !errors.Is(err, ErrTooDeep) {
+		t.Errorf("Train(fifth step) = %v; want ErrTooDeep", err)
+	}
+}
+
+// TestNetworkDepthTracksWeights trains one step at a time until the
+// weights run out of levels, which CheckDepth must see coming.
+func TestNetworkDepthTracksWeights(t *testing.T) {
+	b, _ := newSimBackend(t)
+	e := func(v float64) Ciphertext {
+		c, err := encrypt(b, b, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	n := &Network{
+		Encryptor: b, Evaluator: b, Encoder: b,
+		Layers: []*Dense{NewDense(2, 2, Square{}), NewDense(2, 1, Identity{})},
+	}
+	if err := n.Init(); err != nil {
+		t.Fatal(err)
+	}
+	patterns := [][][]Ciphertext{{{e(0), e(1)}, {e(1)}}}
+
+	last := -1
+	for i := 0; ; i++ {
+		depth, err := n.Depth(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if depth <= last {
+			t.Fatalf("step %d: Depth(1) = %d; want more than %d", i, depth, last)
+		}
+		last = depth
+		level, err := b.Level(n.Layers[0].Weights[0][0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = n.Train(patterns, TrainOptions{Epochs: 1, LearningRate: 0.1, Momentum: 0.1})
+		if errors.Is(err, ErrTooDeep) {
+			after, _ := b.Level(n.Layers[0].Weights[0][0])
+			if after != level {
+				t.Errorf("failed Train changed the weights from level %d to %d", level, after)
+			}
+			if i == 0 {
+				t.Error("no training step fit in the levels")
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("step %d: Train() = %v; want ErrTooDeep once the levels run out", i, err)
+		}
+	}
+}
+
+func TestNetworkNotInitialized(t *testing.T) {
+	b, _ := newSimBackend(t)
+	x, err := encrypt(b, b, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []*Network{
+		{Encryptor: b, Evaluator: b, Encoder: b},
+		{Encryptor: b, Evaluator: b, Encoder: b, Layers: []*Dense{NewDense(1, 1, nil)}},
+	} {
+		if _, err := n.Forward([]Ciphertext{x}); !errors.Is(err, ErrNotInitialized) {
+			t.Errorf("Forward(%d layers, no Init) = %v; want ErrNotInitialized", len(n.Layers), err)
+		}
+		if _, err := n.Backward([]Ciphertext{x}, 0.1, 0.1); !errors.Is(err, ErrNotInitialized) {
+			t.Errorf("Backward(%d layers, no Init) = %v; want ErrNotInitialized", len(n.Layers), err)
+		}
+	}
+
+	n := &Network{Encryptor: b, Evaluator: b, Encoder: b, Layers: []*Dense{NewDense(1, 1, Identity{})}}
+	if err := n.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n.Backward([]Ciphertext{x}, 0.1, 0.1); err == nil {
+		t.Error("Backward(no Forward pass) succeeded")
+	}
+}
@@ -0,0 +1,240 @@
+package gobrain
+
+import (
+	"fmt"
+)
+
+// Packed is a FeedForward network prepared for SIMD inference. A ciphertext
+// holds a whole batch of activation vectors, each sample in its own block
+// of Width slots, and every layer is a matrix-vector product by the
+// diagonal method: one rotation and one multiplication per nonzero
+// diagonal, instead of one multiplication per weight and sample.
+type Packed struct {
+	Encryptor Encryptor
+	Evaluator Evaluator
+	Packer    Packer
+
+	// Number of input and output values of a sample
+	NInputs, NOutputs int
+	// Slots per sample, the widest layer of the network
+	Width int
+
+	layers []*packedLayer
+}
+
+// packedLayer is the sum over i of diagonals[i] times the input rotated by
+// steps[i], plus bias. Layers of cleartext weights keep their diagonals and
+// bias as slot values instead.
+type packedLayer struct {
+	steps     []int
+	diagonals []Ciphertext
+	bias      Ciphertext
+
+	plainDiagonals [][]float64
+	plainBias      []float64
+
+	act Activation
+}
+
+// diagonal lists the weight read at each output slot j after rotating the
+// input by step: row rows[j] of the weight matrix, or none if it is -1.
+type diagonal struct {
+	step int
+	rows []int
+}
+
+// layout returns the nonzero diagonals of an in by out matrix in blocks of
+// width slots. Slot j of diagonal k holds w[(j+k) mod width][j]; each
+// diagonal is split in the part that reads within the block after rotating
+// by k and the part that wraps around, which is read after rotating by
+// k-width instead.
+func layout(in, out, width int) []diagonal {
+	var diags []diagonal
+	for k := 0; k < width; k++ {
+		for _, wrap := range []bool{false, true} {
+			d := diagonal{step: k, rows: make([]int, out)}
+			if wrap {
+				d.step -= width
+			}
+			used := false
+			for j := range d.rows {
+				i := j + d.step
+				if i < 0 || i >= width || i >= in {
+					i = -1
+				}
+				d.rows[j] = i
+				used = used || i >= 0
+			}
+			if used {
+				diags = append(diags, d)
+			}
+		}
+	}
+	return diags
+}
+
+func newPacked(e Encryptor, ev Evaluator, p Packer, inputs, hiddens, outputs int) (*Packed, error) {
+	pk := &Packed{
+		Encryptor: e,
+		Evaluator: ev,
+		Packer:    p,
+		NInputs:   inputs,
+		NOutputs:  outputs,
+		Width:     maxInt(inputs, maxInt(hiddens, outputs)),
+	}
+	if pk.Width > p.Slots() {
+		return nil, fmt.Errorf("gobrain: %d values per sample for %d slots", pk.Width, p.Slots())
+	}
+	return pk, nil
+}
+
+// Pack prepares the network's current weights for packed inference with p,
+// which must be the backend of the network. Packing multiplies every weight
+// by a mask once, so packed inference uses one more level than Update.
+// Galois keys for rotations by up to Width slots either way are needed.
+func (nn *FeedForward) Pack(p Packer) (*Packed, error) {
+	if len(nn.Contexts) > 0 {
+		return nil, fmt.Errorf("gobrain: cannot pack a network with contexts")
+	}
+	inputs, hiddens := nn.NInputs-1, nn.NHiddens-1
+	pk, err := newPacked(nn.Encryptor, nn.Evaluator, p, inputs, hiddens, nn.NOutputs)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range []struct {
+		weights  [][]Ciphertext
+		in, out  int
+		function Activation
+	}{
+		{nn.InputWeights, inputs, hiddens, nn.HiddenFunc},
+		{nn.OutputWeights, hiddens, nn.NOutputs, nn.OutputFunc},
+	} {
+		layer := &packedLayer{act: l.function}
+		for _, d := range layout(l.in, l.out, pk.Width) {
+			var diag Ciphertext
+			for j, i := range d.rows {
+				if i < 0 {
+					continue
+				}
+				if diag, err = pk.place(diag, l.weights[i][j], j); err != nil {
+					return nil, err
+				}
+			}
+			layer.steps = append(layer.steps, d.step)
+			layer.diagonals = append(layer.diagonals, diag)
+		}
+		for j := 0; j < l.out; j++ {
+			if layer.bias, err = pk.place(layer.bias, l.weights[l.in][j], j); err != nil {
+				return nil, err
+			}
+		}
+		pk.layers = append(pk.layers, layer)
+	}
+	return pk, nil
+}
+
+// place adds v, masked to slot j of every block, to sum.
+func (pk *Packed) place(sum, v Ciphertext, j int) (Ciphertext, error) {
+	mask := make([]float64, pk.Packer.Slots())
+	for s := j; s < len(mask); s += pk.Width {
+		mask[s] = 1
+	}
+	c, err := pk.Packer.MulVector(v, mask)
+	if err != nil {
+		return nil, err
+	}
+	if sum == nil {
+		return c, nil
+	}
+	return pk.Evaluator.Add(sum, c)
+}
+
+// spread returns the slot values holding v[j] at slot j of every block.
+func (pk *Packed) spread(v func(j int) float64, n int) []float64 {
+	slots := make([]float64, pk.Packer.Slots())
+	for s := range slots {
+		if j := s % pk.Width; j < n && s < pk.Batch()*pk.Width {
+			slots[s] = v(j)
+		}
+	}
+	return slots
+}
+
+// Batch returns the number of samples a ciphertext holds.
+func (pk *Packed) Batch() int {
+	return pk.Packer.Slots() / pk.Width
+}
+
+// Encrypt packs and encrypts up to Batch samples of NInputs values each.
+func (pk *Packed) Encrypt(samples [][]float64) (Ciphertext, error) {
+	if len(samples) > pk.Batch() {
+		return nil, fmt.Errorf("%w: %d samples for a batch of %d", ErrShape, len(samples), pk.Batch())
+	}
+	v := make([]float64, pk.Packer.Slots())
+	for b, s := range samples {
+		if len(s) != pk.NInputs {
+			return nil, fmt.Errorf("%w: sample %d has %d inputs for %d input nodes", ErrShape, b, len(s), pk.NInputs)
+		}
+		copy(v[b*pk.Width:], s)
+	}
+	p, err := pk.Packer.EncodeVector(v)
+	if err != nil {
+		return nil, err
+	}
+	return pk.Encryptor.Encrypt(p)
+}
+
+// Unpack splits the decrypted slots of an Update result into the outputs
+// of the given number of samples.
+func (pk *Packed) Unpack(slots []float64, samples int) [][]float64 {
+	out := make([][]float64, samples)
+	for b := range out {
+		out[b] = append([]float64(nil), slots[b*pk.Width:b*pk.Width+pk.NOutputs]...)
+	}
+	return out
+}
+
+// Update activates the network on a batch packed by Encrypt. Slots outside
+// the first NOutputs of each block hold garbage.
+func (pk *Packed) Update(x Ciphertext) (Ciphertext, error) {
+	for _, l := range pk.layers {
+		var sum Ciphertext
+		for i, step := range l.steps {
+			rotated := x
+			if step != 0 {
+				var err error
+				if rotated, err = pk.Packer.Rotate(x, step); err != nil {
+					return nil, err
+				}
+			}
+			var elem Ciphertext
+			var err error
+			if l.plainDiagonals != nil {
+				elem, err = pk.Packer.MulVector(rotated, l.plainDiagonals[i])
+			} else {
+				elem, err = pk.Evaluator.Mul(l.diagonals[i], rotated)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if sum == nil {
+				sum = elem
+			} else if sum, err = pk.Evaluator.Add(sum, elem); err != nil {
+				return nil, err
+			}
+		}
+		var err error
+		if l.plainBias != nil {
+			sum, err = pk.Packer.AddVector(sum, l.plainBias)
+		} else {
+			sum, err = pk.Evaluator.Add(sum, l.bias)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if x, err = l.act.Forward(pk.Evaluator, sum); err != nil {
+			return nil, err
+		}
+	}
+	return x, nil
+}
@@ -0,0 +1,71 @@
+package gobrain
+
+import (
+	"math"
+	"testing"
+
+	"github.com/d4l3k/go-fheml/sim"
+)
+
+func TestPackedMatchesUpdate(t *testing.T) {
+	b, d := newSimBackend(t)
+	ff := &FeedForward{Encryptor: b, Evaluator: b, Encoder: b}
+	if err := ff.Init(3, 4, 2); err != nil {
+		t.Fatal(err)
+	}
+	pk, err := ff.Pack(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pk.Width != 4 || pk.Batch() != 8 {
+		t.Fatalf("Width, Batch() = %d, %d; want 4, 8", pk.Width, pk.Batch())
+	}
+
+	samples := [][]float64{{0.5, -1, 0.25}, {1, 0, 0}, {-0.5, 0.75, 1}}
+	x, err := pk.Encrypt(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := pk.Update(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := d.Decrypt(y.(*sim.Ciphertext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	slots, err := b.Encoder.DecodeVector(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := pk.Unpack(slots, len(samples))
+
+	for s, sample := range samples {
+		var inputs []Ciphertext
+		for _, v := range sample {
+			c, err := encrypt(b, b, v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			inputs = append(inputs, c)
+		}
+		out, err := ff.Update(inputs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, c := range out {
+			p, err := d.Decrypt(c.(*sim.Ciphertext))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, _ := b.Encoder.Decode(p)
+			if math.Abs(got[s][j]-want) > 1e-6 {
+				t.Errorf("sample %d output %d = %g; want %g", s, j, got[s][j], want)
+			}
+		}
+	}
+
+	if _, err := pk.Encrypt([][]float64{{1, 2}}); err == nil {
+		t.Error("Encrypt(short sample) succeeded")
+	}
+}
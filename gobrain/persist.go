@@ -0,0 +1,392 @@
+package gobrain
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/d4l3k/go-fheml/poly"
+)
+
+// FormatVersion is the version of the format Save writes.
+const FormatVersion = 1
+
+// ErrFormat is returned by Load for data that is not a saved network, or
+// one saved by a newer version.
+var ErrFormat = errors.New("gobrain: unrecognized network format")
+
+var formatMagic = [8]byte{'g', 'o', 'b', 'r', 'a', 'i', 'n', 0}
+
+// maxCount bounds each size read by Load and maxWeights the total number of
+// weights. Load also allocates weights and coefficients only as their data
+// arrives, so a corrupt header cannot make it allocate much more than the
+// input holds.
+const (
+	maxCount   = 1 << 20
+	maxWeights = 1 << 24
+)
+
+// Kinds of weights.
+const (
+	encryptedWeights uint8 = iota
+	plainWeights
+)
+
+// Activation tags.
+const (
+	identityTag uint8 = iota
+	squareTag
+	polyTag
+)
+
+type header struct {
+	kind                     uint8
+	inputs, hiddens, outputs int
+	regression               bool
+	hidden, output           Activation
+}
+
+type writer struct {
+	w   io.Writer
+	err error
+}
+
+func (w *writer) put(v interface{}) {
+	if w.err == nil {
+		w.err = binary.Write(w.w, binary.LittleEndian, v)
+	}
+}
+
+func (w *writer) header(h *header) {
+	w.put(formatMagic)
+	w.put(uint32(FormatVersion))
+	w.put(h.kind)
+	w.put([]uint32{uint32(h.inputs), uint32(h.hiddens), uint32(h.outputs)})
+	w.put(h.regression)
+	w.activation(h.hidden)
+	w.activation(h.output)
+}
+
+func (w *writer) activation(a Activation) {
+	switch a := a.(type) {
+	case Identity:
+		w.put(identityTag)
+	case Square:
+		w.put(squareTag)
+	case *PolyActivation:
+		w.put(polyTag)
+		w.put(a.Bound)
+		w.polynomial(a.Poly)
+		w.polynomial(a.Deriv)
+	default:
+		if w.err == nil {
+			w.err = fmt.Errorf("gobrain: cannot save activation %T", a)
+		}
+	}
+}
+
+func (w *writer) polynomial(p *Polynomial) {
+	if p.Basis != poly.Chebyshev && w.err == nil {
+		w.err = fmt.Errorf("gobrain: cannot save a polynomial in the %v basis", p.Basis)
+	}
+	w.put(p.A)
+	w.put(p.B)
+	w.put(uint32(len(p.Coeffs)))
+	w.put(p.Coeffs)
+}
+
+func (w *writer) ciphertexts(s Serializer, m [][]Ciphertext) {
+	for _, row := range m {
+		for _, c := range row {
+			if w.err == nil {
+				w.err = s.SaveCiphertext(w.w, c)
+			}
+		}
+	}
+}
+
+type reader struct {
+	r   io.Reader
+	err error
+}
+
+func (r *reader) get(v interface{}) {
+	if r.err == nil {
+		r.err = binary.Read(r.r, binary.LittleEndian, v)
+	}
+}
+
+func (r *reader) fail(format string, args ...interface{}) {
+	if r.err == nil {
+		r.err = fmt.Errorf("%w: %s", ErrFormat, fmt.Sprintf(format, args...))
+	}
+}
+
+// count reads a size of at least min.
+func (r *reader) count(what string, min int) int {
+	var n uint32
+	r.get(&n)
+	if r.err == nil && (n < uint32(min) || n > maxCount) {
+		r.fail("%s %d out of range", what, n)
+	}
+	return int(n)
+}
+
+// weights fails if a network would have more than maxWeights weights.
+func (r *reader) weights(n int64) {
+	if r.err == nil && n > maxWeights {
+		r.fail("%d weights, want at most %d", n, maxWeights)
+	}
+}
+
+func (r *reader) header(kind uint8) *header {
+	var magic [8]byte
+	var version uint32
+	h := &header{}
+	r.get(&magic)
+	if r.err == nil && magic != formatMagic {
+		r.fail("bad magic %q", magic[:])
+	}
+	r.get(&version)
+	if r.err == nil && (version == 0 || version > FormatVersion) {
+		r.fail("version %d, want at most %d", version, FormatVersion)
+	}
+	r.get(&h.kind)
+	if r.err == nil && h.kind != kind {
+		r.fail("weights of kind %d, want %d", h.kind, kind)
+	}
+	// Sizes include the bias nodes.
+	h.inputs = r.count("input count", 2)
+	h.hiddens = r.count("hidden count", 2)
+	h.outputs = r.count("output count", 1)
+	r.weights(int64(h.inputs)*int64(h.hiddens) + int64(h.hiddens)*int64(h.outputs))
+	r.get(&h.regression)
+	h.hidden = r.activation()
+	h.output = r.activation()
+	if r.err != nil {
+		return nil
+	}
+	return h
+}
+
+func (r *reader) activation() Activation {
+	var tag uint8
+	r.get(&tag)
+	if r.err != nil {
+		return nil
+	}
+	switch tag {
+	case identityTag:
+		return Identity{}
+	case squareTag:
+		return Square{}
+	case polyTag:
+		a := &PolyActivation{}
+		r.get(&a.Bound)
+		a.Poly = r.polynomial()
+		a.Deriv = r.polynomial()
+		return a
+	}
+	r.fail("unknown activation %d", tag)
+	return nil
+}
+
+func (r *reader) polynomial() *Polynomial {
+	p := &Polynomial{Basis: poly.Chebyshev}
+	r.get(&p.A)
+	r.get(&p.B)
+	n := r.count("coefficient count", 1)
+	if r.err != nil {
+		return nil
+	}
+	if !(p.A < p.B) {
+		r.fail("empty interval [%g, %g]", p.A, p.B)
+		return nil
+	}
+	p.Coeffs = r.float64s(n)
+	return p
+}
+
+// float64s reads n values, growing the slice in chunks as they arrive.
+func (r *reader) float64s(n int) []float64 {
+	var v []float64
+	for len(v) < n && r.err == nil {
+		chunk := make([]float64, minInt(n-len(v), 1<<10))
+		r.get(chunk)
+		v = append(v, chunk...)
+	}
+	return v
+}
+
+// ciphertexts reads a rows by cols matrix, stopping at the first error.
+func (r *reader) ciphertexts(s Serializer, rows, cols int) [][]Ciphertext {
+	var m [][]Ciphertext
+	for i := 0; i < rows && r.err == nil; i++ {
+		row := make([]Ciphertext, 0, cols)
+		for j := 0; j < cols && r.err == nil; j++ {
+			var c Ciphertext
+			if c, r.err = s.LoadCiphertext(r.r); r.err == nil {
+				row = append(row, c)
+			}
+		}
+		m = append(m, row)
+	}
+	return m
+}
+
+// floats reads a rows by cols matrix, stopping at the first error.
+func (r *reader) floats(rows, cols int) [][]float64 {
+	var m [][]float64
+	for i := 0; i < rows && r.err == nil; i++ {
+		m = append(m, r.float64s(cols))
+	}
+	return m
+}
+
+func (nn *FeedForward) serializer() (Serializer, error) {
+	s, ok := nn.Evaluator.(Serializer)
+	if !ok {
+		return nil, fmt.Errorf("gobrain: %T cannot save ciphertexts", nn.Evaluator)
+	}
+	return s, nil
+}
+
+// Save writes the architecture, the encrypted weights, the momentum state
+// and the contexts of the network to w. The data starts with the magic
+// "gobrain\x00", the format version, the kind of weights and the
+// architecture: layer sizes including bias nodes, regression flag and both
+// activations. The weights follow as ciphertexts framed by the backend.
+// Numbers are little endian.
+func (nn *FeedForward) Save(w io.Writer) error {
+	s, err := nn.serializer()
+	if err != nil {
+		return err
+	}
+	out := &writer{w: w}
+	out.header(&header{
+		kind:       encryptedWeights,
+		inputs:     nn.NInputs,
+		hiddens:    nn.NHiddens,
+		outputs:    nn.NOutputs,
+		regression: nn.Regression,
+		hidden:     nn.HiddenFunc,
+		output:     nn.OutputFunc,
+	})
+	out.ciphertexts(s, nn.InputWeights)
+	out.ciphertexts(s, nn.OutputWeights)
+	out.ciphertexts(s, nn.InputChanges)
+	out.ciphertexts(s, nn.OutputChanges)
+	out.put(uint32(len(nn.Contexts)))
+	out.ciphertexts(s, nn.Contexts)
+	return out.err
+}
+
+// Load replaces the network with one written by Save, keeping its backend
+// and Logger. Loaded ciphertexts are checked against the backend's
+// encryption parameters.
+func (nn *FeedForward) Load(r io.Reader) error {
+	s, err := nn.serializer()
+	if err != nil {
+		return err
+	}
+	in := &reader{r: r}
+	h := in.header(encryptedWeights)
+	if in.err != nil {
+		return in.err
+	}
+	if err := defaultActivations(&h.hidden, &h.output, h.regression); err != nil {
+		return err
+	}
+	inputWeights := in.ciphertexts(s, h.inputs, h.hiddens)
+	outputWeights := in.ciphertexts(s, h.hiddens, h.outputs)
+	inputChanges := in.ciphertexts(s, h.inputs, h.hiddens)
+	outputChanges := in.ciphertexts(s, h.hiddens, h.outputs)
+	var contexts [][]Ciphertext
+	if n := in.count("context count", 0); in.err == nil {
+		in.weights(int64(n) * int64(h.hiddens))
+		contexts = in.ciphertexts(s, n, h.hiddens)
+	}
+	if in.err != nil {
+		return in.err
+	}
+
+	loaded := &FeedForward{
+		Encryptor:  nn.Encryptor,
+		Evaluator:  nn.Evaluator,
+		Encoder:    nn.Encoder,
+		NInputs:    h.inputs,
+		NHiddens:   h.hiddens,
+		NOutputs:   h.outputs,
+		Regression: h.regression,
+		HiddenFunc: h.hidden,
+		OutputFunc: h.output,
+		Contexts:   contexts,
+		Logger:     nn.Logger,
+
+		InputWeights:  inputWeights,
+		OutputWeights: outputWeights,
+		InputChanges:  inputChanges,
+		OutputChanges: outputChanges,
+	}
+	if err := loaded.initActivations(); err != nil {
+		return err
+	}
+	*nn = *loaded
+	return nil
+}
+
+// Save writes the architecture and weights of the network to w in the
+// format of FeedForward.Save, with the weights as float64s.
+func (nn *PlainFeedForward) Save(w io.Writer) error {
+	inputs, hiddens, outputs, err := nn.check()
+	if err != nil {
+		return err
+	}
+	out := &writer{w: w}
+	out.header(&header{
+		kind:       plainWeights,
+		inputs:     inputs + 1,
+		hiddens:    hiddens + 1,
+		outputs:    outputs,
+		regression: nn.Regression,
+		hidden:     nn.HiddenFunc,
+		output:     nn.OutputFunc,
+	})
+	for _, row := range nn.InputWeights {
+		out.put(row)
+	}
+	for _, row := range nn.OutputWeights {
+		out.put(row)
+	}
+	return out.err
+}
+
+// Load replaces the weights and architecture of the network with those
+// written by Save, keeping its backend.
+func (nn *PlainFeedForward) Load(r io.Reader) error {
+	in := &reader{r: r}
+	h := in.header(plainWeights)
+	if in.err != nil {
+		return in.err
+	}
+	inputWeights := in.floats(h.inputs, h.hiddens-1)
+	outputWeights := in.floats(h.hiddens, h.outputs)
+	if in.err != nil {
+		return in.err
+	}
+	loaded := &PlainFeedForward{
+		Encryptor:     nn.Encryptor,
+		Evaluator:     nn.Evaluator,
+		Regression:    h.regression,
+		HiddenFunc:    h.hidden,
+		OutputFunc:    h.output,
+		InputWeights:  inputWeights,
+		OutputWeights: outputWeights,
+	}
+	if _, _, _, err := loaded.check(); err != nil {
+		return err
+	}
+	*nn = *loaded
+	return nil
+}
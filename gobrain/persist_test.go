@@ -0,0 +1,184 @@
+package gobrain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/d4l3k/go-fheml/sim"
+)
+
+func TestFeedForwardSaveLoad(t *testing.T) {
+	b, d := newSimBackend(t)
+	value := func(c Ciphertext) float64 {
+		p, err := d.Decrypt(c.(*sim.Ciphertext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := b.Encoder.Decode(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	ff := &FeedForward{Encryptor: b, Evaluator: b, Encoder: b, HiddenFunc: Square{}}
+	if err := ff.Init(2, 3, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := ff.SetContexts(1, nil); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := ff.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	loaded := &FeedForward{Encryptor: b, Evaluator: b, Encoder: b}
+	if err := loaded.Load(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.NInputs != ff.NInputs || loaded.NHiddens != ff.NHiddens || loaded.NOutputs != ff.NOutputs {
+		t.Errorf("loaded sizes %d, %d, %d; want %d, %d, %d",
+			loaded.NInputs, loaded.NHiddens, loaded.NOutputs, ff.NInputs, ff.NHiddens, ff.NOutputs)
+	}
+	if _, ok := loaded.HiddenFunc.(Square); !ok {
+		t.Errorf("loaded HiddenFunc = %T; want Square", loaded.HiddenFunc)
+	}
+	if len(loaded.Contexts) != 1 {
+		t.Errorf("loaded %d contexts; want 1", len(loaded.Contexts))
+	}
+	for i, row := range ff.InputWeights {
+		for j, w := range row {
+			if got, want := value(loaded.InputWeights[i][j]), value(w); got != want {
+				t.Errorf("input weight %d, %d = %g; want %g", i, j, got, want)
+			}
+		}
+	}
+
+	x, err := encrypt(b, b, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ff.Update([]Ciphertext{x, x})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := loaded.Update([]Ciphertext{x, x})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := value(got[0]), value(want[0]); math.Abs(g-w) > 1e-6 {
+		t.Errorf("loaded Update() = %g; want %g", g, w)
+	}
+
+	other, err := sim.NewContext(sim.Params{PolyModulusDegree: 64, CoeffModulusBits: []int{60, 60}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mismatched := &FeedForward{Encryptor: b, Evaluator: &Sim{Context: other}, Encoder: b}
+	if err := mismatched.Load(bytes.NewReader(data)); !errors.Is(err, sim.ErrParmsIDMismatch) {
+		t.Errorf("Load(other parameters) = %v; want sim.ErrParmsIDMismatch", err)
+	}
+
+	future := append([]byte(nil), data...)
+	binary.LittleEndian.PutUint32(future[8:], FormatVersion+1)
+	if err := loaded.Load(bytes.NewReader(future)); !errors.Is(err, ErrFormat) {
+		t.Errorf("Load(future version) = %v; want ErrFormat", err)
+	}
+	if err := loaded.Load(bytes.NewReader([]byte("not a network"))); !errors.Is(err, ErrFormat) {
+		t.Errorf("Load(garbage) = %v; want ErrFormat", err)
+	}
+	if err := loaded.Load(bytes.NewReader(data[:len(data)-5])); err == nil {
+		t.Error("Load(truncated) succeeded")
+	}
+}
+
+func TestPlainFeedForwardSaveLoad(t *testing.T) {
+	b, _ := newSimBackend(t)
+	nn := &PlainFeedForward{
+		Encryptor:     b,
+		Evaluator:     b,
+		Regression:    true,
+		InputWeights:  [][]float64{{0.5, -1}, {0.25, 2}},
+		OutputWeights: [][]float64{{1}, {-0.5}, {0.75}},
+	}
+	var buf bytes.Buffer
+	if err := nn.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded := &PlainFeedForward{Encryptor: b, Evaluator: b}
+	if err := loaded.Load(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.InputWeights, nn.InputWeights) || !reflect.DeepEqual(loaded.OutputWeights, nn.OutputWeights) {
+		t.Errorf("loaded weights %v, %v; want %v, %v", loaded.InputWeights, loaded.OutputWeights, nn.InputWeights, nn.OutputWeights)
+	}
+	if !loaded.Regression || !reflect.DeepEqual(loaded.HiddenFunc, nn.HiddenFunc) {
+		t.Errorf("loaded Regression %v and HiddenFunc %v; want true and %v", loaded.Regression, loaded.HiddenFunc, nn.HiddenFunc)
+	}
+
+	ff := &FeedForward{Encryptor: b, Evaluator: b, Encoder: b}
+	if err := ff.Load(bytes.NewReader(buf.Bytes())); !errors.Is(err, ErrFormat) {
+		t.Errorf("FeedForward.Load(plaintext weights) = %v; want ErrFormat", err)
+	}
+}
+
+// testHeader returns a saved network header with the given sizes, bias nodes
+// included, and identity activations.
+func testHeader(kind uint8, inputs, hiddens, outputs uint32) []byte {
+	var buf bytes.Buffer
+	for _, v := range []interface{}{
+		formatMagic, uint32(FormatVersion), kind,
+		[]uint32{inputs, hiddens, outputs}, false, identityTag, identityTag,
+	} {
+		binary.Write(&buf, binary.LittleEndian, v)
+	}
+	return buf.Bytes()
+}
+
+func TestLoadLimits(t *testing.T) {
+	b, _ := newSimBackend(t)
+	huge := testHeader(plainWeights, 1<<20, 1<<20, 1)
+	if err := (&PlainFeedForward{}).Load(bytes.NewReader(huge)); !errors.Is(err, ErrFormat) {
+		t.Errorf("PlainFeedForward.Load(2^40 weights) = %v; want ErrFormat", err)
+	}
+	huge = testHeader(encryptedWeights, 1<<20, 1<<20, 1)
+	ff := &FeedForward{Encryptor: b, Evaluator: b, Encoder: b}
+	if err := ff.Load(bytes.NewReader(huge)); !errors.Is(err, ErrFormat) {
+		t.Errorf("FeedForward.Load(2^40 weights) = %v; want ErrFormat", err)
+	}
+
+	// A header within the limits but without its weights fails after
+	// reading the first row, without allocating the rest.
+	large := testHeader(plainWeights, 1<<11, 1<<12, 1)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if err := (&PlainFeedForward{}).Load(bytes.NewReader(large)); err == nil {
+		t.Error("PlainFeedForward.Load(header only) succeeded")
+	}
+	runtime.ReadMemStats(&after)
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("PlainFeedForward.Load(header only) allocated %d bytes", n)
+	}
+	// So does an activation promising more coefficients than follow.
+	var buf bytes.Buffer
+	for _, v := range []interface{}{
+		formatMagic, uint32(FormatVersion), plainWeights,
+		[]uint32{3, 3, 1}, false, polyTag, 8.0, -8.0, 8.0, uint32(1 << 20),
+	} {
+		binary.Write(&buf, binary.LittleEndian, v)
+	}
+	runtime.ReadMemStats(&before)
+	if err := (&PlainFeedForward{}).Load(&buf); err == nil {
+		t.Error("PlainFeedForward.Load(short polynomial) succeeded")
+	}
+	runtime.ReadMemStats(&after)
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("PlainFeedForward.Load(short polynomial) allocated %d bytes", n)
+	}
+}
@@ -0,0 +1,130 @@
+package gobrain
+
+import (
+	"fmt"
+)
+
+// PlainFeedForward serves a network trained in the clear on encrypted
+// inputs. Its weights stay cleartext and are applied with plaintext
+// multiplications and additions, so the weight products need no
+// relinearization keys and the same model works with any client's keys.
+type PlainFeedForward struct {
+	Encryptor Encryptor
+	Evaluator Evaluator
+
+	// Regression uses a linear output
+	Regression bool
+	// Activation functions of the hidden and output layers; the defaults
+	// are those of FeedForward
+	HiddenFunc, OutputFunc Activation
+	// InputWeights[i][j] connects input i to hidden node j and
+	// OutputWeights[i][j] hidden node i to output j, the last row of each
+	// holding the bias, as in FeedForward
+	InputWeights, OutputWeights [][]float64
+}
+
+// check validates the weight shapes, defaults missing activations and
+// returns the number of inputs, hidden nodes and outputs without biases.
+func (nn *PlainFeedForward) check() (inputs, hiddens, outputs int, err error) {
+	if len(nn.InputWeights) < 2 || len(nn.OutputWeights) < 2 {
+		return 0, 0, 0, fmt.Errorf("gobrain: weights need a row per input and one for the bias")
+	}
+	inputs, hiddens = len(nn.InputWeights)-1, len(nn.OutputWeights)-1
+	outputs = len(nn.OutputWeights[0])
+	for i, row := range nn.InputWeights {
+		if len(row) != hiddens {
+			return 0, 0, 0, fmt.Errorf("gobrain: input weight row %d has %d columns for %d hidden nodes", i, len(row), hiddens)
+		}
+	}
+	for i, row := range nn.OutputWeights {
+		if len(row) != outputs {
+			return 0, 0, 0, fmt.Errorf("gobrain: output weight row %d has %d columns for %d outputs", i, len(row), outputs)
+		}
+	}
+	if err := defaultActivations(&nn.HiddenFunc, &nn.OutputFunc, nn.Regression); err != nil {
+		return 0, 0, 0, err
+	}
+	return inputs, hiddens, outputs, nil
+}
+
+// Update activates the network on encrypted inputs.
+func (nn *PlainFeedForward) Update(inputs []Ciphertext) ([]Ciphertext, error) {
+	nInputs, _, _, err := nn.check()
+	if err != nil {
+		return nil, err
+	}
+	if len(inputs) != nInputs {
+		return nil, fmt.Errorf("%w: %d inputs for %d input nodes", ErrShape, len(inputs), nInputs)
+	}
+	hidden, err := nn.layer(inputs, nn.InputWeights, nn.HiddenFunc)
+	if err != nil {
+		return nil, err
+	}
+	return nn.layer(hidden, nn.OutputWeights, nn.OutputFunc)
+}
+
+func (nn *PlainFeedForward) layer(inputs []Ciphertext, w [][]float64, f Activation) ([]Ciphertext, error) {
+	bias := len(w) - 1
+	out := make([]Ciphertext, len(w[0]))
+	for j := range out {
+		var sum Ciphertext
+		for i, in := range inputs {
+			elem, err := nn.Evaluator.MulConst(in, w[i][j])
+			if err != nil {
+				return nil, err
+			}
+			if sum == nil {
+				sum = elem
+			} else if sum, err = nn.Evaluator.Add(sum, elem); err != nil {
+				return nil, err
+			}
+		}
+		sum, err := nn.Evaluator.AddConst(sum, w[bias][j])
+		if err != nil {
+			return nil, err
+		}
+		if out[j], err = f.Forward(nn.Evaluator, sum); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Pack prepares the network for packed inference with p, which must be the
+// backend of nn.Evaluator. Cleartext diagonals need no masking, so packed
+// inference uses no more levels than Update.
+func (nn *PlainFeedForward) Pack(p Packer) (*Packed, error) {
+	inputs, hiddens, outputs, err := nn.check()
+	if err != nil {
+		return nil, err
+	}
+	pk, err := newPacked(nn.Encryptor, nn.Evaluator, p, inputs, hiddens, outputs)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range []struct {
+		weights  [][]float64
+		in, out  int
+		function Activation
+	}{
+		{nn.InputWeights, inputs, hiddens, nn.HiddenFunc},
+		{nn.OutputWeights, hiddens, outputs, nn.OutputFunc},
+	} {
+		layer := &packedLayer{act: l.function}
+		for _, d := range layout(l.in, l.out, pk.Width) {
+			rows := d.rows
+			diag := pk.spread(func(j int) float64 {
+				if rows[j] < 0 {
+					return 0
+				}
+				return l.weights[rows[j]][j]
+			}, l.out)
+			layer.steps = append(layer.steps, d.step)
+			layer.plainDiagonals = append(layer.plainDiagonals, diag)
+		}
+		bias := l.weights[l.in]
+		layer.plainBias = pk.spread(func(j int) float64 { return bias[j] }, l.out)
+		pk.layers = append(pk.layers, layer)
+	}
+	return pk, nil
+}
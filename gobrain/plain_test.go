@@ -0,0 +1,105 @@
+package gobrain
+
+import (
+	"math"
+	"testing"
+
+	"github.com/d4l3k/go-fheml/sim"
+)
+
+func TestPlainFeedForward(t *testing.T) {
+	b, d := newSimBackend(t)
+	decrypt := func(c Ciphertext) []float64 {
+		p, err := d.Decrypt(c.(*sim.Ciphertext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := b.Encoder.DecodeVector(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	nn := &PlainFeedForward{
+		Encryptor: b,
+		Evaluator: b,
+		InputWeights: [][]float64{
+			{0.5, -1, 0.25},
+			{-0.75, 0.5, 1},
+			{0.1, 0.2, -0.3},
+		},
+		OutputWeights: [][]float64{{1}, {-0.5}, {0.75}, {0.2}},
+	}
+	in := []float64{0.5, -1}
+	var inputs []Ciphertext
+	for _, v := range in {
+		c, err := encrypt(b, b, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, c)
+	}
+	out, err := nn.Update(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sigmoid := nn.HiddenFunc.(*PolyActivation).Poly
+	want := nn.OutputWeights[3][0]
+	for j := 0; j < 3; j++ {
+		sum := nn.InputWeights[2][j]
+		for i, v := range in {
+			sum += v * nn.InputWeights[i][j]
+		}
+		want += sigmoid.Evaluate(sum) * nn.OutputWeights[j][0]
+	}
+	want = sigmoid.Evaluate(want)
+	if got := decrypt(out[0])[0]; math.Abs(got-want) > 1e-6 {
+		t.Errorf("Update() = %g; want %g", got, want)
+	}
+
+	pk, err := nn.Pack(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := pk.Encrypt([][]float64{{1, 1}, in})
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := pk.Update(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := pk.Unpack(decrypt(y), 2)[1][0]; math.Abs(got-want) > 1e-6 {
+		t.Errorf("packed Update() = %g; want %g", got, want)
+	}
+	scalar, _ := b.Level(out[0])
+	if packed, _ := b.Level(y); packed != scalar {
+		t.Errorf("packed Update() left %d levels; Update() left %d", packed, scalar)
+	}
+
+	nn.OutputWeights[1] = []float64{1, 2}
+	if _, err := nn.Update(inputs); err == nil {
+		t.Error("Update(ragged weights) succeeded")
+	}
+}
+
+func TestPlainFeedForwardNeedsNoRelinKeys(t *testing.T) {
+	b, _ := newSimBackend(t)
+	b.Evaluator.RelinKeys = nil
+	nn := &PlainFeedForward{
+		Encryptor:     b,
+		Evaluator:     b,
+		Regression:    true,
+		HiddenFunc:    Identity{},
+		InputWeights:  [][]float64{{2}, {1}},
+		OutputWeights: [][]float64{{3}, {-1}},
+	}
+	x, err := encrypt(b, b, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := nn.Update([]Ciphertext{x}); err != nil {
+		t.Errorf("Update() without relinearization keys = %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package gobrain
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/d4l3k/go-fheml/poly"
+)
+
+// Polynomial is the polynomial type shared with the backends. The networks
+// use Chebyshev series over an interval [A, B], which evaluate stably at
+// high degree.
+type Polynomial = poly.Polynomial
+
+// Fit selects how a function is approximated by a polynomial.
+type Fit int
+
+const (
+	// ChebyshevFit interpolates at the Chebyshev nodes of the interval,
+	// which comes close to the best uniform approximation.
+	ChebyshevFit Fit = iota
+	// LeastSquaresFit minimizes the squared error over evenly spaced
+	// samples of the interval.
+	LeastSquaresFit
+)
+
+func (f Fit) String() string {
+	switch f {
+	case ChebyshevFit:
+		return "Chebyshev"
+	case LeastSquaresFit:
+		return "least squares"
+	}
+	return fmt.Sprintf("Fit(%d)", int(f))
+}
+
+// Approximate returns a polynomial of the given degree approximating f over
+// [a, b].
+func Approximate(f func(float64) float64, degree int, a, b float64, fit Fit) (*Polynomial, error) {
+	if degree < 0 {
+		return nil, fmt.Errorf("gobrain: negative polynomial degree %d", degree)
+	}
+	if !(a < b) {
+		return nil, fmt.Errorf("gobrain: empty interval [%g, %g]", a, b)
+	}
+	switch fit {
+	case ChebyshevFit:
+		return chebyshevInterpolate(f, degree, a, b), nil
+	case LeastSquaresFit:
+		return leastSquares(f, degree, a, b)
+	}
+	return nil, fmt.Errorf("gobrain: unknown fit %v", fit)
+}
+
+func chebyshevInterpolate(f func(float64) float64, degree int, a, b float64) *Polynomial {
+	n := degree + 1
+	fx := make([]float64, n)
+	for k := range fx {
+		t := math.Cos(math.Pi * (float64(k) + 0.5) / float64(n))
+		fx[k] = f((b-a)/2*t + (a+b)/2)
+	}
+	c := make([]float64, n)
+	for j := range c {
+		var s float64
+		for k, v := range fx {
+			s += v * math.Cos(math.Pi*float64(j)*(float64(k)+0.5)/float64(n))
+		}
+		c[j] = 2 * s / float64(n)
+	}
+	c[0] /= 2
+	return &Polynomial{Basis: poly.Chebyshev, Coeffs: c, A: a, B: b}
+}
+
+// leastSquares fits the Chebyshev coefficients by solving the normal
+// equations over 16 samples per coefficient.
+func leastSquares(f func(float64) float64, degree int, a, b float64) (*Polynomial, error) {
+	n := degree + 1
+	samples := 16 * n
+	// Normal equations augmented with the right-hand side.
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, n+1)
+	}
+	t := make([]float64, n)
+	for s := 0; s < samples; s++ {
+		x := -1 + 2*float64(s)/float64(samples-1)
+		y := f((b-a)/2*x + (a+b)/2)
+		t[0] = 1
+		if n > 1 {
+			t[1] = x
+		}
+		for j := 2; j < n; j++ {
+			t[j] = 2*x*t[j-1] - t[j-2]
+		}
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				m[i][j] += t[i] * t[j]
+			}
+			m[i][n] += t[i] * y
+		}
+	}
+	c, err := solve(m)
+	if err != nil {
+		return nil, err
+	}
+	return &Polynomial{Basis: poly.Chebyshev, Coeffs: c, A: a, B: b}, nil
+}
+
+// solve solves the augmented linear system m by Gaussian elimination with
+// partial pivoting.
+func solve(m [][]float64) ([]float64, error) {
+	n := len(m)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(m[r][col]) > math.Abs(m[pivot][col]) {
+				pivot = r
+			}
+		}
+		if m[pivot][col] == 0 {
+			return nil, fmt.Errorf("gobrain: singular least squares system")
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		for r := col + 1; r < n; r++ {
+			f := m[r][col] / m[col][col]
+			for c := col; c <= n; c++ {
+				m[r][c] -= f * m[col][c]
+			}
+		}
+	}
+	x := make([]float64, n)
+	for r := n - 1; r >= 0; r-- {
+		s := m[r][n]
+		for c := r + 1; c < n; c++ {
+			s -= m[r][c] * x[c]
+		}
+		x[r] = s / m[r][r]
+	}
+	return x, nil
+}
@@ -0,0 +1,63 @@
+package sealbackend_test
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/d4l3k/go-fheml/gobrain"
+	"github.com/d4l3k/go-fheml/gobrain/sealbackend"
+	"github.com/d4l3k/go-fheml/seal"
+)
+
+func ExampleBackend() {
+	check := func(err error) {
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	params, err := seal.NewEncryptionParamsCKKS()
+	check(err)
+	c, err := seal.NewContext(params)
+	check(err)
+	g, err := seal.NewKeyGenerator(c)
+	check(err)
+	pub, err := g.PublicKey()
+	check(err)
+	sec, err := g.SecretKey()
+	check(err)
+	relin, err := g.RelinKeys(60, 2)
+	check(err)
+
+	encr, err := seal.NewEncryptor(c, pub)
+	check(err)
+	enco, err := seal.NewCKKSEncoder(c)
+	check(err)
+	decr, err := seal.NewDecryptor(c, sec)
+	check(err)
+	eval, err := seal.NewAutoEvaluator(c, relin)
+	check(err)
+
+	backend := &sealbackend.Backend{Encoder: enco, Encryptor: encr, Evaluator: eval, Context: c}
+	ff := &gobrain.FeedForward{
+		Encryptor: backend,
+		Evaluator: backend,
+		Encoder:   backend,
+	}
+	check(ff.Init(2, 2, 1))
+
+	e := func(a float64) gobrain.Ciphertext {
+		p, err := backend.Encode(a)
+		check(err)
+		cipher, err := backend.Encrypt(p)
+		check(err)
+		return cipher
+	}
+	out, err := ff.Update([]gobrain.Ciphertext{e(1), e(0)})
+	check(err)
+
+	d := &sealbackend.Decrypter{Decryptor: decr, Encoder: enco}
+	v, err := d.Decrypt(out[0])
+	check(err)
+	fmt.Printf("%.2f\n", v)
+}
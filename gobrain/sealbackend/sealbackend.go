@@ -0,0 +1,174 @@
+/*
+Package sealbackend runs gobrain networks on package seal. It is kept apart
+from gobrain so that gobrain builds, and runs on its simulator, without
+cgo and the SEAL library.
+*/
+package sealbackend
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/d4l3k/go-fheml/gobrain"
+	"github.com/d4l3k/go-fheml/seal"
+)
+
+type (
+	Ciphertext = gobrain.Ciphertext
+	Plaintext  = gobrain.Plaintext
+)
+
+// Backend implements gobrain's Encoder, Encryptor, Evaluator, Packer and
+// Serializer; its ciphertexts are *seal.Ciphertext.
+type Backend struct {
+	Encoder   *seal.CKKSEncoder
+	Encryptor *seal.Encryptor
+	Evaluator *seal.AutoEvaluator
+	// Context checks loaded ciphertexts; only LoadCiphertext needs it.
+	Context *seal.Context
+}
+
+func (b *Backend) Encode(v float64) (Plaintext, error) {
+	return b.Encoder.Encode(v)
+}
+
+func (b *Backend) Encrypt(p Plaintext) (Ciphertext, error) {
+	plain, ok := p.(*seal.Plaintext)
+	if !ok {
+		return nil, mismatch("Backend.Encrypt", p)
+	}
+	return b.Encryptor.Encrypt(plain)
+}
+
+func (b *Backend) Add(x, y Ciphertext) (Ciphertext, error) {
+	return b.binary("Backend.Add", x, y, b.Evaluator.Add)
+}
+
+func (b *Backend) Sub(x, y Ciphertext) (Ciphertext, error) {
+	return b.binary("Backend.Sub", x, y, b.Evaluator.Sub)
+}
+
+func (b *Backend) Mul(x, y Ciphertext) (Ciphertext, error) {
+	return b.binary("Backend.Mul", x, y, b.Evaluator.Mul)
+}
+
+func (b *Backend) Square(x Ciphertext) (Ciphertext, error) {
+	return b.unary("Backend.Square", x, b.Evaluator.Square)
+}
+
+func (b *Backend) Negate(x Ciphertext) (Ciphertext, error) {
+	return b.unary("Backend.Negate", x, b.Evaluator.Negate)
+}
+
+func (b *Backend) AddConst(x Ciphertext, v float64) (Ciphertext, error) {
+	return b.unary("Backend.AddConst", x, func(c *seal.Ciphertext) (*seal.Ciphertext, error) {
+		return b.Evaluator.AddConst(c, v)
+	})
+}
+
+func (b *Backend) MulConst(x Ciphertext, v float64) (Ciphertext, error) {
+	return b.unary("Backend.MulConst", x, func(c *seal.Ciphertext) (*seal.Ciphertext, error) {
+		return b.Evaluator.MulConst(c, v)
+	})
+}
+
+func (b *Backend) EvaluatePolynomial(x Ciphertext, p *gobrain.Polynomial) (Ciphertext, error) {
+	return b.unary("Backend.EvaluatePolynomial", x, func(c *seal.Ciphertext) (*seal.Ciphertext, error) {
+		return b.Evaluator.EvaluatePolynomial(c, p)
+	})
+}
+
+func (b *Backend) Level(x Ciphertext) (int, error) {
+	c, ok := x.(*seal.Ciphertext)
+	if !ok {
+		return 0, mismatch("Backend.Level", x)
+	}
+	return b.Evaluator.Evaluator.Level(c)
+}
+
+func (b *Backend) Slots() int {
+	return b.Encoder.SlotCount()
+}
+
+func (b *Backend) EncodeVector(v []float64) (Plaintext, error) {
+	return b.Encoder.EncodeVector(v)
+}
+
+func (b *Backend) AddVector(x Ciphertext, v []float64) (Ciphertext, error) {
+	return b.unary("Backend.AddVector", x, func(c *seal.Ciphertext) (*seal.Ciphertext, error) {
+		return b.Evaluator.AddVector(c, v)
+	})
+}
+
+func (b *Backend) MulVector(x Ciphertext, v []float64) (Ciphertext, error) {
+	return b.unary("Backend.MulVector", x, func(c *seal.Ciphertext) (*seal.Ciphertext, error) {
+		return b.Evaluator.MulVector(c, v)
+	})
+}
+
+func (b *Backend) Rotate(x Ciphertext, steps int) (Ciphertext, error) {
+	return b.unary("Backend.Rotate", x, func(c *seal.Ciphertext) (*seal.Ciphertext, error) {
+		return b.Evaluator.Rotate(c, steps)
+	})
+}
+
+func (b *Backend) SaveCiphertext(w io.Writer, x Ciphertext) error {
+	c, ok := x.(*seal.Ciphertext)
+	if !ok {
+		return mismatch("Backend.SaveCiphertext", x)
+	}
+	return c.Save(w)
+}
+
+func (b *Backend) LoadCiphertext(r io.Reader) (Ciphertext, error) {
+	if b.Context == nil {
+		return nil, fmt.Errorf("sealbackend: Backend.LoadCiphertext: no Context to check ciphertexts against")
+	}
+	c := &seal.Ciphertext{}
+	if err := c.Load(b.Context, r); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Decrypter decrypts ciphertexts of Backend, e.g. for EarlyStopping.
+type Decrypter struct {
+	Decryptor *seal.Decryptor
+	Encoder   *seal.CKKSEncoder
+}
+
+func (d *Decrypter) Decrypt(x Ciphertext) (float64, error) {
+	c, ok := x.(*seal.Ciphertext)
+	if !ok {
+		return 0, mismatch("Decrypter.Decrypt", x)
+	}
+	p, err := d.Decryptor.Decrypt(c)
+	if err != nil {
+		return 0, err
+	}
+	return d.Encoder.Decode(p)
+}
+
+func (b *Backend) unary(op string, x Ciphertext, f func(*seal.Ciphertext) (*seal.Ciphertext, error)) (Ciphertext, error) {
+	cx, ok := x.(*seal.Ciphertext)
+	if !ok {
+		return nil, mismatch(op, x)
+	}
+	return f(cx)
+}
+
+func (b *Backend) binary(op string, x, y Ciphertext, f func(a, b *seal.Ciphertext) (*seal.Ciphertext, error)) (Ciphertext, error) {
+	cx, ok := x.(*seal.Ciphertext)
+	if !ok {
+		return nil, mismatch(op, x)
+	}
+	cy, ok := y.(*seal.Ciphertext)
+	if !ok {
+		return nil, mismatch(op, y)
+	}
+	return f(cx, cy)
+}
+
+func mismatch(op string, v interface{}) error {
+	return fmt.Errorf("sealbackend: %s: unexpected %T: %w", op, v, gobrain.ErrBackendMismatch)
+}
@@ -0,0 +1,217 @@
+package sealbackend_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/d4l3k/go-fheml/gobrain"
+	"github.com/d4l3k/go-fheml/gobrain/sealbackend"
+	"github.com/d4l3k/go-fheml/seal"
+	"github.com/d4l3k/go-fheml/sim"
+)
+
+type backend interface {
+	gobrain.Encoder
+	gobrain.Encryptor
+	gobrain.Evaluator
+	gobrain.Packer
+}
+
+// kit is a backend with a way to read back every slot of its ciphertexts,
+// so the seal backend and the simulator can be checked against each other.
+type kit struct {
+	name    string
+	b       backend
+	decrypt func(c gobrain.Ciphertext) ([]float64, error)
+}
+
+// newSEALKit returns a kit on params with Galois keys for the given
+// rotation steps, if any.
+func newSEALKit(t *testing.T, params *seal.EncryptionParams, steps ...int) kit {
+	t.Helper()
+	c, err := seal.NewContext(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := seal.NewKeyGenerator(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, _ := g.PublicKey()
+	sec, _ := g.SecretKey()
+	relin, err := g.RelinKeys(60, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &sealbackend.Backend{Context: c}
+	b.Encoder, _ = seal.NewCKKSEncoder(c)
+	b.Encryptor, _ = seal.NewEncryptor(c, pub)
+	if b.Evaluator, err = seal.NewAutoEvaluator(c, relin); err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) > 0 {
+		if b.Evaluator.GaloisKeys, err = g.GaloisKeys(60, steps...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d, _ := seal.NewDecryptor(c, sec)
+	return kit{"seal", b, func(x gobrain.Ciphertext) ([]float64, error) {
+		p, err := d.Decrypt(x.(*seal.Ciphertext))
+		if err != nil {
+			return nil, err
+		}
+		return b.Encoder.DecodeVector(p)
+	}}
+}
+
+func newSimKit(t *testing.T) kit {
+	t.Helper()
+	c, err := sim.NewContext(sim.DefaultParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, _ := sim.NewKeyGenerator(c)
+	pub, _ := g.PublicKey()
+	sec, _ := g.SecretKey()
+	relin, _ := g.RelinKeys(60, 2)
+	b := &gobrain.Sim{Context: c}
+	b.Encoder, _ = sim.NewCKKSEncoder(c)
+	b.Encryptor, _ = sim.NewEncryptor(c, pub)
+	if b.Evaluator, err = sim.NewAutoEvaluator(c, relin); err != nil {
+		t.Fatal(err)
+	}
+	b.Evaluator.GaloisKeys, _ = g.GaloisKeys(60)
+	d, _ := sim.NewDecryptor(c, sec)
+	return kit{"sim", b, func(x gobrain.Ciphertext) ([]float64, error) {
+		p, err := d.Decrypt(x.(*sim.Ciphertext))
+		if err != nil {
+			return nil, err
+		}
+		return b.Encoder.DecodeVector(p)
+	}}
+}
+
+func TestConstantsFillSlots(t *testing.T) {
+	params, err := seal.NewEncryptionParamsCKKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []kit{newSimKit(t), newSEALKit(t, params)} {
+		p, err := k.b.EncodeVector([]float64{1, 2, 3, 4})
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, err := k.b.Encrypt(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y, err := k.b.AddConst(x, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if y, err = k.b.MulConst(y, -2); err != nil {
+			t.Fatal(err)
+		}
+		if p, err = k.b.Encode(3); err != nil {
+			t.Fatal(err)
+		}
+		z, err := k.b.Encrypt(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tt := range []struct {
+			name string
+			c    gobrain.Ciphertext
+			want float64
+		}{
+			{"Encode", z, 3},
+			{"AddConst, MulConst", y, -6},
+		} {
+			out, err := k.decrypt(tt.c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(out[1]-tt.want) > 1e-3 {
+				t.Errorf("%s: %s slot 1 = %f; want %f", k.name, tt.name, out[1], tt.want)
+			}
+		}
+	}
+}
+
+// TestPackedFeedForward checks packed inference on SEAL against Update. Pack
+// and the activations rely on constants reaching every slot.
+func TestPackedFeedForward(t *testing.T) {
+	params, err := seal.NewEncryptionParams(seal.SchemeCKKS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := params.SetPolyModulusDegree(4096); err != nil {
+		t.Fatal(err)
+	}
+	// Two layers of a product and a sigmoid each, plus the masks of Pack,
+	// with levels to spare. The chain is too long for 128-bit security at
+	// this degree, which keeps the keys small enough for a test.
+	bits := make([]int, 16)
+	for i := range bits {
+		bits[i] = 60
+	}
+	if err := params.SetCoeffModulus(bits); err != nil {
+		t.Fatal(err)
+	}
+	if err := params.SetSecurityLevel(seal.SecurityNone); err != nil {
+		t.Fatal(err)
+	}
+	k := newSEALKit(t, params, -4, -3, -2, -1, 1, 2, 3)
+
+	ff := &gobrain.FeedForward{Encryptor: k.b, Evaluator: k.b, Encoder: k.b}
+	if err := ff.Init(3, 4, 2); err != nil {
+		t.Fatal(err)
+	}
+	pk, err := ff.Pack(k.b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := [][]float64{{0.5, -1, 0.25}, {1, 0, 0}, {-0.5, 0.75, 1}}
+	x, err := pk.Encrypt(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := pk.Update(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slots, err := k.decrypt(y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := pk.Unpack(slots, len(samples))
+
+	for s, sample := range samples {
+		var inputs []gobrain.Ciphertext
+		for _, v := range sample {
+			p, err := k.b.Encode(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, err := k.b.Encrypt(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			inputs = append(inputs, c)
+		}
+		out, err := ff.Update(inputs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, c := range out {
+			want, err := k.decrypt(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got[s][j]-want[0]) > 1e-3 {
+				t.Errorf("sample %d output %d = %g; want %g", s, j, got[s][j], want[0])
+			}
+		}
+	}
+}
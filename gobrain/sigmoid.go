@@ -0,0 +1,20 @@
+package gobrain
+
+import "math"
+
+const (
+	// DefaultSigmoidDegree and DefaultSigmoidBound give a sigmoid within
+	// 0.03 of the logistic function over [-8, 8], at a depth of 3 levels.
+	DefaultSigmoidDegree = 7
+	DefaultSigmoidBound  = 8
+)
+
+// NewSigmoid approximates the logistic function 1/(1+exp(-x)) by a
+// polynomial of the given degree over [-bound, bound].
+func NewSigmoid(degree int, bound float64, fit Fit) (*PolyActivation, error) {
+	return NewPolyActivation(logistic, degree, bound, fit)
+}
+
+func logistic(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
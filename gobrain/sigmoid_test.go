@@ -0,0 +1,84 @@
+package gobrain
+
+import (
+	"math"
+	"testing"
+
+	"github.com/d4l3k/go-fheml/sim"
+)
+
+func TestSigmoidApproximation(t *testing.T) {
+	tests := []struct {
+		degree int
+		fit    Fit
+		bound  float64
+	}{
+		{7, ChebyshevFit, 0.035},
+		{7, LeastSquaresFit, 0.035},
+		{15, ChebyshevFit, 2e-3},
+		{15, LeastSquaresFit, 2e-3},
+	}
+	for _, tt := range tests {
+		s, err := NewSigmoid(tt.degree, 8, tt.fit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		const h = 1e-5
+		for x := -8.0; x <= 8; x += 0.01 {
+			want := 1 / (1 + math.Exp(-x))
+			if got := s.Poly.Evaluate(x); math.Abs(got-want) > tt.bound {
+				t.Errorf("degree %d %v: sigmoid(%g) = %g; want %g within %g", tt.degree, tt.fit, x, got, want, tt.bound)
+				break
+			}
+			slope := (s.Poly.Evaluate(x+h) - s.Poly.Evaluate(x-h)) / (2 * h)
+			if got := s.Deriv.Evaluate(x); math.Abs(got-slope) > 1e-6 {
+				t.Errorf("degree %d %v: dsigmoid(%g) = %g; want %g", tt.degree, tt.fit, x, got, slope)
+				break
+			}
+		}
+	}
+	// Degree 7 is the most that fits in ceil(log2(7+1)) = 3 levels.
+	s, err := NewSigmoid(DefaultSigmoidDegree, DefaultSigmoidBound, ChebyshevFit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := s.Depth(); d != 3 {
+		t.Errorf("default sigmoid: Depth() = %d; want 3", d)
+	}
+	if _, err := NewSigmoid(0, 8, ChebyshevFit); err == nil {
+		t.Error("NewSigmoid(degree 0) succeeded")
+	}
+}
+
+func TestSigmoidEncrypted(t *testing.T) {
+	b, d := newSimBackend(t)
+	s, err := NewSigmoid(DefaultSigmoidDegree, DefaultSigmoidBound, ChebyshevFit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range []float64{-7.5, -2, 0, 0.5, 3, 8} {
+		p, err := b.Encode(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := b.Encrypt(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := b.EvaluatePolynomial(c, s.Poly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		plain, err := d.Decrypt(r.(*sim.Ciphertext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := b.Encoder.Decode(plain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := 1 / (1 + math.Exp(-x)); math.Abs(got-want) > 0.035 {
+			t.Errorf("sigmoid(%g) = %g; want %g within 0.035", x, got, want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package gobrain
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/d4l3k/go-fheml/sim"
+)
+
+// Sim runs networks on the cleartext simulator in package sim, which is
+// useful for debugging. It implements Encoder, Encryptor, Evaluator, Packer
+// and Serializer; its ciphertexts are *sim.Ciphertext.
+type Sim struct {
+	Encoder   *sim.CKKSEncoder
+	Encryptor *sim.Encryptor
+	Evaluator *sim.AutoEvaluator
+	// Context checks loaded ciphertexts; only LoadCiphertext needs it.
+	Context *sim.Context
+}
+
+func (b *Sim) Encode(v float64) (Plaintext, error) {
+	return b.Encoder.Encode(v)
+}
+
+func (b *Sim) Encrypt(p Plaintext) (Ciphertext, error) {
+	plain, ok := p.(*sim.Plaintext)
+	if !ok {
+		return nil, mismatch("Sim.Encrypt", p)
+	}
+	return b.Encryptor.Encrypt(plain)
+}
+
+func (b *Sim) Add(x, y Ciphertext) (Ciphertext, error) {
+	return b.binary("Sim.Add", x, y, b.Evaluator.Add)
+}
+
+func (b *Sim) Sub(x, y Ciphertext) (Ciphertext, error) {
+	return b.binary("Sim.Sub", x, y, b.Evaluator.Sub)
+}
+
+func (b *Sim) Mul(x, y Ciphertext) (Ciphertext, error) {
+	return b.binary("Sim.Mul", x, y, b.Evaluator.Mul)
+}
+
+func (b *Sim) Square(x Ciphertext) (Ciphertext, error) {
+	return b.unary("Sim.Square", x, b.Evaluator.Square)
+}
+
+func (b *Sim) Negate(x Ciphertext) (Ciphertext, error) {
+	return b.unary("Sim.Negate", x, b.Evaluator.Negate)
+}
+
+func (b *Sim) AddConst(x Ciphertext, v float64) (Ciphertext, error) {
+	return b.unary("Sim.AddConst", x, func(c *sim.Ciphertext) (*sim.Ciphertext, error) {
+		return b.Evaluator.AddConst(c, v)
+	})
+}
+
+func (b *Sim) MulConst(x Ciphertext, v float64) (Ciphertext, error) {
+	return b.unary("Sim.MulConst", x, func(c *sim.Ciphertext) (*sim.Ciphertext, error) {
+		return b.Evaluator.MulConst(c, v)
+	})
+}
+
+func (b *Sim) EvaluatePolynomial(x Ciphertext, p *Polynomial) (Ciphertext, error) {
+	return b.unary("Sim.EvaluatePolynomial", x, func(c *sim.Ciphertext) (*sim.Ciphertext, error) {
+		return b.Evaluator.EvaluatePolynomial(c, p)
+	})
+}
+
+func (b *Sim) Level(x Ciphertext) (int, error) {
+	c, ok := x.(*sim.Ciphertext)
+	if !ok {
+		return 0, mismatch("Sim.Level", x)
+	}
+	return b.Evaluator.Evaluator.Level(c)
+}
+
+func (b *Sim) Slots() int {
+	return b.Encoder.SlotCount()
+}
+
+func (b *Sim) EncodeVector(v []float64) (Plaintext, error) {
+	return b.Encoder.EncodeVector(v)
+}
+
+func (b *Sim) AddVector(x Ciphertext, v []float64) (Ciphertext, error) {
+	return b.unary("Sim.AddVector", x, func(c *sim.Ciphertext) (*sim.Ciphertext, error) {
+		return b.Evaluator.AddVector(c, v)
+	})
+}
+
+func (b *Sim) MulVector(x Ciphertext, v []float64) (Ciphertext, error) {
+	return b.unary("Sim.MulVector", x, func(c *sim.Ciphertext) (*sim.Ciphertext, error) {
+		return b.Evaluator.MulVector(c, v)
+	})
+}
+
+func (b *Sim) Rotate(x Ciphertext, steps int) (Ciphertext, error) {
+	return b.unary("Sim.Rotate", x, func(c *sim.Ciphertext) (*sim.Ciphertext, error) {
+		return b.Evaluator.Rotate(c, steps)
+	})
+}
+
+func (b *Sim) SaveCiphertext(w io.Writer, x Ciphertext) error {
+	c, ok := x.(*sim.Ciphertext)
+	if !ok {
+		return mismatch("Sim.SaveCiphertext", x)
+	}
+	return c.Save(w)
+}
+
+func (b *Sim) LoadCiphertext(r io.Reader) (Ciphertext, error) {
+	if b.Context == nil {
+		return nil, fmt.Errorf("gobrain: Sim.LoadCiphertext: no Context to check ciphertexts against")
+	}
+	c := &sim.Ciphertext{}
+	if err := c.Load(b.Context, r); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SimDecrypter decrypts ciphertexts of Sim, e.g. for EarlyStopping.
+type SimDecrypter struct {
+	Decryptor *sim.Decryptor
+	Encoder   *sim.CKKSEncoder
+}
+
+func (d *SimDecrypter) Decrypt(x Ciphertext) (float64, error) {
+	c, ok := x.(*sim.Ciphertext)
+	if !ok {
+		return 0, mismatch("SimDecrypter.Decrypt", x)
+	}
+	p, err := d.Decryptor.Decrypt(c)
+	if err != nil {
+		return 0, err
+	}
+	return d.Encoder.Decode(p)
+}
+
+func (b *Sim) unary(op string, x Ciphertext, f func(*sim.Ciphertext) (*sim.Ciphertext, error)) (Ciphertext, error) {
+	cx, ok := x.(*sim.Ciphertext)
+	if !ok {
+		return nil, mismatch(op, x)
+	}
+	return f(cx)
+}
+
+func (b *Sim) binary(op string, x, y Ciphertext, f func(a, b *sim.Ciphertext) (*sim.Ciphertext, error)) (Ciphertext, error) {
+	cx, ok := x.(*sim.Ciphertext)
+	if !ok {
+		return nil, mismatch(op, x)
+	}
+	cy, ok := y.(*sim.Ciphertext)
+	if !ok {
+		return nil, mismatch(op, y)
+	}
+	return f(cx, cy)
+}
@@ -0,0 +1,97 @@
+package gobrain
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/d4l3k/go-fheml/sim"
+)
+
+func newSimBackend(t *testing.T) (*Sim, *sim.Decryptor) {
+	t.Helper()
+	// A deep chain of 60-bit primes keeps the scale at 2^60 through the
+	// whole of Train, which the default parameters are too shallow for.
+	bits := make([]int, 64)
+	for i := range bits {
+		bits[i] = 60
+	}
+	return newSimBackendParams(t, sim.Params{PolyModulusDegree: 64, CoeffModulusBits: bits, NoiseStdDev: 3.19})
+}
+
+func newSimBackendParams(t *testing.T, params sim.Params) (*Sim, *sim.Decryptor) {
+	t.Helper()
+	c, err := sim.NewContext(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, _ := sim.NewKeyGenerator(c)
+	pub, _ := g.PublicKey()
+	sec, _ := g.SecretKey()
+	relin, _ := g.RelinKeys(60, 2)
+	b := &Sim{Context: c}
+	b.Encoder, _ = sim.NewCKKSEncoder(c)
+	b.Encryptor, _ = sim.NewEncryptor(c, pub)
+	if b.Evaluator, err = sim.NewAutoEvaluator(c, relin); err != nil {
+		t.Fatal(err)
+	}
+	b.Evaluator.GaloisKeys, _ = g.GaloisKeys(60)
+	d, _ := sim.NewDecryptor(c, sec)
+	return b, d
+}
+
+func TestFeedForwardSim(t *testing.T) {
+	b, d := newSimBackend(t)
+	e := func(v float64) Ciphertext {
+		p, err := b.Encode(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := b.Encrypt(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	decrypt := func(c Ciphertext) float64 {
+		p, err := d.Decrypt(c.(*sim.Ciphertext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := b.Encoder.Decode(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	ff := &FeedForward{Encryptor: b, Evaluator: b, Encoder: b}
+	if err := ff.Init(2, 2, 1); err != nil {
+		t.Fatal(err)
+	}
+	patterns := [][][]Ciphertext{
+		{{e(0), e(1)}, {e(1)}},
+		{{e(1), e(1)}, {e(0)}},
+	}
+	res, err := ff.Train(patterns, TrainOptions{Epochs: 1, LearningRate: 0.6, Momentum: 0.4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := decrypt(res.Losses[0]); v < 0 || math.IsNaN(v) {
+		t.Errorf("training error = %f; want a non-negative number", v)
+	}
+	out, err := ff.Update([]Ciphertext{e(1), e(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || out[0].(*sim.Ciphertext).Overflowed() {
+		t.Errorf("Update() = %v; want one valid output", out)
+	}
+}
+
+func TestBackendMismatch(t *testing.T) {
+	b, _ := newSimBackend(t)
+	if _, err := b.Add("not a ciphertext", nil); !errors.Is(err, ErrBackendMismatch) {
+		t.Errorf("Add(foreign value) = %v; want ErrBackendMismatch", err)
+	}
+}
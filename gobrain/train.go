@@ -0,0 +1,160 @@
+package gobrain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrStopTraining is returned by a training callback to end training early
+// without failing it.
+var ErrStopTraining = errors.New("gobrain: stop training")
+
+// TrainOptions configures FeedForward.Train and Network.Train. Every
+// training step consumes levels, so Epochs times the number of patterns is
+// bounded by the modulus chain; see Network.CheckDepth.
+type TrainOptions struct {
+	// Number of passes over the patterns
+	Epochs int
+	// Factors of the current and the previous weight changes
+	LearningRate, Momentum float64
+	// OnPattern is called after each pattern with its encrypted loss and
+	// OnEpoch after each epoch with the summed loss. Either may return
+	// ErrStopTraining to end training, or another error to abort it.
+	OnPattern func(epoch, pattern int, loss Ciphertext) error
+	OnEpoch   func(epoch int, loss Ciphertext) error
+	// EarlyStopping, if set, decrypts the epoch losses and ends training
+	// once they stop improving.
+	EarlyStopping *EarlyStopping
+}
+
+// EarlyStopping ends training after Patience epochs, at least one, whose
+// loss is not below the best loss so far by more than MinDelta.
+type EarlyStopping struct {
+	Decrypter Decrypter
+	Patience  int
+	MinDelta  float64
+}
+
+// TrainResult reports a training run.
+type TrainResult struct {
+	// Encrypted summed loss of each completed epoch
+	Losses []Ciphertext
+	// Decrypted losses, if EarlyStopping was set
+	Values []float64
+	// Whether a callback or EarlyStopping ended training
+	Stopped bool
+}
+
+// stop ends training on a callback error, which fails it unless it is
+// ErrStopTraining.
+func (r *TrainResult) stop(err error) (*TrainResult, error) {
+	if !errors.Is(err, ErrStopTraining) {
+		return nil, err
+	}
+	r.Stopped = true
+	return r, nil
+}
+
+/*
+Train runs BackPropagate over every pattern, each a pair of inputs and
+targets, for opts.Epochs epochs. A callback stopping training in the middle
+of an epoch leaves that epoch out of the result.
+*/
+func (nn *FeedForward) Train(patterns [][][]Ciphertext, opts TrainOptions) (*TrainResult, error) {
+	zero, err := nn.encrypt(0)
+	if err != nil {
+		return nil, err
+	}
+	step := func(p [][]Ciphertext) (Ciphertext, error) {
+		if _, err := nn.Update(p[0]); err != nil {
+			return nil, err
+		}
+		return nn.BackPropagate(p[1], opts.LearningRate, opts.Momentum)
+	}
+	done := func(epoch int, sum Ciphertext) {
+		nn.trace("epoch", sum, "epoch", epoch, "patterns", len(patterns))
+	}
+	return train(nn.Evaluator, zero, patterns, opts, step, done)
+}
+
+// train runs step, which trains on a pattern and returns its loss, over
+// every pattern for opts.Epochs epochs. Epoch losses are summed onto zero
+// and passed to done, if it is not nil, before the callbacks see them.
+func train(e Evaluator, zero Ciphertext, patterns [][][]Ciphertext, opts TrainOptions,
+	step func(p [][]Ciphertext) (Ciphertext, error), done func(epoch int, sum Ciphertext)) (*TrainResult, error) {
+	es := opts.EarlyStopping
+	if es != nil && es.Decrypter == nil {
+		return nil, fmt.Errorf("gobrain: EarlyStopping needs a Decrypter")
+	}
+	r := &TrainResult{}
+	best, wait := math.Inf(1), 0
+
+	for epoch := 0; epoch < opts.Epochs; epoch++ {
+		sum := zero
+		for i, p := range patterns {
+			loss, err := step(p)
+			if err != nil {
+				return nil, err
+			}
+			if sum, err = e.Add(sum, loss); err != nil {
+				return nil, err
+			}
+			if opts.OnPattern != nil {
+				if err := opts.OnPattern(epoch, i, loss); err != nil {
+					return r.stop(err)
+				}
+			}
+		}
+
+		if done != nil {
+			done(epoch, sum)
+		}
+		r.Losses = append(r.Losses, sum)
+		if opts.OnEpoch != nil {
+			if err := opts.OnEpoch(epoch, sum); err != nil {
+				return r.stop(err)
+			}
+		}
+
+		if es == nil {
+			continue
+		}
+		v, err := es.Decrypter.Decrypt(sum)
+		if err != nil {
+			return nil, err
+		}
+		r.Values = append(r.Values, v)
+		if v < best-es.MinDelta {
+			best, wait = v, 0
+		} else if wait++; wait >= maxInt(es.Patience, 1) {
+			r.Stopped = true
+			break
+		}
+	}
+	return r, nil
+}
+
+// Prediction holds the outputs of the network for a pattern and the
+// pattern's targets.
+type Prediction struct {
+	Outputs, Targets []Ciphertext
+}
+
+// Test activates the network on each pattern and returns its predictions.
+func (nn *FeedForward) Test(patterns [][][]Ciphertext) ([]Prediction, error) {
+	predictions := make([]Prediction, len(patterns))
+	for i, p := range patterns {
+		out, err := nn.Update(p[0])
+		if err != nil {
+			return nil, err
+		}
+		nn.trace("test", out[0], "pattern", i, "outputs", out, "targets", p[1])
+		// Update reuses its output slice.
+		predictions[i] = Prediction{
+			Outputs: append([]Ciphertext(nil), out...),
+			Targets: p[1],
+		}
+	}
+	return predictions, nil
+}
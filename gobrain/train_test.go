@@ -0,0 +1,106 @@
+package gobrain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// scriptedLosses decrypts any ciphertext to the next of its values.
+type scriptedLosses []float64
+
+func (s *scriptedLosses) Decrypt(c Ciphertext) (float64, error) {
+	v := (*s)[0]
+	*s = (*s)[1:]
+	return v, nil
+}
+
+// newTrainNetwork returns a network on a depthTracker, which never runs out
+// of levels, and two patterns for it.
+func newTrainNetwork(t *testing.T) (*FeedForward, [][][]Ciphertext) {
+	t.Helper()
+	b := &depthTracker{}
+	nn := &FeedForward{Encryptor: b, Evaluator: b, Encoder: b}
+	if err := nn.Init(2, 2, 1); err != nil {
+		t.Fatal(err)
+	}
+	patterns := [][][]Ciphertext{
+		{{0, 0}, {0}},
+		{{0, 0}, {0}},
+	}
+	return nn, patterns
+}
+
+func TestTrainCallbacks(t *testing.T) {
+	nn, patterns := newTrainNetwork(t)
+	var calls []int
+	res, err := nn.Train(patterns, TrainOptions{
+		Epochs: 5,
+		OnPattern: func(epoch, pattern int, loss Ciphertext) error {
+			calls = append(calls, epoch*10+pattern)
+			return nil
+		},
+		OnEpoch: func(epoch int, loss Ciphertext) error {
+			if epoch == 2 {
+				return ErrStopTraining
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Stopped || len(res.Losses) != 3 || res.Values != nil {
+		t.Errorf("Train() = %+v; want 3 losses and Stopped", res)
+	}
+	if want := []int{0, 1, 10, 11, 20, 21}; fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("OnPattern calls = %v; want %v", calls, want)
+	}
+
+	fail := errors.New("fail")
+	_, err = nn.Train(patterns, TrainOptions{
+		Epochs:    2,
+		OnPattern: func(epoch, pattern int, loss Ciphertext) error { return fail },
+	})
+	if !errors.Is(err, fail) {
+		t.Errorf("Train(failing OnPattern) = %v; want %v", err, fail)
+	}
+}
+
+func TestEarlyStopping(t *testing.T) {
+	nn, patterns := newTrainNetwork(t)
+	losses := scriptedLosses{4, 3, 2.95, 2.99, 1, 0.5}
+	res, err := nn.Train(patterns, TrainOptions{
+		Epochs:        6,
+		EarlyStopping: &EarlyStopping{Decrypter: &losses, Patience: 2, MinDelta: 0.1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Stopped || len(res.Losses) != 4 || len(res.Values) != 4 || res.Values[3] != 2.99 {
+		t.Errorf("Train() = %+v; want to stop after 4 epochs", res)
+	}
+
+	if _, err := nn.Train(patterns, TrainOptions{Epochs: 1, EarlyStopping: &EarlyStopping{}}); err == nil {
+		t.Error("Train(EarlyStopping without Decrypter) succeeded")
+	}
+}
+
+func TestTestPredictions(t *testing.T) {
+	nn, patterns := newTrainNetwork(t)
+	predictions, err := nn.Test(patterns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(predictions) != 2 {
+		t.Fatalf("Test() returned %d predictions; want 2", len(predictions))
+	}
+	for i, p := range predictions {
+		if len(p.Outputs) != 1 || len(p.Targets) != 1 {
+			t.Errorf("prediction %d = %+v; want one output and target", i, p)
+		}
+	}
+	if &predictions[0].Outputs[0] == &predictions[1].Outputs[0] {
+		t.Error("predictions share their outputs")
+	}
+}
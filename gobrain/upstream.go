@@ -0,0 +1,101 @@
+package gobrain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// upstream is a FeedForward of the upstream goml/gobrain package, which
+// this package forked, as encoding/json writes it. Its sizes include the
+// bias nodes and its input weights have an unused hidden bias column.
+type upstream struct {
+	NInputs, NHiddens, NOutputs int
+	Regression                  bool
+	Contexts                    [][]float64
+	InputWeights, OutputWeights [][]float64
+}
+
+func readUpstream(r io.Reader) (*upstream, error) {
+	u := &upstream{}
+	if err := json.NewDecoder(r).Decode(u); err != nil {
+		return nil, fmt.Errorf("gobrain: decoding upstream network: %w", err)
+	}
+	if u.NInputs < 2 || u.NHiddens < 2 || u.NOutputs < 1 {
+		return nil, fmt.Errorf("gobrain: upstream network has %d inputs, %d hidden nodes and %d outputs",
+			u.NInputs, u.NHiddens, u.NOutputs)
+	}
+	if len(u.InputWeights) != u.NInputs || len(u.OutputWeights) != u.NHiddens {
+		return nil, fmt.Errorf("gobrain: upstream network has %d and %d weight rows for %d inputs and %d hidden nodes",
+			len(u.InputWeights), len(u.OutputWeights), u.NInputs, u.NHiddens)
+	}
+	for i, row := range u.InputWeights {
+		if len(row) != u.NHiddens {
+			return nil, fmt.Errorf("gobrain: upstream input weight row %d has %d columns for %d hidden nodes", i, len(row), u.NHiddens)
+		}
+	}
+	for i, row := range u.OutputWeights {
+		if len(row) != u.NOutputs {
+			return nil, fmt.Errorf("gobrain: upstream output weight row %d has %d columns for %d outputs", i, len(row), u.NOutputs)
+		}
+	}
+	for _, c := range u.Contexts {
+		if len(c) != u.NHiddens {
+			return nil, fmt.Errorf("gobrain: upstream context has %d values for %d hidden nodes", len(c), u.NHiddens)
+		}
+	}
+	return u, nil
+}
+
+// LoadJSON initializes the network with the weights and contexts of a
+// network the upstream goml/gobrain package saved with encoding/json,
+// encrypting them. Its Regression flag is adopted; the activations are
+// this package's approximations of upstream's.
+func (nn *FeedForward) LoadJSON(r io.Reader) error {
+	u, err := readUpstream(r)
+	if err != nil {
+		return err
+	}
+	nn.Regression = u.Regression
+	if err := nn.InitWeights(u.InputWeights, u.OutputWeights); err != nil {
+		return err
+	}
+	nn.Contexts = nil
+	for _, c := range u.Contexts {
+		context := make([]Ciphertext, len(c))
+		for i, v := range c {
+			if context[i], err = nn.encrypt(v); err != nil {
+				return err
+			}
+		}
+		nn.Contexts = append(nn.Contexts, context)
+	}
+	return nil
+}
+
+// LoadJSON sets the weights of the network to those of a network the
+// upstream goml/gobrain package saved with encoding/json. Upstream networks
+// with contexts are refused.
+func (nn *PlainFeedForward) LoadJSON(r io.Reader) error {
+	u, err := readUpstream(r)
+	if err != nil {
+		return err
+	}
+	if len(u.Contexts) > 0 {
+		return fmt.Errorf("gobrain: PlainFeedForward does not support contexts")
+	}
+	hiddens := u.NHiddens - 1
+	inputWeights := make([][]float64, len(u.InputWeights))
+	for i, row := range u.InputWeights {
+		inputWeights[i] = row[:hiddens]
+	}
+	loaded := *nn
+	loaded.Regression = u.Regression
+	loaded.InputWeights = inputWeights
+	loaded.OutputWeights = u.OutputWeights
+	if _, _, _, err := loaded.check(); err != nil {
+		return err
+	}
+	*nn = loaded
+	return nil
+}
@@ -0,0 +1,102 @@
+package gobrain
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/d4l3k/go-fheml/sim"
+)
+
+// upstreamXOR is a network saved by the upstream package, with the unused
+// hidden bias column of its input weights set to 9.
+const upstreamXOR = `{
+	"NInputs": 3, "NHiddens": 3, "NOutputs": 1, "Regression": false,
+	"InputActivations": [1, 1, 1], "HiddenActivations": [1, 1, 1], "OutputActivations": [1],
+	"Contexts": null,
+	"InputWeights": [[1.5, -2, 9], [-1, 2.5, 9], [0.25, -0.5, 9]],
+	"OutputWeights": [[2], [-1.5], [0.5]],
+	"InputChanges": [[0, 0, 0], [0, 0, 0], [0, 0, 0]],
+	"OutputChanges": [[0], [0], [0]]
+}`
+
+func TestLoadJSON(t *testing.T) {
+	b, d := newSimBackend(t)
+	value := func(c Ciphertext) float64 {
+		p, err := d.Decrypt(c.(*sim.Ciphertext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := b.Encoder.Decode(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	in := []float64{1, 0}
+	inputs := make([]Ciphertext, len(in))
+	for i, v := range in {
+		var err error
+		if inputs[i], err = encrypt(b, b, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ff := &FeedForward{Encryptor: b, Evaluator: b, Encoder: b}
+	if err := ff.LoadJSON(strings.NewReader(upstreamXOR)); err != nil {
+		t.Fatal(err)
+	}
+	sigmoid := ff.HiddenFunc.(*PolyActivation).Poly
+	inputWeights := [][]float64{{1.5, -2}, {-1, 2.5}, {0.25, -0.5}}
+	outputWeights := []float64{2, -1.5, 0.5}
+	want := outputWeights[2]
+	for j := 0; j < 2; j++ {
+		sum := inputWeights[2][j]
+		for i, v := range in {
+			sum += v * inputWeights[i][j]
+		}
+		want += sigmoid.Evaluate(sum) * outputWeights[j]
+	}
+	want = sigmoid.Evaluate(want)
+
+	out, err := ff.Update(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("Update() returned %d outputs; want 1", len(out))
+	}
+	if got := value(out[0]); math.Abs(got-want) > 1e-6 {
+		t.Errorf("FeedForward output = %g; want %g", got, want)
+	}
+
+	plain := &PlainFeedForward{Encryptor: b, Evaluator: b}
+	if err := plain.LoadJSON(strings.NewReader(upstreamXOR)); err != nil {
+		t.Fatal(err)
+	}
+	if out, err = plain.Update(inputs); err != nil {
+		t.Fatal(err)
+	}
+	if got := value(out[0]); math.Abs(got-want) > 1e-6 {
+		t.Errorf("PlainFeedForward output = %g; want %g", got, want)
+	}
+
+	bad := strings.Replace(upstreamXOR, `"NOutputs": 1`, `"NOutputs": 2`, 1)
+	if err := ff.LoadJSON(strings.NewReader(bad)); err == nil {
+		t.Error("LoadJSON(inconsistent sizes) succeeded")
+	}
+}
+
+func TestInitWeights(t *testing.T) {
+	b, _ := newSimBackend(t)
+	ff := &FeedForward{Encryptor: b, Evaluator: b, Encoder: b}
+	if err := ff.InitWeights([][]float64{{1, 2}, {3, 4}}, [][]float64{{1}, {2}, {3}}); err != nil {
+		t.Fatal(err)
+	}
+	if ff.NInputs != 2 || ff.NHiddens != 3 || ff.NOutputs != 1 {
+		t.Errorf("sizes %d, %d, %d; want 2, 3, 1", ff.NInputs, ff.NHiddens, ff.NOutputs)
+	}
+	if err := ff.InitWeights([][]float64{{1, 2}, {3}}, [][]float64{{1}, {2}, {3}}); err == nil {
+		t.Error("InitWeights(ragged input weights) succeeded")
+	}
+}
@@ -1,54 +1,57 @@
 package gobrain
 
-import (
-	"log"
-	"math"
-	"math/rand"
-
-	"github.com/d4l3k/go-fheml/seal"
-)
+func (nn *FeedForward) encrypt(v float64) (Ciphertext, error) {
+	return encrypt(nn.Encoder, nn.Encryptor, v)
+}
 
-func (nn *FeedForward) random(a, b float64) *seal.Ciphertext {
-	return nn.Encryptor.Encrypt(nn.Encoder.Encode((b-a)*rand.Float64() + a))
+func encrypt(enc Encoder, e Encryptor, v float64) (Ciphertext, error) {
+	p, err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return e.Encrypt(p)
 }
 
-func (nn *FeedForward) matrix(I, J int) [][]*seal.Ciphertext {
-	c := nn.Encryptor.Encrypt(nn.Encoder.Encode(0))
-	m := make([][]*seal.Ciphertext, I)
+func (nn *FeedForward) matrix(I, J int) ([][]Ciphertext, error) {
+	c, err := nn.encrypt(0)
+	if err != nil {
+		return nil, err
+	}
+	// Evaluators never modify their arguments, so cells can share c.
+	m := make([][]Ciphertext, I)
 	for i := 0; i < I; i++ {
-		m[i] = make([]*seal.Ciphertext, J)
+		m[i] = make([]Ciphertext, J)
 		for j := 0; j < J; j++ {
-			m[i][j] = c.Copy()
+			m[i][j] = c
 		}
 	}
-	return m
+	return m, nil
 }
 
-func (nn *FeedForward) vector(I int, fill float64) []*seal.Ciphertext {
-	c := nn.Encryptor.Encrypt(nn.Encoder.Encode(fill))
-	v := make([]*seal.Ciphertext, I)
+func (nn *FeedForward) vector(I int, fill float64) ([]Ciphertext, error) {
+	c, err := nn.encrypt(fill)
+	if err != nil {
+		return nil, err
+	}
+	v := make([]Ciphertext, I)
 	for i := 0; i < I; i++ {
-		v[i] = c.Copy()
+		v[i] = c
 	}
-	return v
+	return v, nil
 }
 
-func (nn *FeedForward) sigmoid(x *seal.Ciphertext) *seal.Ciphertext {
-	x = x.Copy()
-	nn.Evaluator.RelinearizeInplace(x, nn.RelinKeys)
-	for x.Scale() > math.Pow(2, 64) {
-		nn.Evaluator.RescaleToNextInplace(x)
+// newMatrix returns an I by J matrix with cells from fill.
+func newMatrix(I, J int, fill func() (Ciphertext, error)) ([][]Ciphertext, error) {
+	m := make([][]Ciphertext, I)
+	for i := 0; i < I; i++ {
+		m[i] = make([]Ciphertext, J)
+		for j := 0; j < J; j++ {
+			c, err := fill()
+			if err != nil {
+				return nil, err
+			}
+			m[i][j] = c
+		}
 	}
-	log.Println(x.Scale())
-	nn.Evaluator.SquareInplace(x)
-	nn.Evaluator.RelinearizeInplace(x, nn.RelinKeys)
-	return x
-	//return 1 / (1 + math.Exp(-x))
-}
-
-func (nn *FeedForward) dsigmoid(y *seal.Ciphertext) *seal.Ciphertext {
-	c := nn.Encryptor.Encrypt(nn.Encoder.EncodeParmsIDScale(1, y.ParmsID(), y.Scale()))
-	nn.Evaluator.SubInplace(c, y)
-	nn.Evaluator.MultiplyInplace(c, y)
-	return c
+	return m, nil
 }
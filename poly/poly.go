@@ -0,0 +1,425 @@
+/*
+Package poly evaluates real polynomials on encrypted values. The evaluation
+plan is independent of the encryption backend, which supplies an Evaluator
+for its ciphertexts.
+*/
+package poly
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// Basis selects the polynomials a Polynomial's coefficients refer to.
+type Basis int
+
+const (
+	// Monomial is the basis 1, x, x^2, ...
+	Monomial Basis = iota
+	// Chebyshev is the basis of Chebyshev polynomials of the first kind,
+	// T_0, T_1, T_2, ...
+	Chebyshev
+)
+
+func (b Basis) String() string {
+	switch b {
+	case Monomial:
+		return "monomial"
+	case Chebyshev:
+		return "Chebyshev"
+	}
+	return fmt.Sprintf("Basis(%d)", int(b))
+}
+
+// Polynomial is the real polynomial sum(Coeffs[i] * P_i(x)) in Basis.
+// Chebyshev polynomials are taken over the interval [A, B], which is mapped
+// onto [-1, 1] before evaluation; a zero interval stands for [-1, 1]. A and
+// B are ignored in the monomial basis.
+type Polynomial struct {
+	Basis  Basis
+	Coeffs []float64
+	A, B   float64
+}
+
+// Degree returns the degree of p, ignoring trailing zero coefficients. The
+// zero polynomial has degree 0.
+func (p *Polynomial) Degree() int {
+	d := len(p.Coeffs) - 1
+	for d > 0 && p.Coeffs[d] == 0 {
+		d--
+	}
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// Evaluate evaluates p on a cleartext value, for reference.
+func (p *Polynomial) Evaluate(x float64) float64 {
+	if len(p.Coeffs) == 0 {
+		return 0
+	}
+	c := p.Coeffs[:p.Degree()+1]
+	if p.Basis == Monomial {
+		var r float64
+		for i := len(c) - 1; i >= 0; i-- {
+			r = r*x + c[i]
+		}
+		return r
+	}
+	// Clenshaw's recurrence.
+	scale, shift := p.interval()
+	x = x*scale + shift
+	var b1, b2 float64
+	for i := len(c) - 1; i > 0; i-- {
+		b1, b2 = 2*x*b1-b2+c[i], b1
+	}
+	return x*b1 - b2 + c[0]
+}
+
+// Derivative returns the derivative of p, in the same basis and over the
+// same interval.
+func (p *Polynomial) Derivative() *Polynomial {
+	n := len(p.Coeffs) - 1
+	if n < 1 {
+		return &Polynomial{Basis: p.Basis, Coeffs: []float64{0}, A: p.A, B: p.B}
+	}
+	if p.Basis == Monomial {
+		d := make([]float64, n)
+		for k := range d {
+			d[k] = float64(k+1) * p.Coeffs[k+1]
+		}
+		return &Polynomial{Basis: p.Basis, Coeffs: d, A: p.A, B: p.B}
+	}
+	// d[k-1] = d[k+1] + 2k*c[k], with d[0] halved; d has room for d[n+1].
+	d := make([]float64, n+2)
+	for k := n; k > 0; k-- {
+		d[k-1] = d[k+1] + 2*float64(k)*p.Coeffs[k]
+	}
+	d[0] /= 2
+	// Chain rule for the map from [A, B] onto [-1, 1].
+	scale, _ := p.interval()
+	for k := range d {
+		d[k] *= scale
+	}
+	return &Polynomial{Basis: p.Basis, Coeffs: d[:n], A: p.A, B: p.B}
+}
+
+// Depth returns the number of levels Evaluate consumes on p, or 0 if p is
+// not valid.
+func (p *Polynomial) Depth() int {
+	if p.Check() != nil {
+		return 0
+	}
+	plan := newPolyPlan(p)
+	return plan.depth(plan.coeffs, plan.m)
+}
+
+// Check reports whether p can be evaluated: it needs coefficients, a known
+// basis and, for Chebyshev polynomials, a non-empty interval.
+func (p *Polynomial) Check() error {
+	if len(p.Coeffs) == 0 {
+		return fmt.Errorf("polynomial has no coefficients")
+	}
+	if p.Basis != Monomial && p.Basis != Chebyshev {
+		return fmt.Errorf("unknown basis %v", p.Basis)
+	}
+	if p.Basis == Chebyshev && (p.A != 0 || p.B != 0) && p.A >= p.B {
+		return fmt.Errorf("empty interval [%g, %g]", p.A, p.B)
+	}
+	return nil
+}
+
+// interval returns the affine map from [A, B] onto [-1, 1].
+func (p *Polynomial) interval() (scale, shift float64) {
+	if p.Basis == Monomial || (p.A == 0 && p.B == 0) {
+		return 1, 0
+	}
+	return 2 / (p.B - p.A), -(p.A + p.B) / (p.B - p.A)
+}
+
+// polyPlan splits a polynomial of degree below k*2^m into baby steps of
+// degree below k, combined by m levels of giant steps P_{k*2^j}. Evaluated
+// this way a polynomial of degree d needs ceil(log2(d+1)) levels, counting
+// the scalar coefficients as one more factor of each term.
+//
+// A Chebyshev polynomial over [A, B] is planned in the scaled basis
+// S_i(y) = h^i T_i(y/h) of y = x - (A+B)/2, with h = (B-A)/2, so the map
+// onto [-1, 1] is an addition and its scaling is folded into the
+// coefficients. The S_i follow T_i's recurrence with the constant terms
+// multiplied by powers of w = h^2.
+type polyPlan struct {
+	basis  Basis
+	coeffs []float64
+	k, m   int
+	shift  float64
+	w      float64
+}
+
+func newPolyPlan(p *Polynomial) *polyPlan {
+	c := append([]float64(nil), p.Coeffs[:p.Degree()+1]...)
+	l := bits.Len(uint(len(c) - 1))
+	m := l / 2
+	pl := &polyPlan{basis: p.Basis, coeffs: c, k: 1 << uint(l-m), m: m, w: 1}
+	if scale, shift := p.interval(); scale != 1 || shift != 0 {
+		for i := range c {
+			c[i] *= math.Pow(scale, float64(i))
+		}
+		pl.shift = shift / scale
+		pl.w = 1 / (scale * scale)
+	}
+	return pl
+}
+
+// divide splits c into q and r with c = q*P_n + r, where c has fewer than
+// 2n coefficients.
+func (pl *polyPlan) divide(c []float64, n int) (q, r []float64) {
+	q = append([]float64(nil), c[n:]...)
+	r = append([]float64(nil), c[:n]...)
+	if pl.basis == Chebyshev {
+		// S_{n+j} = 2*S_n*S_j - w^j*S_{n-j}.
+		for j := 1; j < len(q); j++ {
+			q[j] *= 2
+			r[n-j] -= c[n+j] * math.Pow(pl.w, float64(j))
+		}
+	}
+	return q, r
+}
+
+// powerDepth is the depth of P_i computed by polyEval.power.
+func powerDepth(i int) int {
+	return bits.Len(uint(i - 1))
+}
+
+// termDepth is the depth of v*P_i computed by polyEval.term.
+func termDepth(i int) int {
+	return bits.Len(uint(i))
+}
+
+// depth mirrors polyEval.eval.
+func (pl *polyPlan) depth(c []float64, m int) int {
+	if m == 0 {
+		top := len(c) - 1
+		for top > 1 && c[top] == 0 {
+			top--
+		}
+		if top < 1 {
+			top = 1
+		}
+		return termDepth(top)
+	}
+	n := pl.k << uint(m-1)
+	if len(c) <= n {
+		return pl.depth(c, m-1)
+	}
+	q, r := pl.divide(c, n)
+	d := powerDepth(n)
+	if len(q) > 1 {
+		d = maxInt(d, pl.depth(q, m-1))
+	}
+	return maxInt(d+1, pl.depth(r, m-1))
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Ciphertext is an encrypted value of the backend an Evaluator belongs to.
+type Ciphertext interface{}
+
+// Evaluator is the arithmetic Evaluate needs from a backend. Mul and Square
+// return finished products, relinearized and rescaled, and every method
+// leaves its arguments unchanged.
+type Evaluator interface {
+	Mul(x, y Ciphertext) (Ciphertext, error)
+	Square(x Ciphertext) (Ciphertext, error)
+	Add(x, y Ciphertext) (Ciphertext, error)
+	Sub(x, y Ciphertext) (Ciphertext, error)
+	MulConst(x Ciphertext, v float64) (Ciphertext, error)
+	AddConst(x Ciphertext, v float64) (Ciphertext, error)
+}
+
+// Evaluate evaluates p on x with baby-step giant-step evaluation, which
+// needs p.Depth() levels and about 2*sqrt(d) ciphertext multiplications for
+// degree d.
+func Evaluate(ev Evaluator, x Ciphertext, p *Polynomial) (Ciphertext, error) {
+	if err := p.Check(); err != nil {
+		return nil, err
+	}
+	plan := newPolyPlan(p)
+	if plan.shift != 0 {
+		var err error
+		if x, err = ev.AddConst(x, plan.shift); err != nil {
+			return nil, err
+		}
+	}
+	e := &polyEval{
+		Evaluator: ev,
+		plan:      plan,
+		powers:    map[int]Ciphertext{1: x},
+	}
+	return e.eval(plan.coeffs, plan.m)
+}
+
+type polyEval struct {
+	Evaluator
+	plan   *polyPlan
+	powers map[int]Ciphertext
+}
+
+// power returns P_i(x) at depth ceil(log2 i), as the product of P_a and
+// P_b with a the largest power of two below i and b = i - a.
+func (e *polyEval) power(i int) (Ciphertext, error) {
+	if c, ok := e.powers[i]; ok {
+		return c, nil
+	}
+	a := 1 << uint(bits.Len(uint(i-1))-1)
+	b := i - a
+	pa, err := e.power(a)
+	if err != nil {
+		return nil, err
+	}
+	var r Ciphertext
+	if a == b {
+		r, err = e.Square(pa)
+	} else {
+		var pb Ciphertext
+		if pb, err = e.power(b); err != nil {
+			return nil, err
+		}
+		r, err = e.Mul(pa, pb)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if e.plan.basis == Chebyshev {
+		// S_{a+b} = 2*S_a*S_b - w^b*S_{a-b}; doubling by addition is free,
+		// and S_{a-b} is shallow enough to scale by w^b.
+		if r, err = e.Add(r, r); err != nil {
+			return nil, err
+		}
+		wb := math.Pow(e.plan.w, float64(b))
+		if a == b {
+			r, err = e.AddConst(r, -wb)
+		} else {
+			var d Ciphertext
+			if d, err = e.power(a - b); err != nil {
+				return nil, err
+			}
+			if wb != 1 {
+				if d, err = e.MulConst(d, wb); err != nil {
+					return nil, err
+				}
+			}
+			r, err = e.Sub(r, d)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	e.powers[i] = r
+	return r, nil
+}
+
+func (e *polyEval) eval(c []float64, m int) (Ciphertext, error) {
+	if m == 0 {
+		return e.leaf(c)
+	}
+	n := e.plan.k << uint(m-1)
+	if len(c) <= n {
+		return e.eval(c, m-1)
+	}
+	q, r := e.plan.divide(c, n)
+	giant, err := e.power(n)
+	if err != nil {
+		return nil, err
+	}
+	var prod Ciphertext
+	if len(q) == 1 {
+		prod, err = e.MulConst(giant, q[0])
+	} else {
+		var qc Ciphertext
+		if qc, err = e.eval(q, m-1); err != nil {
+			return nil, err
+		}
+		prod, err = e.Mul(qc, giant)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rc, err := e.eval(r, m-1)
+	if err != nil {
+		return nil, err
+	}
+	return e.Add(prod, rc)
+}
+
+// leaf evaluates a baby-step polynomial as a sum of terms.
+func (e *polyEval) leaf(c []float64) (Ciphertext, error) {
+	var sum Ciphertext
+	for i := 1; i < len(c); i++ {
+		if c[i] == 0 {
+			continue
+		}
+		t, err := e.term(i, c[i])
+		if err != nil {
+			return nil, err
+		}
+		if sum == nil {
+			sum = t
+		} else if sum, err = e.Add(sum, t); err != nil {
+			return nil, err
+		}
+	}
+	if sum == nil {
+		// A constant still has to come out encrypted.
+		var err error
+		if sum, err = e.MulConst(e.powers[1], 0); err != nil {
+			return nil, err
+		}
+	}
+	if len(c) == 0 || c[0] == 0 {
+		return sum, nil
+	}
+	return e.AddConst(sum, c[0])
+}
+
+// term returns v*P_i at depth ceil(log2(i+1)). Like power it multiplies P_a
+// by P_b, but v goes on the shallower factor P_b, where it costs no extra
+// level.
+func (e *polyEval) term(i int, v float64) (Ciphertext, error) {
+	if i == 1 {
+		return e.MulConst(e.powers[1], v)
+	}
+	a := 1 << uint(bits.Len(uint(i-1))-1)
+	b := i - a
+	if e.plan.basis == Chebyshev {
+		v *= 2
+	}
+	vb, err := e.term(b, v)
+	if err != nil {
+		return nil, err
+	}
+	pa, err := e.power(a)
+	if err != nil {
+		return nil, err
+	}
+	r, err := e.Mul(vb, pa)
+	if err != nil || e.plan.basis != Chebyshev {
+		return r, err
+	}
+	// v*S_{a+b} = 2v*S_b*S_a - v*w^b*S_{a-b}, with v already doubled.
+	wb := v / 2 * math.Pow(e.plan.w, float64(b))
+	if a == b {
+		return e.AddConst(r, -wb)
+	}
+	d, err := e.term(a-b, wb)
+	if err != nil {
+		return nil, err
+	}
+	return e.Sub(r, d)
+}
@@ -0,0 +1,128 @@
+package poly
+
+import (
+	"math"
+	"testing"
+)
+
+// value is a cleartext stand-in for a ciphertext that counts the levels
+// consumed to compute it.
+type value struct {
+	v     float64
+	depth int
+}
+
+// clear evaluates on values, consuming a level per product and per scalar
+// multiplication like the CKKS backends.
+type clear struct{}
+
+func maxDepth(x, y Ciphertext) int {
+	return maxInt(x.(value).depth, y.(value).depth)
+}
+
+func (clear) Mul(x, y Ciphertext) (Ciphertext, error) {
+	return value{x.(value).v * y.(value).v, maxDepth(x, y) + 1}, nil
+}
+
+func (clear) Square(x Ciphertext) (Ciphertext, error) {
+	return value{x.(value).v * x.(value).v, x.(value).depth + 1}, nil
+}
+
+func (clear) Add(x, y Ciphertext) (Ciphertext, error) {
+	return value{x.(value).v + y.(value).v, maxDepth(x, y)}, nil
+}
+
+func (clear) Sub(x, y Ciphertext) (Ciphertext, error) {
+	return value{x.(value).v - y.(value).v, maxDepth(x, y)}, nil
+}
+
+func (clear) MulConst(x Ciphertext, v float64) (Ciphertext, error) {
+	return value{x.(value).v * v, x.(value).depth + 1}, nil
+}
+
+func (clear) AddConst(x Ciphertext, v float64) (Ciphertext, error) {
+	return value{x.(value).v + v, x.(value).depth}, nil
+}
+
+func TestDepth(t *testing.T) {
+	tests := []struct {
+		p    Polynomial
+		want int
+	}{
+		{Polynomial{Coeffs: []float64{2}}, 1},
+		{Polynomial{Coeffs: []float64{1, 2}}, 1},
+		{Polynomial{Coeffs: []float64{1, 2, 3, 4}}, 2},
+		{Polynomial{Coeffs: []float64{1, 2, 3, 4, 5, 6, 7, 8, 0, 0}}, 3},
+		{Polynomial{Coeffs: []float64{1, 0, 0, 0, 0, 0, 0, 0, 1}}, 4},
+		{Polynomial{Coeffs: make([]float64, 16)}, 1},
+		{Polynomial{Basis: Chebyshev, Coeffs: []float64{1, 2, 3, 4}}, 2},
+		{Polynomial{Basis: Chebyshev, A: 0, B: 2, Coeffs: []float64{1, 2, 3, 4}}, 2},
+		{Polynomial{Basis: Chebyshev, A: -8, B: 8, Coeffs: []float64{0.5, 0.2, 0, -0.02, 0, 0.003, 0, -0.0003}}, 3},
+		{Polynomial{}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.p.Depth(); got != tt.want {
+			t.Errorf("%v %v: Depth() = %d; want %d", tt.p.Basis, tt.p.Coeffs, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []*Polynomial{
+		{Coeffs: []float64{1.5}},
+		{Coeffs: []float64{0.5, -2}},
+		{Coeffs: []float64{1, 0.5, -0.25, 0.125}},
+		{Coeffs: []float64{1, -1, 1, -1, 1, -1, 1, -1, 1, -1, 0.5}},
+		{Basis: Chebyshev, A: -4, B: 4, Coeffs: []float64{0.5, 0.6, 0, -0.1, 0, 0.02, 0, -0.003}},
+		{Basis: Chebyshev, A: -1, B: 3, Coeffs: []float64{0.5, 0.6, 0.1, -0.1, 0.05, 0.02, -0.01, -0.003, 0.001}},
+		{Basis: Chebyshev, Coeffs: []float64{0, 1, 0.5, 0.25, 0.125, 0, 0.1, 0.2, 0.3}},
+	}
+	for _, p := range tests {
+		for _, x := range []float64{-1, -0.5, 0, 0.25, 1} {
+			r, err := Evaluate(clear{}, value{v: x}, p)
+			if err != nil {
+				t.Fatalf("%v %v: %v", p.Basis, p.Coeffs, err)
+			}
+			got := r.(value)
+			if want := p.Evaluate(x); math.Abs(got.v-want) > 1e-9 {
+				t.Errorf("%v %v: p(%g) = %g; want %g", p.Basis, p.Coeffs, x, got.v, want)
+			}
+			if got.depth != p.Depth() {
+				t.Errorf("%v %v: used %d levels; Depth() = %d", p.Basis, p.Coeffs, got.depth, p.Depth())
+			}
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	for _, p := range []*Polynomial{
+		{},
+		{Basis: Basis(7), Coeffs: []float64{1}},
+		{Basis: Chebyshev, A: 2, B: 1, Coeffs: []float64{1}},
+	} {
+		if p.Check() == nil {
+			t.Errorf("Check(%+v) succeeded", p)
+		}
+		if _, err := Evaluate(clear{}, value{}, p); err == nil {
+			t.Errorf("Evaluate(%+v) succeeded", p)
+		}
+	}
+}
+
+func TestDerivative(t *testing.T) {
+	const h = 1e-6
+	for _, p := range []*Polynomial{
+		{Coeffs: []float64{1, 0.5, -0.25, 0.125}},
+		{Basis: Chebyshev, Coeffs: []float64{0.5, -1, 0.25, 2}},
+		{Basis: Chebyshev, A: -8, B: 8, Coeffs: []float64{0.5, 0.6, 0, -0.1, 0, 0.02}},
+		{Basis: Chebyshev, Coeffs: []float64{3}},
+	} {
+		d := p.Derivative()
+		for _, x := range []float64{-0.9, -0.2, 0, 0.7} {
+			want := (p.Evaluate(x+h) - p.Evaluate(x-h)) / (2 * h)
+			if got := d.Evaluate(x); math.Abs(got-want) > 1e-6 {
+				t.Errorf("%v %v: p'(%g) = %g; want %g", p.Basis, p.Coeffs, x, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package seal
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// DefaultScale is the scale CKKSEncoder.Encode encodes at.
+	DefaultScale = 1 << 60
+	// DefaultScaleTolerance is the relative scale difference AutoEvaluator
+	// absorbs by relabelling a scale.
+	DefaultScaleTolerance = 1e-3
+	// minScaleBridge is the smallest scale ratio AutoEvaluator bridges by
+	// multiplying with an encoded one directly; below it the rounding error
+	// of the encoded one would be noticeable.
+	minScaleBridge = 1 << 20
+)
+
+// AutoEvaluator wraps a CKKS Evaluator so operands at different levels or
+// scales can be combined directly. Products are relinearized and rescaled,
+// the operand higher in the modulus chain is switched down to the other's
+// level, and small scale differences are fixed up. Its methods never modify
+// their arguments.
+type AutoEvaluator struct {
+	Evaluator *Evaluator
+	Encoder   *CKKSEncoder
+	RelinKeys *RelinKeys
+	// GaloisKeys are needed by Rotate only.
+	GaloisKeys *GaloisKeys
+	// Scale is the working scale. Products are rescaled while the result
+	// stays at or above half of it.
+	Scale float64
+	// Tolerance is the largest relative scale difference Add and Sub fix by
+	// relabelling. Larger differences are bridged by multiplying the operand
+	// with the smaller scale by an encoded one, rescaling it if the ratio is
+	// small, or fail with ErrScaleMismatch at the last level.
+	Tolerance float64
+}
+
+// NewAutoEvaluator returns an AutoEvaluator working at DefaultScale. The
+// relinearization keys may be nil if Mul and Square are not used.
+func NewAutoEvaluator(c *Context, relin *RelinKeys) (*AutoEvaluator, error) {
+	if err := checkOpen("NewAutoEvaluator", c); err != nil {
+		return nil, err
+	}
+	if s := c.Scheme(); s != SchemeCKKS {
+		return nil, &Error{
+			Op:      "NewAutoEvaluator",
+			Message: fmt.Sprintf("scheme %v is not supported", s),
+			Err:     ErrInvalidParameters,
+		}
+	}
+	eval, err := NewEvaluator(c)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := NewCKKSEncoder(c)
+	if err != nil {
+		return nil, err
+	}
+	return &AutoEvaluator{
+		Evaluator: eval,
+		Encoder:   enc,
+		RelinKeys: relin,
+		Scale:     DefaultScale,
+		Tolerance: DefaultScaleTolerance,
+	}, nil
+}
+
+func (a *AutoEvaluator) Add(x, y *Ciphertext) (*Ciphertext, error) {
+	x, y, err := a.align("AutoEvaluator.Add", x, y, true)
+	if err != nil {
+		return nil, err
+	}
+	return a.Evaluator.Add(x, y)
+}
+
+func (a *AutoEvaluator) Sub(x, y *Ciphertext) (*Ciphertext, error) {
+	x, y, err := a.align("AutoEvaluator.Sub", x, y, true)
+	if err != nil {
+		return nil, err
+	}
+	return a.Evaluator.Sub(x, y)
+}
+
+// Mul multiplies x and y, then relinearizes and rescales the product.
+func (a *AutoEvaluator) Mul(x, y *Ciphertext) (*Ciphertext, error) {
+	x, y, err := a.align("AutoEvaluator.Mul", x, y, false)
+	if err != nil {
+		return nil, err
+	}
+	r, err := a.Evaluator.Multiply(x, y)
+	if err != nil {
+		return nil, err
+	}
+	return r, a.finish(r)
+}
+
+// Square squares x, then relinearizes and rescales the result.
+func (a *AutoEvaluator) Square(x *Ciphertext) (*Ciphertext, error) {
+	r, err := a.Evaluator.Square(x)
+	if err != nil {
+		return nil, err
+	}
+	return r, a.finish(r)
+}
+
+// Relinearize returns x relinearized to size 2; x is copied unchanged if it
+// already has size 2.
+func (a *AutoEvaluator) Relinearize(x *Ciphertext) (*Ciphertext, error) {
+	r := x.Copy()
+	if r.Size() <= 2 {
+		return r, nil
+	}
+	return r, a.relinearize(r)
+}
+
+// Rescale returns x rescaled while its scale stays at or above half the
+// working scale.
+func (a *AutoEvaluator) Rescale(x *Ciphertext) (*Ciphertext, error) {
+	r := x.Copy()
+	return r, a.rescale(r)
+}
+
+func (a *AutoEvaluator) Negate(x *Ciphertext) (*Ciphertext, error) {
+	r := x.Copy()
+	if err := a.Evaluator.NegateInplace(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// AddConst adds v to every slot of x.
+func (a *AutoEvaluator) AddConst(x *Ciphertext, v float64) (*Ciphertext, error) {
+	p, err := a.encodeAt(x, v, x.Scale())
+	if err != nil {
+		return nil, err
+	}
+	defer p.Close()
+	r := x.Copy()
+	if err := a.Evaluator.AddPlainInplace(r, p); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// AddVector adds the values of v to the slots of x; missing values are zero.
+func (a *AutoEvaluator) AddVector(x *Ciphertext, v []float64) (*Ciphertext, error) {
+	id := x.ParmsID()
+	defer id.Close()
+	p, err := a.Encoder.EncodeVectorParmsIDScale(v, id, x.Scale())
+	if err != nil {
+		return nil, err
+	}
+	defer p.Close()
+	r := x.Copy()
+	if err := a.Evaluator.AddPlainInplace(r, p); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// MulConst multiplies every slot of x by v. The constant is encoded at the
+// last prime of x's modulus, so rescaling by that prime restores x's scale.
+func (a *AutoEvaluator) MulConst(x *Ciphertext, v float64) (*Ciphertext, error) {
+	return a.mulPlain(x, func(scale float64) (*Plaintext, error) {
+		return a.encodeAt(x, v, scale)
+	})
+}
+
+// MulVector multiplies the slots of x by the values of v, as MulConst does;
+// missing values are zero.
+func (a *AutoEvaluator) MulVector(x *Ciphertext, v []float64) (*Ciphertext, error) {
+	return a.mulPlain(x, func(scale float64) (*Plaintext, error) {
+		id := x.ParmsID()
+		defer id.Close()
+		return a.Encoder.EncodeVectorParmsIDScale(v, id, scale)
+	})
+}
+
+func (a *AutoEvaluator) mulPlain(x *Ciphertext, encode func(scale float64) (*Plaintext, error)) (*Ciphertext, error) {
+	level, q, err := a.lastPrime(x)
+	if err != nil {
+		return nil, err
+	}
+	if level == 0 {
+		// Nothing left to rescale by; the product keeps the larger scale.
+		q = a.Scale
+	}
+	p, err := encode(q)
+	if err != nil {
+		return nil, err
+	}
+	defer p.Close()
+	r, err := a.Evaluator.MultiplyPlain(x, p)
+	if err != nil {
+		return nil, err
+	}
+	if level == 0 {
+		return r, nil
+	}
+	if err := a.Evaluator.RescaleToNextInplace(r); err != nil {
+		return nil, err
+	}
+	r.SetScale(x.Scale())
+	return r, nil
+}
+
+// Rotate rotates the slots of x left by steps with a.GaloisKeys.
+func (a *AutoEvaluator) Rotate(x *Ciphertext, steps int) (*Ciphertext, error) {
+	if a.GaloisKeys == nil {
+		return nil, &Error{
+			Op:      "AutoEvaluator.Rotate",
+			Message: "no Galois keys",
+			Err:     ErrMissingKeys,
+		}
+	}
+	return a.Evaluator.RotateVector(x, steps, a.GaloisKeys)
+}
+
+func (a *AutoEvaluator) encodeAt(x *Ciphertext, v, scale float64) (*Plaintext, error) {
+	id := x.ParmsID()
+	defer id.Close()
+	return a.Encoder.EncodeParmsIDScale(v, id, scale)
+}
+
+// lastPrime returns the level of c and the prime a rescale of c divides by.
+func (a *AutoEvaluator) lastPrime(c *Ciphertext) (int, float64, error) {
+	id := c.ParmsID()
+	defer id.Close()
+	level, err := a.Evaluator.ctx.ChainIndex(id)
+	if err != nil {
+		return 0, 0, err
+	}
+	primes, err := a.Evaluator.ctx.CoeffModulus(id)
+	if err != nil {
+		return 0, 0, err
+	}
+	return level, float64(primes[len(primes)-1]), nil
+}
+
+// align returns x and y switched to the same level and, if matchScale is
+// set, relabelled or multiplied so their scales are equal. Operands that
+// need changes are copied first.
+func (a *AutoEvaluator) align(op string, x, y *Ciphertext, matchScale bool) (*Ciphertext, *Ciphertext, error) {
+	x, y, err := a.matchLevels(x, y)
+	if err != nil || !matchScale {
+		return x, y, err
+	}
+
+	sx, sy := x.Scale(), y.Scale()
+	switch ratio := sx / sy; {
+	case ratio == 1:
+		return x, y, nil
+	case math.Abs(ratio-1) <= a.Tolerance:
+		y = y.Copy()
+		y.SetScale(sx)
+		return x, y, nil
+	case ratio > 1:
+		y, err = a.raiseScale(op, y, sx)
+	default:
+		x, err = a.raiseScale(op, x, sy)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	// Bridging a small ratio costs a level.
+	return a.matchLevels(x, y)
+}
+
+// matchLevels returns x and y with the one higher in the modulus chain
+// switched down to the other's level.
+func (a *AutoEvaluator) matchLevels(x, y *Ciphertext) (*Ciphertext, *Ciphertext, error) {
+	lx, err := a.Evaluator.Level(x)
+	if err != nil {
+		return nil, nil, err
+	}
+	ly, err := a.Evaluator.Level(y)
+	if err != nil {
+		return nil, nil, err
+	}
+	if lx > ly {
+		id := y.ParmsID()
+		defer id.Close()
+		if x, err = a.Evaluator.ModSwitchTo(x, id); err != nil {
+			return nil, nil, err
+		}
+	} else if ly > lx {
+		id := x.ParmsID()
+		defer id.Close()
+		if y, err = a.Evaluator.ModSwitchTo(y, id); err != nil {
+			return nil, nil, err
+		}
+	}
+	return x, y, nil
+}
+
+// raiseScale multiplies c by one encoded at the ratio between scale and
+// c's scale, giving a copy of c at scale. Ratios too small to encode one
+// accurately are multiplied by the last prime first and divided out again
+// by a rescale, which needs c to have a level left.
+func (a *AutoEvaluator) raiseScale(op string, c *Ciphertext, scale float64) (*Ciphertext, error) {
+	ratio := scale / c.Scale()
+	bridge := ratio < minScaleBridge
+	if bridge {
+		level, q, err := a.lastPrime(c)
+		if err != nil {
+			return nil, err
+		}
+		if level == 0 {
+			return nil, &Error{
+				Op:      op,
+				Message: fmt.Sprintf("scale mismatch at the last level: %g and %g", c.Scale(), scale),
+				Err:     ErrScaleMismatch,
+			}
+		}
+		ratio *= q
+	}
+	p, err := a.encodeAt(c, 1, ratio)
+	if err != nil {
+		return nil, err
+	}
+	defer p.Close()
+	r, err := a.Evaluator.MultiplyPlain(c, p)
+	if err != nil {
+		return nil, err
+	}
+	if bridge {
+		if err := a.Evaluator.RescaleToNextInplace(r); err != nil {
+			return nil, err
+		}
+	}
+	// Rounding in the product of the scales must not fail SEAL's exact check.
+	r.SetScale(scale)
+	return r, nil
+}
+
+// finish relinearizes and rescales a product.
+func (a *AutoEvaluator) finish(r *Ciphertext) error {
+	if err := a.relinearize(r); err != nil {
+		return err
+	}
+	return a.rescale(r)
+}
+
+func (a *AutoEvaluator) relinearize(r *Ciphertext) error {
+	if a.RelinKeys == nil {
+		return &Error{
+			Op:      "AutoEvaluator.Relinearize",
+			Message: "no relinearization keys",
+			Err:     ErrMissingKeys,
+		}
+	}
+	return a.Evaluator.RelinearizeInplace(r, a.RelinKeys)
+}
+
+// rescale divides r by the primes at the end of its modulus while the scale
+// stays at or above half the working scale.
+func (a *AutoEvaluator) rescale(r *Ciphertext) error {
+	for {
+		level, q, err := a.lastPrime(r)
+		if err != nil {
+			return err
+		}
+		if level == 0 || r.Scale()/q < a.Scale/2 {
+			return nil
+		}
+		if err := a.Evaluator.RescaleToNextInplace(r); err != nil {
+			return err
+		}
+	}
+}
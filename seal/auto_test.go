@@ -0,0 +1,171 @@
+package seal
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func newAutoKit(t *testing.T) (*ckksKit, *AutoEvaluator) {
+	t.Helper()
+	k := newCKKSKit(t)
+	relin, err := k.keygen.RelinKeys(60, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := NewAutoEvaluator(k.ctx, relin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k, a
+}
+
+func TestAutoEvaluatorAlignsOperands(t *testing.T) {
+	k, a := newAutoKit(t)
+	encrypt := func(v, scale float64) *Ciphertext {
+		p, err := k.enc.EncodeScale(v, scale)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := k.encryptor.Encrypt(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	x := encrypt(3, DefaultScale)
+	y, err := a.Mul(x, encrypt(2, DefaultScale))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lx, _ := a.Evaluator.Level(x)
+	if ly, _ := a.Evaluator.Level(y); ly >= lx {
+		t.Fatalf("Mul did not rescale: level %d, operand level %d", ly, lx)
+	}
+
+	tests := []struct {
+		name string
+		f    func() (*Ciphertext, error)
+		want float64
+	}{
+		{"levels", func() (*Ciphertext, error) { return a.Add(x, y) }, 9},
+		{"sub levels", func() (*Ciphertext, error) { return a.Sub(y, x) }, 3},
+		{"tolerance", func() (*Ciphertext, error) {
+			return a.Add(encrypt(1, math.Pow(2, 40)), encrypt(2, math.Pow(2, 40)*(1+1e-5)))
+		}, 3},
+		{"bridge", func() (*Ciphertext, error) {
+			return a.Add(encrypt(1, math.Pow(2, 30)), encrypt(2, math.Pow(2, 55)))
+		}, 3},
+		{"small ratio", func() (*Ciphertext, error) {
+			return a.Add(encrypt(1, math.Pow(2, 40)), encrypt(2, 1.5*math.Pow(2, 40)))
+		}, 3},
+		{"const", func() (*Ciphertext, error) { return a.AddConst(y, 0.5) }, 6.5},
+		{"mul const", func() (*Ciphertext, error) { return a.MulConst(x, -2) }, -6},
+		{"square", func() (*Ciphertext, error) { return a.Square(x) }, 9},
+	}
+	for _, tt := range tests {
+		c, err := tt.f()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if out := decryptVector(t, k, c); math.Abs(out[0]-tt.want) > 1e-2 {
+			t.Errorf("%s: got %f; want %f", tt.name, out[0], tt.want)
+		}
+	}
+	if got := decryptVector(t, k, x)[0]; math.Abs(got-3) > 1e-3 {
+		t.Errorf("operand modified: got %f; want 3", got)
+	}
+}
+
+func TestAutoEvaluatorScaleMismatch(t *testing.T) {
+	k, a := newAutoKit(t)
+	encrypt := func(v, scale float64) *Ciphertext {
+		p, err := k.enc.EncodeScale(v, scale)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := k.encryptor.Encrypt(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	// Small ratios are bridged by a rescale, which the last level lacks.
+	last := k.ctx.LastParmsID()
+	defer last.Close()
+	x, err := a.Evaluator.ModSwitchTo(encrypt(1, math.Pow(2, 40)), last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := a.Evaluator.ModSwitchTo(encrypt(1, 1.5*math.Pow(2, 40)), last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Add(x, y); !errors.Is(err, ErrScaleMismatch) {
+		t.Errorf("Add() = %v; want ErrScaleMismatch", err)
+	}
+
+	bfv := newBFVKit(t)
+	if _, err := NewAutoEvaluator(bfv.ctx, nil); !errors.Is(err, ErrInvalidParameters) {
+		t.Errorf("NewAutoEvaluator(BFV) = %v; want ErrInvalidParameters", err)
+	}
+}
+
+func TestAutoEvaluatorVectors(t *testing.T) {
+	k, a := newAutoKit(t)
+	p, err := k.enc.EncodeVector([]float64{1, 2, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Rotate(x, 1); !errors.Is(err, ErrMissingKeys) {
+		t.Errorf("Rotate(no keys) = %v; want ErrMissingKeys", err)
+	}
+	if a.GaloisKeys, err = k.keygen.GaloisKeys(60); err != nil {
+		t.Fatal(err)
+	}
+	y, err := a.MulVector(x, []float64{2, 0, -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if y, err = a.AddVector(y, []float64{0, 0, 1}); err != nil {
+		t.Fatal(err)
+	}
+	if y, err = a.Rotate(y, 2); err != nil {
+		t.Fatal(err)
+	}
+	if y.Scale() != x.Scale() {
+		t.Errorf("scale = %g; want %g", y.Scale(), x.Scale())
+	}
+	out := decryptVector(t, k, y)
+	for i, want := range []float64{-2, 0, 0, 0} {
+		if math.Abs(out[i]-want) > 1e-3 {
+			t.Errorf("slot %d = %f; want %f", i, out[i], want)
+		}
+	}
+	if last := out[len(out)-2]; math.Abs(last-2) > 1e-3 {
+		t.Errorf("slot %d = %f; want 2", len(out)-2, last)
+	}
+
+	// Constants reach every slot, not only the first.
+	if y, err = a.AddConst(x, 1); err != nil {
+		t.Fatal(err)
+	}
+	if y, err = a.MulConst(y, -2); err != nil {
+		t.Fatal(err)
+	}
+	out = decryptVector(t, k, y)
+	for i, want := range []float64{-4, -6, -8, -10} {
+		if math.Abs(out[i]-want) > 1e-3 {
+			t.Errorf("const slot %d = %f; want %f", i, out[i], want)
+		}
+	}
+	if last := out[len(out)-1]; math.Abs(last+2) > 1e-3 {
+		t.Errorf("const slot %d = %f; want -2", len(out)-1, last)
+	}
+}
@@ -0,0 +1,141 @@
+package seal
+
+// #include "seal.h"
+import "C"
+
+import (
+	"runtime"
+	"unsafe"
+)
+
+// BatchEncoder packs vectors of integers modulo the plain modulus into the
+// slots of a BFV plaintext. The slots form a matrix of two rows of
+// SlotCount()/2 columns; rows are rotated cyclically by Evaluator.RotateRows
+// and swapped by Evaluator.RotateColumns.
+//
+// Batching requires a prime plain modulus congruent to 1 mod 2*degree, see
+// BatchingPlainModulus.
+type BatchEncoder struct {
+	ptr C.SEALBatchEncoder
+	native
+}
+
+func NewBatchEncoder(c *Context) (*BatchEncoder, error) {
+	if err := checkOpen("NewBatchEncoder", c); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALBatchEncoder
+	err := checkError("NewBatchEncoder", C.SEALBatchEncoderInit(c.ptr, &ptr))
+	runtime.KeepAlive(c)
+	if err != nil {
+		return nil, err
+	}
+	obj := &BatchEncoder{
+		ptr: ptr,
+	}
+	runtime.SetFinalizer(obj, (*BatchEncoder).free)
+	track(obj)
+	return obj, nil
+}
+
+// BatchingPlainModulus returns a prime of the given bit size suitable as a
+// batching plain modulus for polyModulusDegree.
+func BatchingPlainModulus(polyModulusDegree, bitSize int) (uint64, error) {
+	primes, err := coeffModulusPrimes(polyModulusDegree, []int{bitSize})
+	if err != nil {
+		return 0, &Error{
+			Op:      "BatchingPlainModulus",
+			Message: err.Error(),
+			Err:     ErrInvalidArgument,
+		}
+	}
+	return primes[0], nil
+}
+
+func (e *BatchEncoder) SlotCount() int {
+	if e.closed() {
+		return 0
+	}
+	n := int(C.SEALBatchEncoderSlotCount(e.ptr))
+	runtime.KeepAlive(e)
+	return n
+}
+
+// RowCount is always 2.
+func (e *BatchEncoder) RowCount() int {
+	return 2
+}
+
+// RowSize returns the number of columns in each row.
+func (e *BatchEncoder) RowSize() int {
+	return e.SlotCount() / 2
+}
+
+// EncodeUint64 packs values, each reduced modulo the plain modulus, into the
+// slots in row-major order. Unused slots are zero.
+func (e *BatchEncoder) EncodeUint64(values []uint64) (*Plaintext, error) {
+	if err := checkOpen("BatchEncoder.EncodeUint64", e); err != nil {
+		return nil, err
+	}
+	var data *C.uint64_t
+	if len(values) > 0 {
+		data = (*C.uint64_t)(unsafe.Pointer(&values[0]))
+	}
+	var ptr C.SEALPlaintext
+	err := checkError("BatchEncoder.EncodeUint64", C.SEALBatchEncoderEncodeUint64(e.ptr, data, C.size_t(len(values)), &ptr))
+	runtime.KeepAlive(e)
+	if err != nil {
+		return nil, err
+	}
+	return newPlaintext(ptr), nil
+}
+
+// EncodeInt64 packs signed values, which must lie within half the plain
+// modulus of zero.
+func (e *BatchEncoder) EncodeInt64(values []int64) (*Plaintext, error) {
+	if err := checkOpen("BatchEncoder.EncodeInt64", e); err != nil {
+		return nil, err
+	}
+	var data *C.int64_t
+	if len(values) > 0 {
+		data = (*C.int64_t)(unsafe.Pointer(&values[0]))
+	}
+	var ptr C.SEALPlaintext
+	err := checkError("BatchEncoder.EncodeInt64", C.SEALBatchEncoderEncodeInt64(e.ptr, data, C.size_t(len(values)), &ptr))
+	runtime.KeepAlive(e)
+	if err != nil {
+		return nil, err
+	}
+	return newPlaintext(ptr), nil
+}
+
+// DecodeUint64 returns every slot in row-major order.
+func (e *BatchEncoder) DecodeUint64(p *Plaintext) ([]uint64, error) {
+	if err := checkOpen("BatchEncoder.DecodeUint64", e, p); err != nil {
+		return nil, err
+	}
+	out := make([]uint64, e.SlotCount())
+	err := checkError("BatchEncoder.DecodeUint64", C.SEALBatchEncoderDecodeUint64(e.ptr, p.ptr, (*C.uint64_t)(unsafe.Pointer(&out[0]))))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(p)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DecodeInt64 returns every slot, mapping values above half the plain
+// modulus to negatives.
+func (e *BatchEncoder) DecodeInt64(p *Plaintext) ([]int64, error) {
+	if err := checkOpen("BatchEncoder.DecodeInt64", e, p); err != nil {
+		return nil, err
+	}
+	out := make([]int64, e.SlotCount())
+	err := checkError("BatchEncoder.DecodeInt64", C.SEALBatchEncoderDecodeInt64(e.ptr, p.ptr, (*C.int64_t)(unsafe.Pointer(&out[0]))))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(p)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
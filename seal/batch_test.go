@@ -0,0 +1,106 @@
+package seal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBatchEncoder(t *testing.T) {
+	k := newBFVKit(t)
+	if got, want := k.batch.SlotCount(), 2048; got != want {
+		t.Fatalf("SlotCount() = %d; want %d", got, want)
+	}
+	if got, want := k.batch.RowSize(), 1024; got != want {
+		t.Fatalf("RowSize() = %d; want %d", got, want)
+	}
+
+	a := []int64{1, -2, 3, 400, -5000}
+	b := []int64{10, 20, -30, 2, 1}
+	pa, err := k.batch.EncodeInt64(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb, err := k.batch.EncodeInt64(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := k.encryptor.Encrypt(pa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb, err := k.encryptor.Encrypt(pb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := k.eval.AddInplace(ca, cb); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.eval.MultiplyPlainInplace(ca, pb); err != nil {
+		t.Fatal(err)
+	}
+	plain, err := k.decryptor.Decrypt(ca)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := k.batch.DecodeInt64(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range out {
+		var want int64
+		if i < len(a) {
+			want = (a[i] + b[i]) * b[i]
+		}
+		if out[i] != want {
+			t.Fatalf("slot %d = %d; want %d", i, out[i], want)
+		}
+	}
+
+	u := []uint64{0, 1, 12288}
+	pu, err := k.batch.EncodeUint64(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outU, err := k.batch.DecodeUint64(pu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range u {
+		if outU[i] != v {
+			t.Fatalf("slot %d = %d; want %d", i, outU[i], v)
+		}
+	}
+}
+
+func TestBatchEncoderRequiresBatching(t *testing.T) {
+	params, err := NewEncryptionParams(SchemeBFV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := params.SetPolyModulusDegree(2048); err != nil {
+		t.Fatal(err)
+	}
+	if err := params.SetDefaultCoeffModulus(); err != nil {
+		t.Fatal(err)
+	}
+	if err := params.SetPlainModulus(1 << 8); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewContext(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewBatchEncoder(c); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("NewBatchEncoder = %v; want ErrInvalidParameters", err)
+	}
+}
+
+func TestBatchingPlainModulus(t *testing.T) {
+	p, err := BatchingPlainModulus(4096, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p%8192 != 1 || p >= 1<<20 || p < 1<<19 {
+		t.Fatalf("BatchingPlainModulus(4096, 20) = %d", p)
+	}
+}
@@ -0,0 +1,107 @@
+package seal
+
+// #include "seal.h"
+import "C"
+
+import (
+	"runtime"
+	"unsafe"
+)
+
+// SlotCount returns the number of values packed into one plaintext, which
+// is half the poly modulus degree.
+func (e *CKKSEncoder) SlotCount() int {
+	if e.closed() {
+		return 0
+	}
+	n := int(C.SEALCKKSEncoderSlotCount(e.ptr))
+	runtime.KeepAlive(e)
+	return n
+}
+
+// EncodeVector packs values into the slots of a single plaintext at a 60 bit
+// scale. Unused slots are zero.
+func (e *CKKSEncoder) EncodeVector(values []float64) (*Plaintext, error) {
+	return e.EncodeVectorScale(values, DefaultScale)
+}
+
+func (e *CKKSEncoder) EncodeVectorScale(values []float64, scale float64) (*Plaintext, error) {
+	return e.EncodeVectorParmsIDScale(values, &ParmsID{}, scale)
+}
+
+func (e *CKKSEncoder) EncodeVectorParmsIDScale(values []float64, p *ParmsID, scale float64) (*Plaintext, error) {
+	if err := checkOpen("CKKSEncoder.EncodeVector", e); err != nil {
+		return nil, err
+	}
+	var data *C.double
+	if len(values) > 0 {
+		data = (*C.double)(unsafe.Pointer(&values[0]))
+	}
+	var ptr C.SEALPlaintext
+	err := checkError("CKKSEncoder.EncodeVector", C.SEALCKKSEncoderEncodeVector(e.ptr, data, C.size_t(len(values)), p.cptr(), C.double(scale), &ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(p)
+	if err != nil {
+		return nil, err
+	}
+	return newPlaintext(ptr), nil
+}
+
+// EncodeComplexVector packs complex values into the slots of a single
+// plaintext at a 60 bit scale.
+func (e *CKKSEncoder) EncodeComplexVector(values []complex128) (*Plaintext, error) {
+	return e.EncodeComplexVectorScale(values, DefaultScale)
+}
+
+func (e *CKKSEncoder) EncodeComplexVectorScale(values []complex128, scale float64) (*Plaintext, error) {
+	return e.EncodeComplexVectorParmsIDScale(values, &ParmsID{}, scale)
+}
+
+func (e *CKKSEncoder) EncodeComplexVectorParmsIDScale(values []complex128, p *ParmsID, scale float64) (*Plaintext, error) {
+	if err := checkOpen("CKKSEncoder.EncodeComplexVector", e); err != nil {
+		return nil, err
+	}
+	// complex128 has the same layout as a pair of doubles.
+	var data *C.double
+	if len(values) > 0 {
+		data = (*C.double)(unsafe.Pointer(&values[0]))
+	}
+	var ptr C.SEALPlaintext
+	err := checkError("CKKSEncoder.EncodeComplexVector", C.SEALCKKSEncoderEncodeComplexVector(e.ptr, data, C.size_t(len(values)), p.cptr(), C.double(scale), &ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(p)
+	if err != nil {
+		return nil, err
+	}
+	return newPlaintext(ptr), nil
+}
+
+// DecodeVector returns the real part of every slot.
+func (e *CKKSEncoder) DecodeVector(p *Plaintext) ([]float64, error) {
+	if err := checkOpen("CKKSEncoder.DecodeVector", e, p); err != nil {
+		return nil, err
+	}
+	out := make([]float64, e.SlotCount())
+	err := checkError("CKKSEncoder.DecodeVector", C.SEALCKKSEncoderDecodeVector(e.ptr, p.ptr, (*C.double)(unsafe.Pointer(&out[0]))))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(p)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DecodeComplexVector returns every slot.
+func (e *CKKSEncoder) DecodeComplexVector(p *Plaintext) ([]complex128, error) {
+	if err := checkOpen("CKKSEncoder.DecodeComplexVector", e, p); err != nil {
+		return nil, err
+	}
+	out := make([]complex128, e.SlotCount())
+	err := checkError("CKKSEncoder.DecodeComplexVector", C.SEALCKKSEncoderDecodeComplexVector(e.ptr, p.ptr, (*C.double)(unsafe.Pointer(&out[0]))))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(p)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,70 @@
+package seal
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestCKKSEncoderVector(t *testing.T) {
+	k := newCKKSKit(t)
+	if got, want := k.enc.SlotCount(), 8192; got != want {
+		t.Fatalf("SlotCount() = %d; want %d", got, want)
+	}
+
+	in := []float64{1.5, -2, 3.25, 0, 42}
+	p, err := k.enc.EncodeVector(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := k.eval.AddInplace(a, a); err != nil {
+		t.Fatal(err)
+	}
+	plain, err := k.decryptor.Decrypt(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := k.enc.DecodeVector(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != k.enc.SlotCount() {
+		t.Fatalf("len(DecodeVector()) = %d; want %d", len(out), k.enc.SlotCount())
+	}
+	for i, v := range out {
+		want := 0.0
+		if i < len(in) {
+			want = 2 * in[i]
+		}
+		if math.Abs(v-want) > 0.00001 {
+			t.Fatalf("slot %d = %f; want %f", i, v, want)
+		}
+	}
+}
+
+func TestCKKSEncoderComplexVector(t *testing.T) {
+	k := newCKKSKit(t)
+	in := []complex128{1 + 2i, -3i, 4}
+	p, err := k.enc.EncodeComplexVector(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := k.enc.DecodeComplexVector(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range in {
+		if cmplx.Abs(out[i]-v) > 0.00001 {
+			t.Fatalf("slot %d = %v; want %v", i, out[i], v)
+		}
+	}
+
+	tooMany := make([]float64, k.enc.SlotCount()+1)
+	if _, err := k.enc.EncodeVector(tooMany); err == nil {
+		t.Fatal("expected error encoding more values than slots")
+	}
+}
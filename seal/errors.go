@@ -0,0 +1,99 @@
+package seal
+
+// #include <stdlib.h>
+// #include "seal.h"
+import "C"
+
+import (
+	"errors"
+	"strings"
+	"unsafe"
+)
+
+// Typed causes for errors raised by SEAL. Use errors.Is to test for them.
+var (
+	ErrScaleMismatch         = errors.New("seal: scale mismatch")
+	ErrScaleOutOfBounds      = errors.New("seal: scale out of bounds")
+	ErrParmsIDMismatch       = errors.New("seal: parms_id mismatch")
+	ErrEndOfModulusChain     = errors.New("seal: end of modulus switching chain reached")
+	ErrTransparentCiphertext = errors.New("seal: result ciphertext is transparent")
+	ErrInvalidParameters     = errors.New("seal: encryption parameters are not valid")
+	ErrInsecureParameters    = errors.New("seal: encryption parameters are below the requested security level")
+	ErrMissingKeys           = errors.New("seal: required keys are missing")
+	ErrCorruptData           = errors.New("seal: serialized data is truncated or corrupt")
+	ErrInvalidArgument       = errors.New("seal: invalid argument")
+	ErrOutOfRange            = errors.New("seal: out of range")
+	ErrLogic                 = errors.New("seal: logic error")
+	ErrNative                = errors.New("seal: native error")
+	ErrClosed                = errors.New("seal: use of closed handle")
+)
+
+// Error is returned when a call into SEAL throws. Op names the failing
+// method, Message holds the exception text and Unwrap yields the typed cause.
+type Error struct {
+	Op      string
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return "seal: " + e.Op + ": " + e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// causes maps substrings of SEAL exception messages onto typed causes. The
+// first match wins, so more specific entries come first.
+var causes = []struct {
+	substr string
+	err    error
+}{
+	{"scale mismatch", ErrScaleMismatch},
+	{"truncated or corrupt", ErrCorruptData},
+	{"scale out of bounds", ErrScaleOutOfBounds},
+	{"end of modulus switching chain", ErrEndOfModulusChain},
+	{"cannot switch to higher level", ErrEndOfModulusChain},
+	{"transparent", ErrTransparentCiphertext},
+	{"parameters are not set correctly", ErrInvalidParameters},
+	{"not valid for batching", ErrInvalidParameters},
+	{"parameter mismatch", ErrParmsIDMismatch},
+	{"parms_id", ErrParmsIDMismatch},
+	{"not valid for encryption parameters", ErrParmsIDMismatch},
+	{"not enough relinearization keys", ErrMissingKeys},
+	{"galois key not present", ErrMissingKeys},
+}
+
+func classify(code int, msg string) error {
+	lower := strings.ToLower(msg)
+	for _, c := range causes {
+		if strings.Contains(lower, c.substr) {
+			return c.err
+		}
+	}
+	switch code {
+	case C.SEAL_ERROR_INVALID_ARGUMENT:
+		return ErrInvalidArgument
+	case C.SEAL_ERROR_OUT_OF_RANGE:
+		return ErrOutOfRange
+	case C.SEAL_ERROR_LOGIC:
+		return ErrLogic
+	}
+	return ErrNative
+}
+
+// checkError converts a SEALError returned by the shim into a Go error and
+// releases its message.
+func checkError(op string, cerr C.SEALError) error {
+	if cerr.code == C.SEAL_OK {
+		return nil
+	}
+	msg := C.GoString(cerr.message)
+	C.free(unsafe.Pointer(cerr.message))
+	return &Error{
+		Op:      op,
+		Message: msg,
+		Err:     classify(int(cerr.code), msg),
+	}
+}
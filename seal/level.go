@@ -0,0 +1,201 @@
+package seal
+
+// #include "seal.h"
+import "C"
+
+import "runtime"
+
+func (p *Plaintext) ParmsID() *ParmsID {
+	if p.closed() {
+		return &ParmsID{}
+	}
+	id := newParmsID(C.SEALPlaintextParmsID(p.ptr))
+	runtime.KeepAlive(p)
+	return id
+}
+
+// FirstParmsID identifies the parameters fresh ciphertexts are encrypted at.
+func (c *Context) FirstParmsID() *ParmsID {
+	if c.closed() {
+		return &ParmsID{}
+	}
+	id := newParmsID(C.SEALContextFirstParmsID(c.ptr))
+	runtime.KeepAlive(c)
+	return id
+}
+
+// LastParmsID identifies the end of the modulus switching chain.
+func (c *Context) LastParmsID() *ParmsID {
+	if c.closed() {
+		return &ParmsID{}
+	}
+	id := newParmsID(C.SEALContextLastParmsID(c.ptr))
+	runtime.KeepAlive(c)
+	return id
+}
+
+// checkParmsID returns ErrParmsIDMismatch for a ParmsID that is unset or
+// closed, so that no nil pointer reaches SEAL.
+func checkParmsID(op string, p *ParmsID) error {
+	if p.closed() {
+		return &Error{Op: op, Message: "parms_id is not set", Err: ErrParmsIDMismatch}
+	}
+	return nil
+}
+
+// ChainIndex returns how many rescales or modulus switches are left before
+// p reaches the end of the chain, so the last parameters have index 0.
+func (c *Context) ChainIndex(p *ParmsID) (int, error) {
+	if err := checkOpen("Context.ChainIndex", c); err != nil {
+		return 0, err
+	}
+	if err := checkParmsID("Context.ChainIndex", p); err != nil {
+		return 0, err
+	}
+	i := int(C.SEALContextChainIndex(c.ptr, p.ptr))
+	runtime.KeepAlive(c)
+	runtime.KeepAlive(p)
+	if i < 0 {
+		return 0, &Error{
+			Op:      "Context.ChainIndex",
+			Message: "parms_id is not valid for encryption parameters",
+			Err:     ErrParmsIDMismatch,
+		}
+	}
+	return i, nil
+}
+
+// CoeffModulus returns the primes of the coefficient modulus at p, with the
+// prime the next rescale divides by last.
+func (c *Context) CoeffModulus(p *ParmsID) ([]uint64, error) {
+	if err := checkOpen("Context.CoeffModulus", c); err != nil {
+		return nil, err
+	}
+	if err := checkParmsID("Context.CoeffModulus", p); err != nil {
+		return nil, err
+	}
+	n := int(C.SEALContextCoeffModulusCount(c.ptr, p.ptr))
+	runtime.KeepAlive(c)
+	runtime.KeepAlive(p)
+	if n == 0 {
+		return nil, &Error{
+			Op:      "Context.CoeffModulus",
+			Message: "parms_id is not valid for encryption parameters",
+			Err:     ErrParmsIDMismatch,
+		}
+	}
+	primes := make([]uint64, n)
+	C.SEALContextCoeffModulus(c.ptr, p.ptr, (*C.uint64_t)(&primes[0]))
+	runtime.KeepAlive(c)
+	runtime.KeepAlive(p)
+	return primes, nil
+}
+
+// Level returns the chain index of a ciphertext under the evaluator's
+// context.
+func (e *Evaluator) Level(a *Ciphertext) (int, error) {
+	if err := checkOpen("Evaluator.Level", e, a); err != nil {
+		return 0, err
+	}
+	p := a.ParmsID()
+	defer p.Close()
+	return e.ctx.ChainIndex(p)
+}
+
+// ModSwitchToNextInplace drops the last prime of a's coefficient modulus
+// without dividing the scale, unlike RescaleToNextInplace.
+func (e *Evaluator) ModSwitchToNextInplace(a *Ciphertext) error {
+	if err := checkOpen("Evaluator.ModSwitchToNextInplace", e, a); err != nil {
+		return err
+	}
+	defer retrack(a)
+	err := checkError("Evaluator.ModSwitchToNextInplace", C.SEALEvaluatorModSwitchToNextInplace(e.ptr, a.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(a)
+	return err
+}
+
+func (e *Evaluator) ModSwitchToNext(a *Ciphertext) (*Ciphertext, error) {
+	a = a.Copy()
+	if err := e.ModSwitchToNextInplace(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// ModSwitchToInplace switches a down to the parameters p, which must not be
+// higher in the chain than a.
+func (e *Evaluator) ModSwitchToInplace(a *Ciphertext, p *ParmsID) error {
+	if err := checkOpen("Evaluator.ModSwitchToInplace", e, a); err != nil {
+		return err
+	}
+	if err := checkParmsID("Evaluator.ModSwitchToInplace", p); err != nil {
+		return err
+	}
+	defer retrack(a)
+	err := checkError("Evaluator.ModSwitchToInplace", C.SEALEvaluatorModSwitchToInplace(e.ptr, a.ptr, p.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(p)
+	return err
+}
+
+func (e *Evaluator) ModSwitchTo(a *Ciphertext, p *ParmsID) (*Ciphertext, error) {
+	a = a.Copy()
+	if err := e.ModSwitchToInplace(a, p); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// ModSwitchToNextPlainInplace switches an NTT-form plaintext to the next
+// parameters in the chain.
+func (e *Evaluator) ModSwitchToNextPlainInplace(a *Plaintext) error {
+	if err := checkOpen("Evaluator.ModSwitchToNextPlainInplace", e, a); err != nil {
+		return err
+	}
+	defer retrack(a)
+	err := checkError("Evaluator.ModSwitchToNextPlainInplace", C.SEALEvaluatorModSwitchToNextPlainInplace(e.ptr, a.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(a)
+	return err
+}
+
+func (e *Evaluator) ModSwitchToPlainInplace(a *Plaintext, p *ParmsID) error {
+	if err := checkOpen("Evaluator.ModSwitchToPlainInplace", e, a); err != nil {
+		return err
+	}
+	if err := checkParmsID("Evaluator.ModSwitchToPlainInplace", p); err != nil {
+		return err
+	}
+	defer retrack(a)
+	err := checkError("Evaluator.ModSwitchToPlainInplace", C.SEALEvaluatorModSwitchToPlainInplace(e.ptr, a.ptr, p.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(p)
+	return err
+}
+
+// MatchLevels mod switches whichever of a and b is higher in the chain down
+// to the other's level. The scales are left unchanged.
+func (e *Evaluator) MatchLevels(a, b *Ciphertext) error {
+	la, err := e.Level(a)
+	if err != nil {
+		return err
+	}
+	lb, err := e.Level(b)
+	if err != nil {
+		return err
+	}
+	switch {
+	case la > lb:
+		p := b.ParmsID()
+		defer p.Close()
+		return e.ModSwitchToInplace(a, p)
+	case lb > la:
+		p := a.ParmsID()
+		defer p.Close()
+		return e.ModSwitchToInplace(b, p)
+	}
+	return nil
+}
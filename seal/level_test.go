@@ -0,0 +1,145 @@
+package seal
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestModSwitchKeepsScale(t *testing.T) {
+	k := newCKKSKit(t)
+	p, err := k.enc.EncodeVector([]float64{1.5, -2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	top, err := k.eval.Level(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if top == 0 {
+		t.Fatal("fresh ciphertext is already at the last level")
+	}
+
+	b, err := k.eval.ModSwitchToNext(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := k.eval.Level(b); err != nil || got != top-1 {
+		t.Fatalf("Level() after ModSwitchToNext = %d, %v; want %d", got, err, top-1)
+	}
+	if b.Scale() != a.Scale() {
+		t.Errorf("Scale() = %g; want %g", b.Scale(), a.Scale())
+	}
+	out := decryptVector(t, k, b)
+	if math.Abs(out[0]-1.5) > 1e-4 || math.Abs(out[1]+2) > 1e-4 {
+		t.Errorf("decrypted %v; want [1.5 -2 ...]", out[:2])
+	}
+
+	last := k.ctx.LastParmsID()
+	if err := k.eval.ModSwitchToInplace(a, last); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := k.eval.Level(a); got != 0 {
+		t.Errorf("Level() after ModSwitchTo(last) = %d; want 0", got)
+	}
+	if err := k.eval.ModSwitchToNextInplace(a); !errors.Is(err, ErrEndOfModulusChain) {
+		t.Errorf("ModSwitchToNextInplace at the last level = %v; want ErrEndOfModulusChain", err)
+	}
+
+	// b is now above a; MatchLevels brings it down.
+	if err := k.eval.MatchLevels(a, b); err != nil {
+		t.Fatal(err)
+	}
+	if !a.ParmsID().Eq(b.ParmsID()) {
+		t.Error("MatchLevels left the operands at different levels")
+	}
+}
+
+func TestRescaleTo(t *testing.T) {
+	k := newCKKSKit(t)
+	p, err := k.enc.Encode(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	relin, err := k.keygen.RelinKeys(60, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := k.eval.SquareInplace(a); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.eval.RelinearizeInplace(a, relin); err != nil {
+		t.Fatal(err)
+	}
+	next, err := k.eval.ModSwitchToNext(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := k.eval.RescaleToInplace(a, next.ParmsID()); err != nil {
+		t.Fatal(err)
+	}
+	if !a.ParmsID().Eq(next.ParmsID()) {
+		t.Error("RescaleToInplace did not reach the requested parameters")
+	}
+	if a.Scale() >= next.Scale() {
+		t.Errorf("Scale() after rescale = %g; want below %g", a.Scale(), next.Scale())
+	}
+	if out := decryptVector(t, k, a); math.Abs(out[0]-9) > 1e-3 {
+		t.Errorf("decrypted %f; want 9", out[0])
+	}
+}
+
+func TestPlaintextModSwitch(t *testing.T) {
+	k := newCKKSKit(t)
+	p, err := k.enc.Encode(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := k.ctx.LastParmsID()
+	if err := k.eval.ModSwitchToPlainInplace(p, last); err != nil {
+		t.Fatal(err)
+	}
+	if !p.ParmsID().Eq(last) {
+		t.Error("plaintext is not at the last parameters")
+	}
+	other := newBFVKit(t)
+	if _, err := k.ctx.ChainIndex(other.ctx.FirstParmsID()); !errors.Is(err, ErrParmsIDMismatch) {
+		t.Errorf("ChainIndex(unknown) = %v; want ErrParmsIDMismatch", err)
+	}
+}
+
+func TestUnsetParmsID(t *testing.T) {
+	k := newCKKSKit(t)
+	p, err := k.enc.Encode(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := k.ctx.LastParmsID()
+	closed.Close()
+	for _, id := range []*ParmsID{nil, {}, closed} {
+		if _, err := k.ctx.ChainIndex(id); !errors.Is(err, ErrParmsIDMismatch) {
+			t.Errorf("ChainIndex(%v) = %v; want ErrParmsIDMismatch", id, err)
+		}
+		if _, err := k.ctx.CoeffModulus(id); !errors.Is(err, ErrParmsIDMismatch) {
+			t.Errorf("CoeffModulus(%v) = %v; want ErrParmsIDMismatch", id, err)
+		}
+		if err := k.eval.ModSwitchToInplace(a, id); !errors.Is(err, ErrParmsIDMismatch) {
+			t.Errorf("ModSwitchToInplace(%v) = %v; want ErrParmsIDMismatch", id, err)
+		}
+		if err := k.eval.RescaleToInplace(a, id); !errors.Is(err, ErrParmsIDMismatch) {
+			t.Errorf("RescaleToInplace(%v) = %v; want ErrParmsIDMismatch", id, err)
+		}
+	}
+}
@@ -0,0 +1,550 @@
+package seal
+
+// #include "seal.h"
+import "C"
+
+import (
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	liveBytes   int64
+	liveHandles int64
+)
+
+// LiveNativeBytes returns the approximate number of bytes of ciphertext,
+// plaintext and key data held by handles that have not been freed.
+func LiveNativeBytes() int64 {
+	return atomic.LoadInt64(&liveBytes)
+}
+
+// LiveHandles returns the number of handles that have not been freed.
+func LiveHandles() int64 {
+	return atomic.LoadInt64(&liveHandles)
+}
+
+// native is embedded in every handle and records the bytes it was charged.
+type native struct {
+	bytes int64
+}
+
+func (n *native) account(bytes int64) {
+	old := atomic.SwapInt64(&n.bytes, bytes)
+	atomic.AddInt64(&liveBytes, bytes-old)
+}
+
+func (n *native) accounting() *native {
+	return n
+}
+
+// Handle is implemented by every type that wraps a native SEAL object. The
+// object is C++ memory the Go garbage collector cannot see: Close frees it
+// immediately; otherwise a finalizer frees it once the handle is
+// unreachable. Methods keep their handles alive until SEAL returns, so a
+// finalizer cannot free an object during a call. LiveNativeBytes reports
+// how much coefficient data is held by handles that have not been freed
+// yet. Methods of a closed handle, or methods given one, return ErrClosed;
+// accessors without an error result return zero values.
+type Handle interface {
+	io.Closer
+	accounting() *native
+	// closed reports whether the handle has no native object.
+	closed() bool
+}
+
+// checkOpen returns ErrClosed if any of the handles is closed, so that no
+// nil pointer reaches SEAL.
+func checkOpen(op string, handles ...Handle) error {
+	for _, h := range handles {
+		if h.closed() {
+			return &Error{Op: op, Message: "handle is closed", Err: ErrClosed}
+		}
+	}
+	return nil
+}
+
+// sized is implemented by handles whose native size is worth tracking.
+type sized interface {
+	byteCount() int64
+}
+
+// track charges a newly allocated handle.
+func track(h Handle) {
+	atomic.AddInt64(&liveHandles, 1)
+	retrack(h)
+}
+
+// retrack updates the charge for a handle whose native object changed size.
+func retrack(h Handle) {
+	if s, ok := h.(sized); ok && !h.closed() {
+		h.accounting().account(s.byteCount())
+	}
+}
+
+func untrack(h Handle) {
+	atomic.AddInt64(&liveHandles, -1)
+	h.accounting().account(0)
+}
+
+// Scope is an arena that frees the handles tracked in it together. Handles
+// join a scope only through Track, so a scope never frees handles another
+// goroutine allocated.
+type Scope struct {
+	mu      sync.Mutex
+	handles []Handle
+	closed  bool
+}
+
+// NewScope returns an empty scope.
+func NewScope() *Scope {
+	return &Scope{}
+}
+
+// Track adds handles to s. Handles tracked by a closed scope are freed at
+// once.
+func (s *Scope) Track(handles ...Handle) {
+	s.mu.Lock()
+	if !s.closed {
+		s.handles = append(s.handles, handles...)
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+	for _, h := range handles {
+		h.Close()
+	}
+}
+
+// Keep removes handles from s so they survive s.Close.
+func (s *Scope) Keep(handles ...Handle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, h := range handles {
+		for i, o := range s.handles {
+			if o == h {
+				s.handles = append(s.handles[:i], s.handles[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Close frees the handles tracked by s, newest first. It is safe to call
+// more than once.
+func (s *Scope) Close() error {
+	s.mu.Lock()
+	handles := s.handles
+	s.handles, s.closed = nil, true
+	s.mu.Unlock()
+
+	for i := len(handles) - 1; i >= 0; i-- {
+		handles[i].Close()
+	}
+	return nil
+}
+
+// Do runs f with a new scope and closes it when f returns.
+func Do(f func(s *Scope) error) error {
+	s := NewScope()
+	defer s.Close()
+	return f(s)
+}
+
+// Close frees the native objects of every handle type. It may be called more
+// than once; methods of the handle return ErrClosed afterwards.
+
+func (e *EncryptionParams) Close() error {
+	runtime.SetFinalizer(e, nil)
+	e.free()
+	return nil
+}
+
+func (e *EncryptionParams) closed() bool {
+	return e == nil || e.ptr == nil
+}
+
+func (e *EncryptionParams) free() {
+	if e.ptr == nil {
+		return
+	}
+	C.SEALEncryptionParametersDelete(e.ptr)
+	e.ptr = nil
+	untrack(e)
+}
+
+func (c *Context) Close() error {
+	runtime.SetFinalizer(c, nil)
+	c.free()
+	return nil
+}
+
+func (c *Context) closed() bool {
+	return c == nil || c.ptr == nil
+}
+
+func (c *Context) free() {
+	if c.ptr == nil {
+		return
+	}
+	C.SEALContextDelete(c.ptr)
+	c.ptr = nil
+	untrack(c)
+}
+
+func (k *KeyGenerator) Close() error {
+	runtime.SetFinalizer(k, nil)
+	k.free()
+	return nil
+}
+
+func (k *KeyGenerator) closed() bool {
+	return k == nil || k.ptr == nil
+}
+
+func (k *KeyGenerator) free() {
+	if k.ptr == nil {
+		return
+	}
+	C.SEALKeyGeneratorDelete(k.ptr)
+	k.ptr = nil
+	untrack(k)
+}
+
+func (p *PublicKey) Close() error {
+	runtime.SetFinalizer(p, nil)
+	p.free()
+	return nil
+}
+
+func (p *PublicKey) closed() bool {
+	return p == nil || p.ptr == nil
+}
+
+func (p *PublicKey) free() {
+	if p.ptr == nil {
+		return
+	}
+	C.SEALPublicKeyDelete(p.ptr)
+	p.ptr = nil
+	untrack(p)
+}
+
+func (s *SecretKey) Close() error {
+	runtime.SetFinalizer(s, nil)
+	s.free()
+	return nil
+}
+
+func (s *SecretKey) closed() bool {
+	return s == nil || s.ptr == nil
+}
+
+func (s *SecretKey) free() {
+	if s.ptr == nil {
+		return
+	}
+	C.SEALSecretKeyDelete(s.ptr)
+	s.ptr = nil
+	untrack(s)
+}
+
+func (r *RelinKeys) Close() error {
+	runtime.SetFinalizer(r, nil)
+	r.free()
+	return nil
+}
+
+func (r *RelinKeys) closed() bool {
+	return r == nil || r.ptr == nil
+}
+
+func (r *RelinKeys) free() {
+	if r.ptr == nil {
+		return
+	}
+	C.SEALRelinKeysDelete(r.ptr)
+	r.ptr = nil
+	untrack(r)
+}
+
+func (g *GaloisKeys) Close() error {
+	runtime.SetFinalizer(g, nil)
+	g.free()
+	return nil
+}
+
+func (g *GaloisKeys) closed() bool {
+	return g == nil || g.ptr == nil
+}
+
+func (g *GaloisKeys) free() {
+	if g.ptr == nil {
+		return
+	}
+	C.SEALGaloisKeysDelete(g.ptr)
+	g.ptr = nil
+	untrack(g)
+}
+
+func (e *Encryptor) Close() error {
+	runtime.SetFinalizer(e, nil)
+	e.free()
+	return nil
+}
+
+func (e *Encryptor) closed() bool {
+	return e == nil || e.ptr == nil
+}
+
+func (e *Encryptor) free() {
+	if e.ptr == nil {
+		return
+	}
+	C.SEALEncryptorDelete(e.ptr)
+	e.ptr = nil
+	untrack(e)
+}
+
+func (e *Evaluator) Close() error {
+	runtime.SetFinalizer(e, nil)
+	e.free()
+	return nil
+}
+
+func (e *Evaluator) closed() bool {
+	return e == nil || e.ptr == nil
+}
+
+func (e *Evaluator) free() {
+	if e.ptr == nil {
+		return
+	}
+	C.SEALEvaluatorDelete(e.ptr)
+	e.ptr = nil
+	untrack(e)
+}
+
+func (d *Decryptor) Close() error {
+	runtime.SetFinalizer(d, nil)
+	d.free()
+	return nil
+}
+
+func (d *Decryptor) closed() bool {
+	return d == nil || d.ptr == nil
+}
+
+func (d *Decryptor) free() {
+	if d.ptr == nil {
+		return
+	}
+	C.SEALDecryptorDelete(d.ptr)
+	d.ptr = nil
+	untrack(d)
+}
+
+func (b *BinaryFractionalEncoder) Close() error {
+	runtime.SetFinalizer(b, nil)
+	b.free()
+	return nil
+}
+
+func (b *BinaryFractionalEncoder) closed() bool {
+	return b == nil || b.ptr == nil
+}
+
+func (b *BinaryFractionalEncoder) free() {
+	if b.ptr == nil {
+		return
+	}
+	C.SEALBinaryFractionalEncoderDelete(b.ptr)
+	b.ptr = nil
+	untrack(b)
+}
+
+func (c *CKKSEncoder) Close() error {
+	runtime.SetFinalizer(c, nil)
+	c.free()
+	return nil
+}
+
+func (c *CKKSEncoder) closed() bool {
+	return c == nil || c.ptr == nil
+}
+
+func (c *CKKSEncoder) free() {
+	if c.ptr == nil {
+		return
+	}
+	C.SEALCKKSEncoderDelete(c.ptr)
+	c.ptr = nil
+	untrack(c)
+}
+
+func (b *BatchEncoder) Close() error {
+	runtime.SetFinalizer(b, nil)
+	b.free()
+	return nil
+}
+
+func (b *BatchEncoder) closed() bool {
+	return b == nil || b.ptr == nil
+}
+
+func (b *BatchEncoder) free() {
+	if b.ptr == nil {
+		return
+	}
+	C.SEALBatchEncoderDelete(b.ptr)
+	b.ptr = nil
+	untrack(b)
+}
+
+func (p *Plaintext) Close() error {
+	runtime.SetFinalizer(p, nil)
+	p.free()
+	return nil
+}
+
+func (p *Plaintext) closed() bool {
+	return p == nil || p.ptr == nil
+}
+
+func (p *Plaintext) free() {
+	if p.ptr == nil {
+		return
+	}
+	C.SEALPlaintextDelete(p.ptr)
+	p.ptr = nil
+	untrack(p)
+}
+
+func (c *Ciphertext) Close() error {
+	runtime.SetFinalizer(c, nil)
+	c.free()
+	return nil
+}
+
+func (c *Ciphertext) closed() bool {
+	return c == nil || c.ptr == nil
+}
+
+func (c *Ciphertext) free() {
+	if c.ptr == nil {
+		return
+	}
+	C.SEALCiphertextDelete(c.ptr)
+	c.ptr = nil
+	untrack(c)
+}
+
+func (p *ParmsID) Close() error {
+	runtime.SetFinalizer(p, nil)
+	p.free()
+	return nil
+}
+
+func (p *ParmsID) closed() bool {
+	return p == nil || p.ptr == nil
+}
+
+func (p *ParmsID) free() {
+	if p.ptr == nil {
+		return
+	}
+	C.SEALParmsIDDelete(p.ptr)
+	p.ptr = nil
+	untrack(p)
+}
+
+func (s *SymmetricEncryptor) Close() error {
+	runtime.SetFinalizer(s, nil)
+	s.free()
+	return nil
+}
+
+func (s *SymmetricEncryptor) closed() bool {
+	return s == nil || s.ptr == nil
+}
+
+func (s *SymmetricEncryptor) free() {
+	if s.ptr == nil {
+		return
+	}
+	C.SEALSymmetricEncryptorDelete(s.ptr)
+	s.ptr = nil
+	untrack(s)
+}
+
+func (s *SeededCiphertext) Close() error {
+	runtime.SetFinalizer(s, nil)
+	s.free()
+	return nil
+}
+
+func (s *SeededCiphertext) closed() bool {
+	return s == nil || s.ptr == nil
+}
+
+func (s *SeededCiphertext) free() {
+	if s.ptr == nil {
+		return
+	}
+	C.SEALSeededCiphertextDelete(s.ptr)
+	s.ptr = nil
+	untrack(s)
+}
+
+func (c *Ciphertext) byteCount() int64 {
+	n := int64(C.SEALCiphertextByteCount(c.ptr))
+	runtime.KeepAlive(c)
+	return n
+}
+
+func (p *Plaintext) byteCount() int64 {
+	n := int64(C.SEALPlaintextByteCount(p.ptr))
+	runtime.KeepAlive(p)
+	return n
+}
+
+func (k *PublicKey) byteCount() int64 {
+	n := int64(C.SEALPublicKeyByteCount(k.ptr))
+	runtime.KeepAlive(k)
+	return n
+}
+
+func (k *SecretKey) byteCount() int64 {
+	n := int64(C.SEALSecretKeyByteCount(k.ptr))
+	runtime.KeepAlive(k)
+	return n
+}
+
+func (k *RelinKeys) byteCount() int64 {
+	n := int64(C.SEALRelinKeysByteCount(k.ptr))
+	runtime.KeepAlive(k)
+	return n
+}
+
+func (k *GaloisKeys) byteCount() int64 {
+	n := int64(C.SEALGaloisKeysByteCount(k.ptr))
+	runtime.KeepAlive(k)
+	return n
+}
+
+func (s *SeededCiphertext) byteCount() int64 {
+	n := int64(C.SEALSeededCiphertextByteCount(s.ptr))
+	runtime.KeepAlive(s)
+	return n
+}
+
+// cptr returns the native parameters of p, or nil for an unset p, which
+// encoders read as the first parameters.
+func (p *ParmsID) cptr() C.SEALParmsID {
+	if p == nil {
+		return nil
+	}
+	return p.ptr
+}
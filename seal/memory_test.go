@@ -0,0 +1,133 @@
+package seal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCloseReleasesAccounting(t *testing.T) {
+	k := newCKKSKit(t)
+	p, err := k.enc.Encode(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handles, bytes := LiveHandles(), LiveNativeBytes()
+	a, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := LiveHandles(); got != handles+1 {
+		t.Errorf("LiveHandles() = %d; want %d", got, handles+1)
+	}
+	// Two polynomials per prime of the coefficient modulus.
+	poly := int64(2 * k.ctx.PolyModulusDegree() * 8)
+	if got := LiveNativeBytes() - bytes; got <= 0 || got%poly != 0 {
+		t.Errorf("ciphertext charged %d bytes; want a positive multiple of %d", got, poly)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := LiveHandles(); got != handles {
+		t.Errorf("LiveHandles() after Close = %d; want %d", got, handles)
+	}
+	if got := LiveNativeBytes(); got != bytes {
+		t.Errorf("LiveNativeBytes() after Close = %d; want %d", got, bytes)
+	}
+}
+
+func TestScope(t *testing.T) {
+	k := newCKKSKit(t)
+	p, err := k.enc.Encode(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handles := LiveHandles()
+
+	var kept, dropped *Ciphertext
+	err = Do(func(s *Scope) error {
+		var err error
+		if dropped, err = k.encryptor.Encrypt(p); err != nil {
+			return err
+		}
+		if kept, err = k.eval.Add(dropped, dropped); err != nil {
+			return err
+		}
+		s.Track(dropped, kept)
+		s.Keep(kept)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dropped.closed() {
+		t.Error("scope did not free its handles")
+	}
+	if got := LiveHandles(); got != handles+1 {
+		t.Errorf("LiveHandles() = %d; want %d", got, handles+1)
+	}
+	out := decryptVector(t, k, kept)
+	if out[0] < 5.99 || out[0] > 6.01 {
+		t.Errorf("kept ciphertext decrypts to %f; want 6", out[0])
+	}
+
+	// Handles allocated outside a scope are never captured by it.
+	s := NewScope()
+	other, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if other.closed() {
+		t.Error("Close freed a handle it did not track")
+	}
+	s.Track(kept)
+	if !kept.closed() {
+		t.Error("Track on a closed scope did not free the handle")
+	}
+}
+
+func TestClosedHandle(t *testing.T) {
+	k := newCKKSKit(t)
+	p, err := k.enc.Encode(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Close()
+	if _, err := k.eval.Add(a, a); !errors.Is(err, ErrClosed) {
+		t.Errorf("Add(closed) = %v; want ErrClosed", err)
+	}
+	if _, err := k.decryptor.Decrypt(a); !errors.Is(err, ErrClosed) {
+		t.Errorf("Decrypt(closed) = %v; want ErrClosed", err)
+	}
+	if _, err := a.MarshalBinary(); !errors.Is(err, ErrClosed) {
+		t.Errorf("MarshalBinary(closed) = %v; want ErrClosed", err)
+	}
+	if got := a.Scale(); got != 0 {
+		t.Errorf("Scale(closed) = %g; want 0", got)
+	}
+	if !a.Copy().closed() {
+		t.Error("Copy(closed) is open")
+	}
+
+	var zero Ciphertext
+	if _, err := k.eval.Square(&zero); !errors.Is(err, ErrClosed) {
+		t.Errorf("Square(zero Ciphertext) = %v; want ErrClosed", err)
+	}
+	enc, err := NewCKKSEncoder(k.ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc.Close()
+	if _, err := enc.DecodeVector(p); !errors.Is(err, ErrClosed) {
+		t.Errorf("DecodeVector on closed encoder = %v; want ErrClosed", err)
+	}
+}
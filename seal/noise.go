@@ -0,0 +1,146 @@
+package seal
+
+// #include "seal.h"
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+)
+
+// ErrPrecisionLost is returned by PrecisionEstimator.Check when a decrypted
+// result is further from its reference than allowed.
+var ErrPrecisionLost = errors.New("seal: precision lost")
+
+// InvariantNoiseBudget returns the bits of noise budget left in a BFV
+// ciphertext. It decrypts correctly while the budget is positive.
+func (d *Decryptor) InvariantNoiseBudget(c *Ciphertext) (int, error) {
+	if err := checkOpen("Decryptor.InvariantNoiseBudget", d, c); err != nil {
+		return 0, err
+	}
+	var out C.int
+	err := checkError("Decryptor.InvariantNoiseBudget", C.SEALDecryptorInvariantNoiseBudget(d.ptr, c.ptr, &out))
+	runtime.KeepAlive(d)
+	runtime.KeepAlive(c)
+	if err != nil {
+		return 0, err
+	}
+	return int(out), nil
+}
+
+// ScaleReport describes how much room a CKKS ciphertext has left.
+type ScaleReport struct {
+	// Level is the number of rescales left before the end of the chain.
+	Level int
+	// ScaleBits is log2 of the ciphertext's scale.
+	ScaleBits float64
+	// ModulusBits is the size of the coefficient modulus at the ciphertext's
+	// level.
+	ModulusBits float64
+	// RemainingBits is the room between the scale and the modulus, which
+	// must hold the integer part of the values. Results are garbage once it
+	// is used up.
+	RemainingBits float64
+}
+
+func (r ScaleReport) String() string {
+	return fmt.Sprintf("level %d, scale 2^%.1f, modulus 2^%.1f, %.1f bits remaining",
+		r.Level, r.ScaleBits, r.ModulusBits, r.RemainingBits)
+}
+
+// ScaleReport reports the level and scale headroom of a under the
+// evaluator's context.
+func (e *Evaluator) ScaleReport(a *Ciphertext) (ScaleReport, error) {
+	id := a.ParmsID()
+	defer id.Close()
+	level, err := e.ctx.ChainIndex(id)
+	if err != nil {
+		return ScaleReport{}, err
+	}
+	primes, err := e.ctx.CoeffModulus(id)
+	if err != nil {
+		return ScaleReport{}, err
+	}
+	var modulus float64
+	for _, q := range primes {
+		modulus += math.Log2(float64(q))
+	}
+	scale := math.Log2(a.Scale())
+	return ScaleReport{
+		Level:         level,
+		ScaleBits:     scale,
+		ModulusBits:   modulus,
+		RemainingBits: modulus - scale,
+	}, nil
+}
+
+// Precision compares a decrypted CKKS result with reference values.
+type Precision struct {
+	MaxError  float64
+	MeanError float64
+	// Bits is -log2(MaxError), the number of correct fractional bits.
+	Bits float64
+}
+
+// PrecisionEstimator decrypts ciphertexts to measure their error against
+// known reference values. It needs the secret key, so it is meant for tests
+// and for data owners monitoring their own circuits.
+type PrecisionEstimator struct {
+	Decryptor *Decryptor
+	Encoder   *CKKSEncoder
+	// MinBits is the precision Check requires.
+	MinBits float64
+}
+
+// Estimate decrypts a and compares its first len(want) slots with want.
+func (p *PrecisionEstimator) Estimate(a *Ciphertext, want []float64) (Precision, error) {
+	plain, err := p.Decryptor.Decrypt(a)
+	if err != nil {
+		return Precision{}, err
+	}
+	defer plain.Close()
+	got, err := p.Encoder.DecodeVector(plain)
+	if err != nil {
+		return Precision{}, err
+	}
+	if len(want) > len(got) {
+		return Precision{}, &Error{
+			Op:      "PrecisionEstimator.Estimate",
+			Message: fmt.Sprintf("%d reference values for %d slots", len(want), len(got)),
+			Err:     ErrInvalidArgument,
+		}
+	}
+	var r Precision
+	for i, w := range want {
+		d := math.Abs(got[i] - w)
+		if math.IsNaN(d) {
+			d = math.Inf(1)
+		}
+		r.MaxError = math.Max(r.MaxError, d)
+		r.MeanError += d
+	}
+	if len(want) > 0 {
+		r.MeanError /= float64(len(want))
+	}
+	r.Bits = -math.Log2(r.MaxError)
+	return r, nil
+}
+
+// Check returns ErrPrecisionLost if a has fewer than MinBits of precision
+// against want.
+func (p *PrecisionEstimator) Check(a *Ciphertext, want []float64) error {
+	r, err := p.Estimate(a, want)
+	if err != nil {
+		return err
+	}
+	if r.Bits < p.MinBits {
+		return &Error{
+			Op:      "PrecisionEstimator.Check",
+			Message: fmt.Sprintf("%.1f bits of precision, want %.1f (max error %g)", r.Bits, p.MinBits, r.MaxError),
+			Err:     ErrPrecisionLost,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package seal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInvariantNoiseBudget(t *testing.T) {
+	k := newBFVKit(t)
+	p, err := k.batch.EncodeUint64([]uint64{3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := k.decryptor.InvariantNoiseBudget(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh <= 0 {
+		t.Fatalf("fresh noise budget = %d; want > 0", fresh)
+	}
+	if err := k.eval.SquareInplace(a); err != nil {
+		t.Fatal(err)
+	}
+	squared, err := k.decryptor.InvariantNoiseBudget(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if squared >= fresh {
+		t.Errorf("noise budget after squaring = %d; want below %d", squared, fresh)
+	}
+
+	c := newCKKSKit(t)
+	cp, err := c.enc.Encode(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := c.encryptor.Encrypt(cp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.decryptor.InvariantNoiseBudget(ca); err == nil {
+		t.Error("InvariantNoiseBudget(CKKS) succeeded; want an error")
+	}
+}
+
+func TestScaleReportAndPrecision(t *testing.T) {
+	k, auto := newAutoKit(t)
+	p, err := k.enc.EncodeVector([]float64{1.5, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := k.eval.ScaleReport(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before.ScaleBits != 60 || before.RemainingBits <= 0 {
+		t.Errorf("fresh report = %v", before)
+	}
+	b, err := auto.Square(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := k.eval.ScaleReport(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Level >= before.Level || after.RemainingBits >= before.RemainingBits {
+		t.Errorf("report after squaring = %v; before %v", after, before)
+	}
+
+	est := &PrecisionEstimator{Decryptor: k.decryptor, Encoder: k.enc, MinBits: 10}
+	if err := est.Check(b, []float64{2.25, 4}); err != nil {
+		t.Error(err)
+	}
+	if err := est.Check(b, []float64{2.25, 5}); !errors.Is(err, ErrPrecisionLost) {
+		t.Errorf("Check(wrong reference) = %v; want ErrPrecisionLost", err)
+	}
+}
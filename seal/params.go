@@ -0,0 +1,321 @@
+package seal
+
+// #include "seal.h"
+import "C"
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"runtime"
+)
+
+// Scheme selects the homomorphic encryption scheme.
+type Scheme int
+
+const (
+	SchemeBFV  Scheme = C.SEAL_SCHEME_BFV
+	SchemeCKKS Scheme = C.SEAL_SCHEME_CKKS
+)
+
+func (s Scheme) String() string {
+	switch s {
+	case SchemeBFV:
+		return "BFV"
+	case SchemeCKKS:
+		return "CKKS"
+	}
+	return fmt.Sprintf("Scheme(%d)", int(s))
+}
+
+// SecurityLevel is a security level from the HomomorphicEncryption.org
+// standard. SecurityNone disables the check in NewContext.
+type SecurityLevel int
+
+const (
+	SecurityNone SecurityLevel = 0
+	Security128  SecurityLevel = C.SEAL_SECURITY_128
+	Security192  SecurityLevel = C.SEAL_SECURITY_192
+	Security256  SecurityLevel = C.SEAL_SECURITY_256
+)
+
+func (l SecurityLevel) String() string {
+	if l == SecurityNone {
+		return "none"
+	}
+	return fmt.Sprintf("%d-bit", int(l))
+}
+
+// maxCoeffModulusBits is the largest total coefficient modulus, in bits, that
+// keeps each poly modulus degree at a given security level.
+var maxCoeffModulusBits = map[SecurityLevel]map[int]int{
+	Security128: {1024: 27, 2048: 54, 4096: 109, 8192: 218, 16384: 438, 32768: 881},
+	Security192: {1024: 19, 2048: 37, 4096: 75, 8192: 152, 16384: 305, 32768: 611},
+	Security256: {1024: 14, 2048: 29, 4096: 58, 8192: 118, 16384: 237, 32768: 476},
+}
+
+// securityOf returns the highest level a coefficient modulus of totalBits
+// achieves at the given degree.
+func securityOf(degree, totalBits int) SecurityLevel {
+	for _, l := range []SecurityLevel{Security256, Security192, Security128} {
+		if max, ok := maxCoeffModulusBits[l][degree]; ok && totalBits <= max {
+			return l
+		}
+	}
+	return SecurityNone
+}
+
+// NewEncryptionParams returns empty parameters for scheme. Set the poly
+// modulus degree first, then the coefficient modulus and, for BFV, the plain
+// modulus.
+func NewEncryptionParams(scheme Scheme) (*EncryptionParams, error) {
+	var ptr C.SEALEncryptionParameters
+	if err := checkError("NewEncryptionParams", C.SEALEncryptionParametersInit(C.int(scheme), &ptr)); err != nil {
+		return nil, err
+	}
+	return newEncryptionParams(ptr), nil
+}
+
+func (p *EncryptionParams) Scheme() Scheme {
+	if p.closed() {
+		return 0
+	}
+	s := Scheme(C.SEALEncryptionParametersScheme(p.ptr))
+	runtime.KeepAlive(p)
+	return s
+}
+
+func (p *EncryptionParams) PolyModulusDegree() int {
+	if p.closed() {
+		return 0
+	}
+	n := int(C.SEALEncryptionParametersPolyModulusDegree(p.ptr))
+	runtime.KeepAlive(p)
+	return n
+}
+
+// CoeffModulus returns the primes making up the coefficient modulus.
+func (p *EncryptionParams) CoeffModulus() []uint64 {
+	if p.closed() {
+		return nil
+	}
+	n := int(C.SEALEncryptionParametersCoeffModulusCount(p.ptr))
+	runtime.KeepAlive(p)
+	if n == 0 {
+		return nil
+	}
+	primes := make([]uint64, n)
+	C.SEALEncryptionParametersCoeffModulus(p.ptr, (*C.uint64_t)(&primes[0]))
+	runtime.KeepAlive(p)
+	return primes
+}
+
+func (p *EncryptionParams) PlainModulus() uint64 {
+	if p.closed() {
+		return 0
+	}
+	n := uint64(C.SEALEncryptionParametersPlainModulus(p.ptr))
+	runtime.KeepAlive(p)
+	return n
+}
+
+func (p *EncryptionParams) SecurityLevel() SecurityLevel {
+	return p.security
+}
+
+func (p *EncryptionParams) SetPolyModulusDegree(degree int) error {
+	if err := checkOpen("EncryptionParams.SetPolyModulusDegree", p); err != nil {
+		return err
+	}
+	if degree <= 0 || degree&(degree-1) != 0 {
+		return &Error{
+			Op:      "EncryptionParams.SetPolyModulusDegree",
+			Message: fmt.Sprintf("poly modulus degree %d is not a power of two", degree),
+			Err:     ErrInvalidParameters,
+		}
+	}
+	err := checkError("EncryptionParams.SetPolyModulusDegree", C.SEALEncryptionParametersSetPolyModulusDegree(p.ptr, C.uint64_t(degree)))
+	runtime.KeepAlive(p)
+	return err
+}
+
+// SetCoeffModulus sets the coefficient modulus to distinct NTT-friendly
+// primes with the given bit sizes, in order. The poly modulus degree must
+// already be set.
+func (p *EncryptionParams) SetCoeffModulus(bitSizes []int) error {
+	if err := checkOpen("EncryptionParams.SetCoeffModulus", p); err != nil {
+		return err
+	}
+	primes, err := coeffModulusPrimes(p.PolyModulusDegree(), bitSizes)
+	if err != nil {
+		return &Error{
+			Op:      "EncryptionParams.SetCoeffModulus",
+			Message: err.Error(),
+			Err:     ErrInvalidParameters,
+		}
+	}
+	return p.SetCoeffModulusPrimes(primes)
+}
+
+// SetCoeffModulusPrimes sets the coefficient modulus to explicit primes.
+func (p *EncryptionParams) SetCoeffModulusPrimes(primes []uint64) error {
+	if err := checkOpen("EncryptionParams.SetCoeffModulusPrimes", p); err != nil {
+		return err
+	}
+	if len(primes) == 0 {
+		return &Error{
+			Op:      "EncryptionParams.SetCoeffModulusPrimes",
+			Message: "coefficient modulus is empty",
+			Err:     ErrInvalidParameters,
+		}
+	}
+	err := checkError("EncryptionParams.SetCoeffModulusPrimes", C.SEALEncryptionParametersSetCoeffModulus(p.ptr, (*C.uint64_t)(&primes[0]), C.size_t(len(primes))))
+	runtime.KeepAlive(p)
+	return err
+}
+
+// SetDefaultCoeffModulus uses SEAL's default coefficient modulus for the
+// current poly modulus degree and security level.
+func (p *EncryptionParams) SetDefaultCoeffModulus() error {
+	if err := checkOpen("EncryptionParams.SetDefaultCoeffModulus", p); err != nil {
+		return err
+	}
+	level := p.security
+	if level == SecurityNone {
+		level = Security128
+	}
+	err := checkError("EncryptionParams.SetDefaultCoeffModulus", C.SEALEncryptionParametersSetDefaultCoeffModulus(p.ptr, C.int(level)))
+	runtime.KeepAlive(p)
+	return err
+}
+
+func (p *EncryptionParams) SetPlainModulus(plainModulus uint64) error {
+	if err := checkOpen("EncryptionParams.SetPlainModulus", p); err != nil {
+		return err
+	}
+	err := checkError("EncryptionParams.SetPlainModulus", C.SEALEncryptionParametersSetPlainModulus(p.ptr, C.uint64_t(plainModulus)))
+	runtime.KeepAlive(p)
+	return err
+}
+
+// SetSecurityLevel sets the level NewContext enforces against the
+// coefficient modulus. The default is Security128.
+func (p *EncryptionParams) SetSecurityLevel(level SecurityLevel) error {
+	switch level {
+	case SecurityNone, Security128, Security192, Security256:
+		p.security = level
+		return nil
+	}
+	return &Error{
+		Op:      "EncryptionParams.SetSecurityLevel",
+		Message: fmt.Sprintf("unsupported security level %d", int(level)),
+		Err:     ErrInvalidArgument,
+	}
+}
+
+// coeffModulusPrimes returns distinct primes of the requested bit sizes that
+// are congruent to 1 mod 2*degree, as required for the NTT.
+func coeffModulusPrimes(degree int, bitSizes []int) ([]uint64, error) {
+	if degree <= 0 {
+		return nil, fmt.Errorf("poly modulus degree must be set before the coefficient modulus")
+	}
+	step := uint64(2 * degree)
+	next := map[int]uint64{}
+	primes := make([]uint64, len(bitSizes))
+	for i, size := range bitSizes {
+		if size > 60 || size <= bits.Len64(step) {
+			return nil, fmt.Errorf("coefficient modulus bit size %d out of range for degree %d", size, degree)
+		}
+		candidate, ok := next[size]
+		if !ok {
+			candidate = uint64(1)<<uint(size) - step + 1
+		}
+		low := uint64(1) << uint(size-1)
+		for candidate > low && !new(big.Int).SetUint64(candidate).ProbablyPrime(20) {
+			candidate -= step
+		}
+		if candidate <= low {
+			return nil, fmt.Errorf("not enough %d-bit primes for degree %d", size, degree)
+		}
+		primes[i] = candidate
+		next[size] = candidate - step
+	}
+	return primes, nil
+}
+
+// Qualifiers reports which optimizations SEAL enabled for a Context and
+// whether its parameters are usable at all.
+type Qualifiers struct {
+	ParametersSet       bool
+	EnableFFT           bool
+	EnableNTT           bool
+	EnableBatching      bool
+	EnableFastPlainLift bool
+	// Security is the highest standard level the coefficient modulus meets.
+	Security SecurityLevel
+}
+
+func (c *Context) Qualifiers() Qualifiers {
+	if c.closed() {
+		return Qualifiers{}
+	}
+	q := C.SEALContextQualifiers(c.ptr)
+	runtime.KeepAlive(c)
+	return Qualifiers{
+		ParametersSet:       q.parameters_set != 0,
+		EnableFFT:           q.enable_fft != 0,
+		EnableNTT:           q.enable_ntt != 0,
+		EnableBatching:      q.enable_batching != 0,
+		EnableFastPlainLift: q.enable_fast_plain_lift != 0,
+		Security:            securityOf(c.PolyModulusDegree(), c.TotalCoeffModulusBitCount()),
+	}
+}
+
+func (c *Context) Scheme() Scheme {
+	if c.closed() {
+		return 0
+	}
+	s := Scheme(C.SEALContextScheme(c.ptr))
+	runtime.KeepAlive(c)
+	return s
+}
+
+func (c *Context) PolyModulusDegree() int {
+	if c.closed() {
+		return 0
+	}
+	n := int(C.SEALContextPolyModulusDegree(c.ptr))
+	runtime.KeepAlive(c)
+	return n
+}
+
+func (c *Context) TotalCoeffModulusBitCount() int {
+	if c.closed() {
+		return 0
+	}
+	n := int(C.SEALContextTotalCoeffModulusBitCount(c.ptr))
+	runtime.KeepAlive(c)
+	return n
+}
+
+// validate rejects contexts SEAL could not set up and contexts weaker than
+// the requested security level.
+func (c *Context) validate(level SecurityLevel) error {
+	q := c.Qualifiers()
+	if !q.ParametersSet {
+		return &Error{
+			Op:      "NewContext",
+			Message: "encryption parameters are not set correctly",
+			Err:     ErrInvalidParameters,
+		}
+	}
+	if level != SecurityNone && (q.Security == SecurityNone || q.Security < level) {
+		return &Error{
+			Op: "NewContext",
+			Message: fmt.Sprintf("%d-bit coefficient modulus at degree %d does not provide %v security",
+				c.TotalCoeffModulusBitCount(), c.PolyModulusDegree(), level),
+			Err: ErrInsecureParameters,
+		}
+	}
+	return nil
+}
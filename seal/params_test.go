@@ -0,0 +1,115 @@
+package seal
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+	"testing"
+)
+
+func TestCoeffModulusPrimes(t *testing.T) {
+	degree := 8192
+	sizes := []int{60, 40, 40, 60}
+	primes, err := coeffModulusPrimes(degree, sizes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[uint64]bool{}
+	for i, p := range primes {
+		if bits.Len64(p) != sizes[i] {
+			t.Errorf("prime %d has %d bits; want %d", p, bits.Len64(p), sizes[i])
+		}
+		if p%uint64(2*degree) != 1 {
+			t.Errorf("prime %d is not 1 mod %d", p, 2*degree)
+		}
+		if !new(big.Int).SetUint64(p).ProbablyPrime(20) {
+			t.Errorf("%d is not prime", p)
+		}
+		if seen[p] {
+			t.Errorf("prime %d repeated", p)
+		}
+		seen[p] = true
+	}
+
+	if _, err := coeffModulusPrimes(degree, []int{61}); err == nil {
+		t.Error("expected error for 61-bit prime")
+	}
+	if _, err := coeffModulusPrimes(0, []int{40}); err == nil {
+		t.Error("expected error without a poly modulus degree")
+	}
+}
+
+func TestEncryptionParamsBuilder(t *testing.T) {
+	params, err := NewEncryptionParams(SchemeCKKS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := params.SetPolyModulusDegree(8192); err != nil {
+		t.Fatal(err)
+	}
+	if err := params.SetCoeffModulus([]int{60, 40, 40, 60}); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(params.CoeffModulus()); got != 4 {
+		t.Fatalf("len(CoeffModulus()) = %d; want 4", got)
+	}
+	c, err := NewContext(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := c.Qualifiers()
+	if !q.ParametersSet || !q.EnableNTT || q.Security != Security128 {
+		t.Fatalf("unexpected qualifiers %+v", q)
+	}
+	if got := c.TotalCoeffModulusBitCount(); got != 200 {
+		t.Fatalf("TotalCoeffModulusBitCount() = %d; want 200", got)
+	}
+}
+
+func TestEncryptionParamsInsecure(t *testing.T) {
+	params, err := NewEncryptionParams(SchemeCKKS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := params.SetPolyModulusDegree(4096); err != nil {
+		t.Fatal(err)
+	}
+	if err := params.SetCoeffModulus([]int{60, 60}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewContext(params); !errors.Is(err, ErrInsecureParameters) {
+		t.Fatalf("NewContext = %v; want ErrInsecureParameters", err)
+	}
+	if err := params.SetSecurityLevel(SecurityNone); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewContext(params); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEncryptionParamsBFV(t *testing.T) {
+	params, err := NewEncryptionParams(SchemeBFV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := params.SetPolyModulusDegree(4096); err != nil {
+		t.Fatal(err)
+	}
+	if err := params.SetDefaultCoeffModulus(); err != nil {
+		t.Fatal(err)
+	}
+	if err := params.SetPlainModulus(40961); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewContext(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := c.Qualifiers(); !q.EnableBatching {
+		t.Fatalf("expected batching for plain modulus 40961, got %+v", q)
+	}
+	if err := params.SetPolyModulusDegree(3000); !errors.Is(err, ErrInvalidParameters) {
+		t.Fatalf("SetPolyModulusDegree(3000) = %v; want ErrInvalidParameters", err)
+	}
+}
@@ -0,0 +1,65 @@
+package seal
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestPolynomialDepth(t *testing.T) {
+	tests := []struct {
+		p    Polynomial
+		want int
+	}{
+		{Polynomial{Coeffs: []float64{2}}, 1},
+		{Polynomial{Coeffs: []float64{1, 2}}, 1},
+		{Polynomial{Coeffs: []float64{1, 2, 3, 4}}, 2},
+		{Polynomial{Coeffs: []float64{1, 2, 3, 4, 5, 6, 7, 8, 0, 0}}, 3},
+		{Polynomial{Coeffs: make([]float64, 16)}, 1},
+		{Polynomial{Basis: Chebyshev, Coeffs: []float64{1, 2, 3, 4}}, 2},
+		{Polynomial{Basis: Chebyshev, A: 0, B: 2, Coeffs: []float64{1, 2, 3, 4}}, 2},
+	}
+	for _, tt := range tests {
+		if got := tt.p.Depth(); got != tt.want {
+			t.Errorf("%v %v: Depth() = %d; want %d", tt.p.Basis, tt.p.Coeffs, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluatePolynomial(t *testing.T) {
+	k, a := newAutoKit(t)
+	// Every slot is checked, so constants that only reach the first slot
+	// show up here.
+	in := []float64{-0.75, 0, 0.5, 1}
+	p, err := k.enc.EncodeVector(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, poly := range []*Polynomial{
+		{Coeffs: []float64{0.5, 0.25, -0.5, 0.125}},
+		{Basis: Chebyshev, A: -1, B: 1, Coeffs: []float64{0.5, 0.6, 0, -0.1, 0, 0.02}},
+		{Basis: Chebyshev, A: -2, B: 2, Coeffs: []float64{0.5, 0.6, 0, -0.1, 0, 0.02, 0, -0.003}},
+	} {
+		r, err := a.EvaluatePolynomial(x, poly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lx, _ := a.Evaluator.Level(x)
+		if lr, _ := a.Evaluator.Level(r); lx-lr != poly.Depth() {
+			t.Errorf("%v: used %d levels; Depth() = %d", poly.Basis, lx-lr, poly.Depth())
+		}
+		out := decryptVector(t, k, r)
+		for i, v := range in {
+			if want := poly.Evaluate(v); math.Abs(out[i]-want) > 1e-4 {
+				t.Errorf("%v: p(%g) = %g; want %g", poly.Basis, v, out[i], want)
+			}
+		}
+	}
+	if _, err := a.EvaluatePolynomial(x, &Polynomial{}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("EvaluatePolynomial(no coefficients) = %v; want ErrInvalidArgument", err)
+	}
+}
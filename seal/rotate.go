@@ -0,0 +1,163 @@
+package seal
+
+// #include "seal.h"
+import "C"
+
+import (
+	"runtime"
+	"unsafe"
+)
+
+// GaloisKeys allow the Evaluator to rotate slots and conjugate ciphertexts.
+type GaloisKeys struct {
+	ptr C.SEALGaloisKeys
+	native
+}
+
+// GaloisKeys generates keys for the given rotation steps. With no steps it
+// generates keys for every power-of-two rotation in either direction, which
+// is enough for any rotation but slower for steps that are not powers of two.
+func (g *KeyGenerator) GaloisKeys(decompositionBitCount int, steps ...int) (*GaloisKeys, error) {
+	if err := checkOpen("KeyGenerator.GaloisKeys", g); err != nil {
+		return nil, err
+	}
+	var data *C.int
+	cSteps := make([]C.int, len(steps))
+	for i, s := range steps {
+		cSteps[i] = C.int(s)
+	}
+	if len(cSteps) > 0 {
+		data = (*C.int)(unsafe.Pointer(&cSteps[0]))
+	}
+	var ptr C.SEALGaloisKeys
+	err := checkError("KeyGenerator.GaloisKeys", C.SEALKeyGeneratorGaloisKeys(g.ptr, C.int(decompositionBitCount), data, C.size_t(len(cSteps)), &ptr))
+	runtime.KeepAlive(g)
+	if err != nil {
+		return nil, err
+	}
+	k := &GaloisKeys{
+		ptr: ptr,
+	}
+	runtime.SetFinalizer(k, (*GaloisKeys).free)
+	track(k)
+	return k, nil
+}
+
+// RotateVectorInplace cyclically rotates the CKKS slots of a left by steps;
+// negative steps rotate right.
+func (e *Evaluator) RotateVectorInplace(a *Ciphertext, steps int, k *GaloisKeys) error {
+	if err := checkOpen("Evaluator.RotateVectorInplace", e, a, k); err != nil {
+		return err
+	}
+	err := checkError("Evaluator.RotateVectorInplace", C.SEALEvaluatorRotateVectorInplace(e.ptr, a.ptr, C.int(steps), k.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(k)
+	return err
+}
+
+func (e *Evaluator) RotateVector(a *Ciphertext, steps int, k *GaloisKeys) (*Ciphertext, error) {
+	a = a.Copy()
+	if err := e.RotateVectorInplace(a, steps, k); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// RotateRowsInplace cyclically rotates both BFV batching rows of a left by
+// steps; negative steps rotate right.
+func (e *Evaluator) RotateRowsInplace(a *Ciphertext, steps int, k *GaloisKeys) error {
+	if err := checkOpen("Evaluator.RotateRowsInplace", e, a, k); err != nil {
+		return err
+	}
+	err := checkError("Evaluator.RotateRowsInplace", C.SEALEvaluatorRotateRowsInplace(e.ptr, a.ptr, C.int(steps), k.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(k)
+	return err
+}
+
+func (e *Evaluator) RotateRows(a *Ciphertext, steps int, k *GaloisKeys) (*Ciphertext, error) {
+	a = a.Copy()
+	if err := e.RotateRowsInplace(a, steps, k); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// RotateColumnsInplace swaps the two BFV batching rows of a.
+func (e *Evaluator) RotateColumnsInplace(a *Ciphertext, k *GaloisKeys) error {
+	if err := checkOpen("Evaluator.RotateColumnsInplace", e, a, k); err != nil {
+		return err
+	}
+	err := checkError("Evaluator.RotateColumnsInplace", C.SEALEvaluatorRotateColumnsInplace(e.ptr, a.ptr, k.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(k)
+	return err
+}
+
+func (e *Evaluator) RotateColumns(a *Ciphertext, k *GaloisKeys) (*Ciphertext, error) {
+	a = a.Copy()
+	if err := e.RotateColumnsInplace(a, k); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// ComplexConjugateInplace conjugates every CKKS slot of a.
+func (e *Evaluator) ComplexConjugateInplace(a *Ciphertext, k *GaloisKeys) error {
+	if err := checkOpen("Evaluator.ComplexConjugateInplace", e, a, k); err != nil {
+		return err
+	}
+	err := checkError("Evaluator.ComplexConjugateInplace", C.SEALEvaluatorComplexConjugateInplace(e.ptr, a.ptr, k.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(k)
+	return err
+}
+
+func (e *Evaluator) ComplexConjugate(a *Ciphertext, k *GaloisKeys) (*Ciphertext, error) {
+	a = a.Copy()
+	if err := e.ComplexConjugateInplace(a, k); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// SumSlots returns a ciphertext in which every slot holds the sum of all
+// slots of a. It needs log2(slots) rotations, all by powers of two, so the
+// default GaloisKeys are sufficient.
+func (e *Evaluator) SumSlots(a *Ciphertext, k *GaloisKeys) (*Ciphertext, error) {
+	sum := a.Copy()
+	degree := e.ctx.PolyModulusDegree()
+	if e.ctx.Scheme() == SchemeBFV {
+		for step := 1; step < degree/2; step *= 2 {
+			rotated, err := e.RotateRows(sum, step, k)
+			if err != nil {
+				return nil, err
+			}
+			if err := e.AddInplace(sum, rotated); err != nil {
+				return nil, err
+			}
+		}
+		rotated, err := e.RotateColumns(sum, k)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.AddInplace(sum, rotated); err != nil {
+			return nil, err
+		}
+		return sum, nil
+	}
+	for step := 1; step < degree/2; step *= 2 {
+		rotated, err := e.RotateVector(sum, step, k)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.AddInplace(sum, rotated); err != nil {
+			return nil, err
+		}
+	}
+	return sum, nil
+}
@@ -0,0 +1,138 @@
+package seal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRotateVector(t *testing.T) {
+	k := newCKKSKit(t)
+	gk, err := k.keygen.GaloisKeys(60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := []float64{1, 2, 3, 4}
+	p, err := k.enc.EncodeVector(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := k.eval.RotateVector(a, 1, gk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := decryptVector(t, k, rotated)
+	for i, want := range []float64{2, 3, 4, 0} {
+		if math.Abs(out[i]-want) > 0.0001 {
+			t.Fatalf("slot %d = %f; want %f", i, out[i], want)
+		}
+	}
+
+	sum, err := k.eval.SumSlots(a, gk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = decryptVector(t, k, sum)
+	for _, i := range []int{0, 1, len(out) - 1} {
+		if math.Abs(out[i]-10) > 0.001 {
+			t.Fatalf("slot %d = %f; want 10", i, out[i])
+		}
+	}
+}
+
+func TestRotateVectorSteps(t *testing.T) {
+	k := newCKKSKit(t)
+	gk, err := k.keygen.GaloisKeys(60, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := k.enc.EncodeVector([]float64{1, 2, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := k.eval.RotateVectorInplace(a, 3, gk); err != nil {
+		t.Fatal(err)
+	}
+	if out := decryptVector(t, k, a); math.Abs(out[0]-4) > 0.0001 {
+		t.Fatalf("slot 0 = %f; want 4", out[0])
+	}
+}
+
+func TestRotateRowsAndColumns(t *testing.T) {
+	k := newBFVKit(t)
+	gk, err := k.keygen.GaloisKeys(30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := k.batch.RowSize()
+	in := make([]uint64, k.batch.SlotCount())
+	in[0], in[1], in[row] = 5, 7, 11
+	p, err := k.batch.EncodeUint64(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := k.eval.RotateRows(a, 1, gk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := decryptBatch(t, k, rotated); out[0] != 7 || out[row-1] != 5 {
+		t.Fatalf("RotateRows: slots 0, %d = %d, %d; want 7, 5", row-1, out[0], out[row-1])
+	}
+
+	swapped, err := k.eval.RotateColumns(a, gk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := decryptBatch(t, k, swapped); out[0] != 11 || out[row] != 5 {
+		t.Fatalf("RotateColumns: slots 0, %d = %d, %d; want 11, 5", row, out[0], out[row])
+	}
+
+	sum, err := k.eval.SumSlots(a, gk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range decryptBatch(t, k, sum) {
+		if v != 23 {
+			t.Fatalf("SumSlots: slot %d = %d; want 23", i, v)
+		}
+	}
+}
+
+func decryptVector(t *testing.T, k *ckksKit, a *Ciphertext) []float64 {
+	t.Helper()
+	plain, err := k.decryptor.Decrypt(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := k.enc.DecodeVector(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func decryptBatch(t *testing.T, k *bfvKit, a *Ciphertext) []uint64 {
+	t.Helper()
+	plain, err := k.decryptor.Decrypt(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := k.batch.DecodeUint64(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
@@ -1,66 +1,324 @@
 #include "seal.h"
+#include "seal/randomtostd.h"
 #include "seal/seal.h"
+#include "seal/util/clipnormal.h"
+#include "seal/util/smallntt.h"
 
-SEALEncryptionParameters SEALEncryptionParametersBFV(void) {
-  auto* params = new seal::EncryptionParameters(seal::scheme_type::BFV);
-  params->set_poly_modulus_degree(2048);
-  params->set_coeff_modulus(seal::coeff_modulus_128(2048));
-  params->set_plain_modulus(1 << 8);
-  return (void*)params;
+#include <algorithm>
+#include <array>
+#include <cmath>
+#include <complex>
+#include <cstdlib>
+#include <cstring>
+#include <memory>
+#include <random>
+#include <sstream>
+#include <stdexcept>
+
+namespace {
+
+SEALError makeError(int code, const char* what) {
+  size_t n = std::strlen(what) + 1;
+  auto* message = static_cast<char*>(std::malloc(n));
+  std::memcpy(message, what, n);
+  return SEALError{code, message};
+}
+
+// guard runs f and converts any exception it throws into a SEALError so that
+// nothing unwinds across the cgo boundary.
+template <typename F>
+SEALError guard(F f) {
+  try {
+    f();
+  } catch (const std::invalid_argument& e) {
+    return makeError(SEAL_ERROR_INVALID_ARGUMENT, e.what());
+  } catch (const std::out_of_range& e) {
+    return makeError(SEAL_ERROR_OUT_OF_RANGE, e.what());
+  } catch (const std::logic_error& e) {
+    return makeError(SEAL_ERROR_LOGIC, e.what());
+  } catch (const std::exception& e) {
+    return makeError(SEAL_ERROR_RUNTIME, e.what());
+  } catch (...) {
+    return makeError(SEAL_ERROR_UNKNOWN, "unknown exception");
+  }
+  return SEALError{SEAL_OK, nullptr};
+}
+
+using ContextPtr = std::shared_ptr<seal::SEALContext>;
+
+SEALError copyBuffer(const std::string& s, char** data, size_t* size) {
+  *size = s.size();
+  *data = static_cast<char*>(std::malloc(s.size() ? s.size() : 1));
+  std::memcpy(*data, s.data(), s.size());
+  return SEALError{SEAL_OK, nullptr};
+}
+
+template <typename T>
+SEALError saveObject(void* p, char** data, size_t* size) {
+  return guard([&] {
+    std::ostringstream stream;
+    static_cast<T*>(p)->save(stream);
+    copyBuffer(stream.str(), data, size);
+  });
+}
+
+// loadObject deserializes a T and, when c is not NULL, hands it to validate
+// together with the context before returning it.
+template <typename T, typename V>
+SEALError loadObject(SEALContext c, char* data, size_t size, void** out,
+                     V validate) {
+  return guard([&] {
+    std::istringstream stream(std::string(data, size));
+    stream.exceptions(std::ios_base::failbit | std::ios_base::badbit);
+    auto obj = std::unique_ptr<T>(new T());
+    try {
+      obj->load(stream);
+    } catch (const std::ios_base::failure&) {
+      throw std::invalid_argument("serialized data is truncated or corrupt");
+    }
+    if (c != nullptr) {
+      validate(*static_cast<ContextPtr*>(c), *obj);
+    }
+    *out = (void*)obj.release();
+  });
+}
+
+size_t byteCount(const seal::Ciphertext& c) {
+  return c.size() * c.poly_modulus_degree() * c.coeff_mod_count() *
+         sizeof(std::uint64_t);
+}
+
+size_t byteCount(const std::vector<std::vector<seal::Ciphertext>>& keys) {
+  size_t n = 0;
+  for (const auto& v : keys) {
+    for (const auto& c : v) {
+      n += byteCount(c);
+    }
+  }
+  return n;
+}
+
+template <typename K>
+void validateKey(const ContextPtr& ctx, const K& key) {
+  if (key.parms_id() != ctx->first_parms_id()) {
+    throw std::invalid_argument("key is not valid for encryption parameters");
+  }
+}
+
+}  // namespace
+
+SEALError SEALEncryptionParametersInit(int scheme,
+                                       SEALEncryptionParameters* out) {
+  return guard([&] {
+    switch (scheme) {
+      case SEAL_SCHEME_BFV:
+        *out = (void*)new seal::EncryptionParameters(seal::scheme_type::BFV);
+        break;
+      case SEAL_SCHEME_CKKS:
+        *out = (void*)new seal::EncryptionParameters(seal::scheme_type::CKKS);
+        break;
+      default:
+        throw std::invalid_argument("unsupported scheme");
+    }
+  });
+}
+
+SEALError SEALEncryptionParametersBFV(SEALEncryptionParameters* out) {
+  return guard([&] {
+    auto* params = new seal::EncryptionParameters(seal::scheme_type::BFV);
+    params->set_poly_modulus_degree(2048);
+    params->set_coeff_modulus(seal::coeff_modulus_128(2048));
+    // 12289 is prime and congruent to 1 mod 2*2048, which enables batching.
+    params->set_plain_modulus(12289);
+    *out = (void*)params;
+  });
 }
 
-SEALEncryptionParameters SEALEncryptionParametersCKKS(void) {
-  auto* params = new seal::EncryptionParameters(seal::scheme_type::CKKS);
-  params->set_poly_modulus_degree(16384);
-  params->set_coeff_modulus(seal::coeff_modulus_128(16384));
-  /*
-	params->set_coeff_modulus({
-      seal::small_mods_40bit(0), seal::small_mods_40bit(1),
-			seal::small_mods_40bit(2), seal::small_mods_40bit(3) });
-      */
-  return (void*)params;
+SEALError SEALEncryptionParametersCKKS(SEALEncryptionParameters* out) {
+  return guard([&] {
+    auto* params = new seal::EncryptionParameters(seal::scheme_type::CKKS);
+    params->set_poly_modulus_degree(16384);
+    params->set_coeff_modulus(seal::coeff_modulus_128(16384));
+    /*
+    params->set_coeff_modulus({
+        seal::small_mods_40bit(0), seal::small_mods_40bit(1),
+        seal::small_mods_40bit(2), seal::small_mods_40bit(3) });
+        */
+    *out = (void*)params;
+  });
 }
 
 void SEALEncryptionParametersDelete(SEALEncryptionParameters p) {
   delete static_cast<seal::EncryptionParameters*>(p);
 }
 
-SEALContext SEALContextInit(SEALEncryptionParameters p) {
+SEALError SEALEncryptionParametersSetPolyModulusDegree(
+    SEALEncryptionParameters p, uint64_t degree) {
+  return guard([&] {
+    auto* params = static_cast<seal::EncryptionParameters*>(p);
+    params->set_poly_modulus_degree(degree);
+  });
+}
+
+SEALError SEALEncryptionParametersSetCoeffModulus(SEALEncryptionParameters p,
+                                                  uint64_t* primes,
+                                                  size_t count) {
+  return guard([&] {
+    auto* params = static_cast<seal::EncryptionParameters*>(p);
+    std::vector<seal::SmallModulus> moduli;
+    for (size_t i = 0; i < count; i++) {
+      moduli.emplace_back(primes[i]);
+    }
+    params->set_coeff_modulus(moduli);
+  });
+}
+
+SEALError SEALEncryptionParametersSetDefaultCoeffModulus(
+    SEALEncryptionParameters p, int security) {
+  return guard([&] {
+    auto* params = static_cast<seal::EncryptionParameters*>(p);
+    auto degree = params->poly_modulus_degree();
+    switch (security) {
+      case SEAL_SECURITY_128:
+        params->set_coeff_modulus(seal::coeff_modulus_128(degree));
+        break;
+      case SEAL_SECURITY_192:
+        params->set_coeff_modulus(seal::coeff_modulus_192(degree));
+        break;
+      case SEAL_SECURITY_256:
+        params->set_coeff_modulus(seal::coeff_modulus_256(degree));
+        break;
+      default:
+        throw std::invalid_argument("unsupported security level");
+    }
+  });
+}
+
+SEALError SEALEncryptionParametersSetPlainModulus(SEALEncryptionParameters p,
+                                                  uint64_t plain_modulus) {
+  return guard([&] {
+    auto* params = static_cast<seal::EncryptionParameters*>(p);
+    params->set_plain_modulus(plain_modulus);
+  });
+}
+
+int SEALEncryptionParametersScheme(SEALEncryptionParameters p) {
+  auto* params = static_cast<seal::EncryptionParameters*>(p);
+  return static_cast<int>(params->scheme());
+}
+
+uint64_t SEALEncryptionParametersPolyModulusDegree(SEALEncryptionParameters p) {
+  auto* params = static_cast<seal::EncryptionParameters*>(p);
+  return params->poly_modulus_degree();
+}
+
+size_t SEALEncryptionParametersCoeffModulusCount(SEALEncryptionParameters p) {
+  auto* params = static_cast<seal::EncryptionParameters*>(p);
+  return params->coeff_modulus().size();
+}
+
+void SEALEncryptionParametersCoeffModulus(SEALEncryptionParameters p,
+                                          uint64_t* out) {
+  auto* params = static_cast<seal::EncryptionParameters*>(p);
+  for (const auto& mod : params->coeff_modulus()) {
+    *out++ = mod.value();
+  }
+}
+
+uint64_t SEALEncryptionParametersPlainModulus(SEALEncryptionParameters p) {
   auto* params = static_cast<seal::EncryptionParameters*>(p);
-  auto ctx = seal::SEALContext::Create(*params);
-  return (void*)new std::shared_ptr<seal::SEALContext>(std::move(ctx));
+  return params->plain_modulus().value();
+}
+
+SEALError SEALContextInit(SEALEncryptionParameters p, SEALContext* out) {
+  return guard([&] {
+    auto* params = static_cast<seal::EncryptionParameters*>(p);
+    auto ctx = seal::SEALContext::Create(*params);
+    *out = (void*)new std::shared_ptr<seal::SEALContext>(std::move(ctx));
+  });
 }
 
 void SEALContextDelete(SEALContext c) {
   delete static_cast<std::shared_ptr<seal::SEALContext>*>(c);
 }
 
-SEALKeyGenerator SEALKeyGeneratorInit(SEALContext c) {
+SEALQualifiers SEALContextQualifiers(SEALContext c) {
   auto* ctx = static_cast<std::shared_ptr<seal::SEALContext>*>(c);
-  return (void*)new seal::KeyGenerator(*ctx);
+  auto q = (*ctx)->context_data()->qualifiers();
+  return SEALQualifiers{q.parameters_set, q.enable_fft, q.enable_ntt,
+                        q.enable_batching, q.enable_fast_plain_lift};
+}
+
+int SEALContextScheme(SEALContext c) {
+  auto* ctx = static_cast<std::shared_ptr<seal::SEALContext>*>(c);
+  return static_cast<int>((*ctx)->context_data()->parms().scheme());
+}
+
+uint64_t SEALContextPolyModulusDegree(SEALContext c) {
+  auto* ctx = static_cast<std::shared_ptr<seal::SEALContext>*>(c);
+  return (*ctx)->context_data()->parms().poly_modulus_degree();
+}
+
+int SEALContextTotalCoeffModulusBitCount(SEALContext c) {
+  auto* ctx = static_cast<std::shared_ptr<seal::SEALContext>*>(c);
+  int bits = 0;
+  for (const auto& mod : (*ctx)->context_data()->parms().coeff_modulus()) {
+    bits += mod.bit_count();
+  }
+  return bits;
+}
+
+SEALError SEALKeyGeneratorInit(SEALContext c, SEALKeyGenerator* out) {
+  return guard([&] {
+    auto* ctx = static_cast<std::shared_ptr<seal::SEALContext>*>(c);
+    *out = (void*)new seal::KeyGenerator(*ctx);
+  });
 }
 
 void SEALKeyGeneratorDelete(SEALKeyGenerator g) {
   delete static_cast<seal::KeyGenerator*>(g);
 }
 
-SEALPublicKey SEALKeyGeneratorPublicKey(SEALKeyGenerator g) {
-  auto* generator = static_cast<seal::KeyGenerator*>(g);
-  auto key = generator->public_key();
-  return (void*)new seal::PublicKey(key);
+SEALError SEALKeyGeneratorPublicKey(SEALKeyGenerator g, SEALPublicKey* out) {
+  return guard([&] {
+    auto* generator = static_cast<seal::KeyGenerator*>(g);
+    auto key = generator->public_key();
+    *out = (void*)new seal::PublicKey(key);
+  });
 }
 
-SEALSecretKey SEALKeyGeneratorSecretKey(SEALKeyGenerator g) {
-  auto* generator = static_cast<seal::KeyGenerator*>(g);
-  auto key = generator->secret_key();
-  return (void*)new seal::SecretKey(key);
+SEALError SEALKeyGeneratorSecretKey(SEALKeyGenerator g, SEALSecretKey* out) {
+  return guard([&] {
+    auto* generator = static_cast<seal::KeyGenerator*>(g);
+    auto key = generator->secret_key();
+    *out = (void*)new seal::SecretKey(key);
+  });
 }
 
-SEALSecretKey SEALKeyGeneratorRelinKeys(SEALKeyGenerator g,
-                                        int decomposition_bit_count, int num) {
-  auto* generator = static_cast<seal::KeyGenerator*>(g);
-  auto key = generator->relin_keys(decomposition_bit_count, num);
-  return (void*)new seal::RelinKeys(key);
+SEALError SEALKeyGeneratorRelinKeys(SEALKeyGenerator g,
+                                    int decomposition_bit_count, int num,
+                                    SEALRelinKeys* out) {
+  return guard([&] {
+    auto* generator = static_cast<seal::KeyGenerator*>(g);
+    auto key = generator->relin_keys(decomposition_bit_count, num);
+    *out = (void*)new seal::RelinKeys(key);
+  });
+}
+
+SEALError SEALKeyGeneratorGaloisKeys(SEALKeyGenerator g,
+                                     int decomposition_bit_count, int* steps,
+                                     size_t count, SEALGaloisKeys* out) {
+  return guard([&] {
+    auto* generator = static_cast<seal::KeyGenerator*>(g);
+    if (count == 0) {
+      auto keys = generator->galois_keys(decomposition_bit_count);
+      *out = (void*)new seal::GaloisKeys(keys);
+    } else {
+      std::vector<int> s(steps, steps + count);
+      auto keys = generator->galois_keys(decomposition_bit_count, s);
+      *out = (void*)new seal::GaloisKeys(keys);
+    }
+  });
 }
 
 void SEALPublicKeyDelete(SEALPublicKey k) {
@@ -75,10 +333,17 @@ void SEALRelinKeysDelete(SEALRelinKeys k) {
   delete static_cast<seal::RelinKeys*>(k);
 }
 
-SEALEncryptor SEALEncryptorInit(SEALContext c, SEALPublicKey k) {
-  auto* ctx = static_cast<std::shared_ptr<seal::SEALContext>*>(c);
-  auto* key = static_cast<seal::PublicKey*>(k);
-  return (void*)new seal::Encryptor(*ctx, *key);
+void SEALGaloisKeysDelete(SEALGaloisKeys k) {
+  delete static_cast<seal::GaloisKeys*>(k);
+}
+
+SEALError SEALEncryptorInit(SEALContext c, SEALPublicKey k,
+                            SEALEncryptor* out) {
+  return guard([&] {
+    auto* ctx = static_cast<std::shared_ptr<seal::SEALContext>*>(c);
+    auto* key = static_cast<seal::PublicKey*>(k);
+    *out = (void*)new seal::Encryptor(*ctx, *key);
+  });
 }
 
 void SEALEncryptorDelete(SEALEncryptor k) {
@@ -99,6 +364,32 @@ double SEALCiphertextScale(SEALCiphertext k) {
   return c->scale();
 }
 
+size_t SEALCiphertextSize(SEALCiphertext k) {
+  return static_cast<seal::Ciphertext*>(k)->size();
+}
+
+size_t SEALCiphertextPolyModulusDegree(SEALCiphertext k) {
+  return static_cast<seal::Ciphertext*>(k)->poly_modulus_degree();
+}
+
+size_t SEALCiphertextCoeffModCount(SEALCiphertext k) {
+  return static_cast<seal::Ciphertext*>(k)->coeff_mod_count();
+}
+
+int SEALCiphertextIsTransparent(SEALCiphertext k) {
+  return static_cast<seal::Ciphertext*>(k)->is_transparent();
+}
+
+int SEALCiphertextIsNTTForm(SEALCiphertext k) {
+  const auto* c = static_cast<const seal::Ciphertext*>(k);
+  return c->is_ntt_form();
+}
+
+void SEALCiphertextSetScale(SEALCiphertext k, double scale) {
+  auto* c = static_cast<seal::Ciphertext*>(k);
+  c->scale() = scale;
+}
+
 SEALParmsID SEALCiphertextParmsID(SEALCiphertext k) {
   auto* c = static_cast<seal::Ciphertext*>(k);
   return (void*)new seal::parms_id_type(c->parms_id());
@@ -114,189 +405,1009 @@ int SEALParmsIDEq(SEALParmsID aptr, SEALParmsID bptr) {
   return *a == *b;
 }
 
-SEALCiphertext SEALEncryptorEncrypt(SEALEncryptor k, SEALPlaintext p) {
-  auto* e = static_cast<seal::Encryptor*>(k);
-  auto* pl = static_cast<seal::Plaintext*>(p);
-  seal::Ciphertext encrypted;
-  e->encrypt(*pl, encrypted);
-  return (void*)new seal::Ciphertext(encrypted);
+SEALError SEALEncryptorEncrypt(SEALEncryptor k, SEALPlaintext p,
+                               SEALCiphertext* out) {
+  return guard([&] {
+    auto* e = static_cast<seal::Encryptor*>(k);
+    auto* pl = static_cast<seal::Plaintext*>(p);
+    seal::Ciphertext encrypted;
+    e->encrypt(*pl, encrypted);
+    *out = (void*)new seal::Ciphertext(encrypted);
+  });
 }
 
-SEALEvaluator SEALEvaluatorInit(SEALContext c) {
-  auto* ctx = static_cast<std::shared_ptr<seal::SEALContext>*>(c);
-  return (void*)new seal::Evaluator(*ctx);
+SEALError SEALEvaluatorInit(SEALContext c, SEALEvaluator* out) {
+  return guard([&] {
+    auto* ctx = static_cast<std::shared_ptr<seal::SEALContext>*>(c);
+    *out = (void*)new seal::Evaluator(*ctx);
+  });
 }
 
 void SEALEvaluatorDelete(SEALEvaluator k) {
   delete static_cast<seal::Evaluator*>(k);
 }
 
-void SEALEvaluatorSquareInplace(SEALEvaluator k, SEALCiphertext cptr) {
-  auto* e = static_cast<seal::Evaluator*>(k);
-  auto* c = static_cast<seal::Ciphertext*>(cptr);
-  e->square_inplace(*c);
+SEALError SEALEvaluatorSquareInplace(SEALEvaluator k, SEALCiphertext cptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* c = static_cast<seal::Ciphertext*>(cptr);
+    e->square_inplace(*c);
+  });
+}
+
+SEALError SEALEvaluatorNegateInplace(SEALEvaluator k, SEALCiphertext cptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* c = static_cast<seal::Ciphertext*>(cptr);
+    e->negate_inplace(*c);
+  });
 }
 
-void SEALEvaluatorNegateInplace(SEALEvaluator k, SEALCiphertext cptr) {
-  auto* e = static_cast<seal::Evaluator*>(k);
-  auto* c = static_cast<seal::Ciphertext*>(cptr);
-  e->negate_inplace(*c);
+SEALError SEALEvaluatorAddInplace(SEALEvaluator k, SEALCiphertext aptr,
+                                  SEALCiphertext bptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* a = static_cast<seal::Ciphertext*>(aptr);
+    auto* b = static_cast<seal::Ciphertext*>(bptr);
+    e->add_inplace(*a, *b);
+  });
 }
 
-void SEALEvaluatorAddInplace(SEALEvaluator k, SEALCiphertext aptr,
-                             SEALCiphertext bptr) {
-  auto* e = static_cast<seal::Evaluator*>(k);
-  auto* a = static_cast<seal::Ciphertext*>(aptr);
-  auto* b = static_cast<seal::Ciphertext*>(bptr);
-  e->add_inplace(*a, *b);
+SEALError SEALEvaluatorAddPlainInplace(SEALEvaluator k, SEALCiphertext aptr,
+                                       SEALPlaintext bptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* a = static_cast<seal::Ciphertext*>(aptr);
+    auto* b = static_cast<seal::Plaintext*>(bptr);
+    e->add_plain_inplace(*a, *b);
+  });
 }
 
-void SEALEvaluatorAddPlainInplace(SEALEvaluator k, SEALCiphertext aptr,
-                                  SEALPlaintext bptr) {
-  auto* e = static_cast<seal::Evaluator*>(k);
-  auto* a = static_cast<seal::Ciphertext*>(aptr);
-  auto* b = static_cast<seal::Plaintext*>(bptr);
-  e->add_plain_inplace(*a, *b);
+SEALError SEALEvaluatorSubInplace(SEALEvaluator k, SEALCiphertext aptr,
+                                  SEALCiphertext bptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* a = static_cast<seal::Ciphertext*>(aptr);
+    auto* b = static_cast<seal::Ciphertext*>(bptr);
+    e->sub_inplace(*a, *b);
+  });
 }
 
-void SEALEvaluatorSubInplace(SEALEvaluator k, SEALCiphertext aptr,
-                             SEALCiphertext bptr) {
-  auto* e = static_cast<seal::Evaluator*>(k);
-  auto* a = static_cast<seal::Ciphertext*>(aptr);
-  auto* b = static_cast<seal::Ciphertext*>(bptr);
-  e->sub_inplace(*a, *b);
+SEALError SEALEvaluatorSubPlainInplace(SEALEvaluator k, SEALCiphertext aptr,
+                                       SEALPlaintext bptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* a = static_cast<seal::Ciphertext*>(aptr);
+    auto* b = static_cast<seal::Plaintext*>(bptr);
+    e->sub_plain_inplace(*a, *b);
+  });
 }
 
-void SEALEvaluatorSubPlainInplace(SEALEvaluator k, SEALCiphertext aptr,
-                                  SEALPlaintext bptr) {
-  auto* e = static_cast<seal::Evaluator*>(k);
-  auto* a = static_cast<seal::Ciphertext*>(aptr);
-  auto* b = static_cast<seal::Plaintext*>(bptr);
-  e->sub_plain_inplace(*a, *b);
+SEALError SEALEvaluatorMultiplyInplace(SEALEvaluator k, SEALCiphertext aptr,
+                                       SEALCiphertext bptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* a = static_cast<seal::Ciphertext*>(aptr);
+    auto* b = static_cast<seal::Ciphertext*>(bptr);
+    e->multiply_inplace(*a, *b);
+  });
 }
 
-void SEALEvaluatorMultiplyInplace(SEALEvaluator k, SEALCiphertext aptr,
-                                  SEALCiphertext bptr) {
-  auto* e = static_cast<seal::Evaluator*>(k);
-  auto* a = static_cast<seal::Ciphertext*>(aptr);
-  auto* b = static_cast<seal::Ciphertext*>(bptr);
-  e->multiply_inplace(*a, *b);
+SEALError SEALEvaluatorMultiplyPlainInplace(SEALEvaluator k,
+                                            SEALCiphertext aptr,
+                                            SEALPlaintext bptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* a = static_cast<seal::Ciphertext*>(aptr);
+    auto* b = static_cast<seal::Plaintext*>(bptr);
+    e->multiply_plain_inplace(*a, *b);
+  });
 }
 
-void SEALEvaluatorMultiplyPlainInplace(SEALEvaluator k, SEALCiphertext aptr,
-                                       SEALPlaintext bptr) {
-  auto* e = static_cast<seal::Evaluator*>(k);
-  auto* a = static_cast<seal::Ciphertext*>(aptr);
-  auto* b = static_cast<seal::Plaintext*>(bptr);
-  e->multiply_plain_inplace(*a, *b);
+SEALError SEALEvaluatorRelinearizeInplace(SEALEvaluator k, SEALCiphertext aptr,
+                                          SEALRelinKeys rptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* a = static_cast<seal::Ciphertext*>(aptr);
+    auto* b = static_cast<seal::RelinKeys*>(rptr);
+    e->relinearize_inplace(*a, *b);
+  });
 }
 
-void SEALEvaluatorRelinearizeInplace(SEALEvaluator k, SEALCiphertext aptr,
-                                     SEALRelinKeys rptr) {
-  auto* e = static_cast<seal::Evaluator*>(k);
-  auto* a = static_cast<seal::Ciphertext*>(aptr);
-  auto* b = static_cast<seal::RelinKeys*>(rptr);
-  e->relinearize_inplace(*a, *b);
+SEALError SEALEvaluatorExponentiateInplace(SEALEvaluator k,
+                                           SEALCiphertext aptr,
+                                           uint64_t power,
+                                           SEALRelinKeys rptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* a = static_cast<seal::Ciphertext*>(aptr);
+    auto* b = static_cast<seal::RelinKeys*>(rptr);
+    e->exponentiate_inplace(*a, power, *b);
+  });
 }
 
-void SEALEvaluatorExponentiateInplace(SEALEvaluator k, SEALCiphertext aptr,
-                                      uint64_t power, SEALRelinKeys rptr) {
-  auto* e = static_cast<seal::Evaluator*>(k);
-  auto* a = static_cast<seal::Ciphertext*>(aptr);
-  auto* b = static_cast<seal::RelinKeys*>(rptr);
-  e->exponentiate_inplace(*a, power, *b);
+SEALError SEALEvaluatorRotateVectorInplace(SEALEvaluator k,
+                                           SEALCiphertext aptr, int steps,
+                                           SEALGaloisKeys gptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* a = static_cast<seal::Ciphertext*>(aptr);
+    auto* g = static_cast<seal::GaloisKeys*>(gptr);
+    e->rotate_vector_inplace(*a, steps, *g);
+  });
 }
 
-void SEALEvaluatorRescaleToNextInplace(SEALEvaluator k, SEALCiphertext aptr) {
-  auto* e = static_cast<seal::Evaluator*>(k);
-  auto* a = static_cast<seal::Ciphertext*>(aptr);
-  e->rescale_to_next_inplace(*a);
+SEALError SEALEvaluatorRotateRowsInplace(SEALEvaluator k, SEALCiphertext aptr,
+                                         int steps, SEALGaloisKeys gptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* a = static_cast<seal::Ciphertext*>(aptr);
+    auto* g = static_cast<seal::GaloisKeys*>(gptr);
+    e->rotate_rows_inplace(*a, steps, *g);
+  });
 }
 
-void SEALEvaluatorRescaleToInplace(SEALEvaluator k, SEALCiphertext aptr, SEALParmsID pptr) {
-  auto* e = static_cast<seal::Evaluator*>(k);
-  auto* a = static_cast<seal::Ciphertext*>(aptr);
-  auto* p = static_cast<seal::parms_id_type*>(pptr);
-  auto pool = seal::MemoryManager::GetPool();
-  if (!pool) {
-    throw std::invalid_argument("what the fuck");
-  }
-  e->rescale_to_inplace(*a, *p, pool);
+SEALError SEALEvaluatorRotateColumnsInplace(SEALEvaluator k,
+                                            SEALCiphertext aptr,
+                                            SEALGaloisKeys gptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* a = static_cast<seal::Ciphertext*>(aptr);
+    auto* g = static_cast<seal::GaloisKeys*>(gptr);
+    e->rotate_columns_inplace(*a, *g);
+  });
 }
 
-SEALDecryptor SEALDecryptorInit(SEALContext c, SEALSecretKey k) {
-  auto* ctx = static_cast<std::shared_ptr<seal::SEALContext>*>(c);
-  auto* key = static_cast<seal::SecretKey*>(k);
-  return (void*)new seal::Decryptor(*ctx, *key);
+SEALError SEALEvaluatorComplexConjugateInplace(SEALEvaluator k,
+                                               SEALCiphertext aptr,
+                                               SEALGaloisKeys gptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* a = static_cast<seal::Ciphertext*>(aptr);
+    auto* g = static_cast<seal::GaloisKeys*>(gptr);
+    e->complex_conjugate_inplace(*a, *g);
+  });
+}
+
+SEALError SEALEvaluatorRescaleToNextInplace(SEALEvaluator k,
+                                            SEALCiphertext aptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* a = static_cast<seal::Ciphertext*>(aptr);
+    e->rescale_to_next_inplace(*a);
+  });
+}
+
+SEALError SEALEvaluatorRescaleToInplace(SEALEvaluator k, SEALCiphertext aptr,
+                                        SEALParmsID pptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* a = static_cast<seal::Ciphertext*>(aptr);
+    auto* p = static_cast<seal::parms_id_type*>(pptr);
+    e->rescale_to_inplace(*a, *p);
+  });
+}
+
+SEALError SEALEvaluatorModSwitchToNextInplace(SEALEvaluator k,
+                                              SEALCiphertext aptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* a = static_cast<seal::Ciphertext*>(aptr);
+    e->mod_switch_to_next_inplace(*a);
+  });
+}
+
+SEALError SEALEvaluatorModSwitchToInplace(SEALEvaluator k, SEALCiphertext aptr,
+                                          SEALParmsID pptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* a = static_cast<seal::Ciphertext*>(aptr);
+    auto* p = static_cast<seal::parms_id_type*>(pptr);
+    e->mod_switch_to_inplace(*a, *p);
+  });
+}
+
+SEALError SEALEvaluatorModSwitchToNextPlainInplace(SEALEvaluator k,
+                                                   SEALPlaintext aptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* a = static_cast<seal::Plaintext*>(aptr);
+    e->mod_switch_to_next_inplace(*a);
+  });
+}
+
+SEALError SEALEvaluatorModSwitchToPlainInplace(SEALEvaluator k,
+                                               SEALPlaintext aptr,
+                                               SEALParmsID pptr) {
+  return guard([&] {
+    auto* e = static_cast<seal::Evaluator*>(k);
+    auto* a = static_cast<seal::Plaintext*>(aptr);
+    auto* p = static_cast<seal::parms_id_type*>(pptr);
+    e->mod_switch_to_inplace(*a, *p);
+  });
+}
+
+SEALError SEALDecryptorInit(SEALContext c, SEALSecretKey k,
+                            SEALDecryptor* out) {
+  return guard([&] {
+    auto* ctx = static_cast<std::shared_ptr<seal::SEALContext>*>(c);
+    auto* key = static_cast<seal::SecretKey*>(k);
+    *out = (void*)new seal::Decryptor(*ctx, *key);
+  });
 }
 
 void SEALDecryptorDelete(SEALDecryptor k) {
   delete static_cast<seal::Decryptor*>(k);
 }
 
-SEALPlaintext SEALDecryptorDecrypt(SEALDecryptor k, SEALCiphertext c) {
-  auto* d = static_cast<seal::Decryptor*>(k);
-  auto* ciphertext = static_cast<seal::Ciphertext*>(c);
-  seal::Plaintext plain;
-  d->decrypt(*ciphertext, plain);
-  return (void*)new seal::Plaintext(plain);
+SEALError SEALDecryptorDecrypt(SEALDecryptor k, SEALCiphertext c,
+                               SEALPlaintext* out) {
+  return guard([&] {
+    auto* d = static_cast<seal::Decryptor*>(k);
+    auto* ciphertext = static_cast<seal::Ciphertext*>(c);
+    seal::Plaintext plain;
+    d->decrypt(*ciphertext, plain);
+    *out = (void*)new seal::Plaintext(plain);
+  });
 }
 
-SEALDecryptor SEALBinaryFractionalEncoderInit(SEALEncryptionParameters params) {
-  auto* p = static_cast<seal::EncryptionParameters*>(params);
-  return (void*)new seal::BinaryFractionalEncoder(
-      p->plain_modulus(), p->poly_modulus_degree(),
-      p->poly_modulus_degree() / 2 - 1, p->poly_modulus_degree() / 2 - 1);
+SEALError SEALDecryptorInvariantNoiseBudget(SEALDecryptor k, SEALCiphertext c,
+                                            int* out) {
+  return guard([&] {
+    auto* d = static_cast<seal::Decryptor*>(k);
+    auto* ciphertext = static_cast<seal::Ciphertext*>(c);
+    if (ciphertext->is_ntt_form()) {
+      throw std::invalid_argument(
+          "noise budget is only defined for BFV ciphertexts");
+    }
+    *out = d->invariant_noise_budget(*ciphertext);
+  });
+}
+
+SEALError SEALBinaryFractionalEncoderInit(SEALEncryptionParameters params,
+                                          SEALBinaryFractionalEncoder* out) {
+  return guard([&] {
+    auto* p = static_cast<seal::EncryptionParameters*>(params);
+    *out = (void*)new seal::BinaryFractionalEncoder(
+        p->plain_modulus(), p->poly_modulus_degree(),
+        p->poly_modulus_degree() / 2 - 1, p->poly_modulus_degree() / 2 - 1);
+  });
 }
 
 void SEALBinaryFractionalEncoderDelete(SEALBinaryFractionalEncoder k) {
   delete static_cast<seal::BinaryFractionalEncoder*>(k);
 }
 
-SEALPlaintext SEALBinaryFractionalEncoderEncode(SEALBinaryFractionalEncoder k,
-                                                double a) {
-  auto* e = static_cast<seal::BinaryFractionalEncoder*>(k);
-  return (void*)new seal::Plaintext(e->encode(a));
+SEALError SEALBinaryFractionalEncoderEncode(SEALBinaryFractionalEncoder k,
+                                            double a, SEALPlaintext* out) {
+  return guard([&] {
+    auto* e = static_cast<seal::BinaryFractionalEncoder*>(k);
+    *out = (void*)new seal::Plaintext(e->encode(a));
+  });
 }
 
-double SEALBinaryFractionalEncoderDecode(SEALBinaryFractionalEncoder k,
-                                         SEALPlaintext a) {
-  auto* e = static_cast<seal::BinaryFractionalEncoder*>(k);
-  auto* p = static_cast<seal::Plaintext*>(a);
-  return e->decode(*p);
+SEALError SEALBinaryFractionalEncoderDecode(SEALBinaryFractionalEncoder k,
+                                            SEALPlaintext a, double* out) {
+  return guard([&] {
+    auto* e = static_cast<seal::BinaryFractionalEncoder*>(k);
+    auto* p = static_cast<seal::Plaintext*>(a);
+    *out = e->decode(*p);
+  });
 }
 
 void SEALPlaintextDelete(SEALPlaintext k) {
   delete static_cast<seal::Plaintext*>(k);
 }
 
-SEALCKKSEncoder SEALCKKSEncoderInit(SEALContext c) {
-  auto* ctx = static_cast<std::shared_ptr<seal::SEALContext>*>(c);
-  return (void*)new seal::CKKSEncoder(*ctx);
+SEALError SEALCKKSEncoderInit(SEALContext c, SEALCKKSEncoder* out) {
+  return guard([&] {
+    auto* ctx = static_cast<std::shared_ptr<seal::SEALContext>*>(c);
+    *out = (void*)new seal::CKKSEncoder(*ctx);
+  });
 }
 
 void SEALCKKSEncoderDelete(SEALCKKSEncoder k) {
   delete static_cast<seal::CKKSEncoder*>(k);
 }
 
-SEALPlaintext SEALCKKSEncoderEncode(SEALCKKSEncoder k, double num,
-                                    SEALParmsID pidptr, double scale) {
+SEALError SEALCKKSEncoderEncode(SEALCKKSEncoder k, double num,
+                                SEALParmsID pidptr, double scale,
+                                SEALPlaintext* out) {
+  return guard([&] {
+    auto* e = static_cast<seal::CKKSEncoder*>(k);
+    std::vector<double> data(e->slot_count(), num);
+    seal::Plaintext p;
+    if (pidptr != nullptr) {
+      auto* pid = static_cast<seal::parms_id_type*>(pidptr);
+      e->encode(data, *pid, scale, p);
+    } else {
+      e->encode(data, scale, p);
+    }
+    *out = (void*)new seal::Plaintext(p);
+  });
+}
+
+SEALError SEALCKKSEncoderDecode(SEALCKKSEncoder k, SEALPlaintext p,
+                                double* out) {
+  return guard([&] {
+    auto* e = static_cast<seal::CKKSEncoder*>(k);
+    auto* plain = static_cast<seal::Plaintext*>(p);
+    std::vector<double> data(1);
+    e->decode(*plain, data);
+    *out = data.at(0);
+  });
+}
+
+size_t SEALCKKSEncoderSlotCount(SEALCKKSEncoder k) {
   auto* e = static_cast<seal::CKKSEncoder*>(k);
-  std::vector<double> data{num};
-  seal::Plaintext p;
-  if (pidptr != nullptr) {
-    auto* pid = static_cast<seal::parms_id_type*>(pidptr);
-    e->encode(data, *pid, scale, p);
-  } else {
-    e->encode(data, scale, p);
+  return e->slot_count();
+}
+
+SEALError SEALCKKSEncoderEncodeVector(SEALCKKSEncoder k, double* values,
+                                      size_t count, SEALParmsID pidptr,
+                                      double scale, SEALPlaintext* out) {
+  return guard([&] {
+    auto* e = static_cast<seal::CKKSEncoder*>(k);
+    std::vector<double> data(values, values + count);
+    seal::Plaintext p;
+    if (pidptr != nullptr) {
+      auto* pid = static_cast<seal::parms_id_type*>(pidptr);
+      e->encode(data, *pid, scale, p);
+    } else {
+      e->encode(data, scale, p);
+    }
+    *out = (void*)new seal::Plaintext(p);
+  });
+}
+
+SEALError SEALCKKSEncoderEncodeComplexVector(SEALCKKSEncoder k, double* values,
+                                             size_t count, SEALParmsID pidptr,
+                                             double scale, SEALPlaintext* out) {
+  return guard([&] {
+    auto* e = static_cast<seal::CKKSEncoder*>(k);
+    std::vector<std::complex<double>> data(count);
+    for (size_t i = 0; i < count; i++) {
+      data[i] = std::complex<double>(values[2 * i], values[2 * i + 1]);
+    }
+    seal::Plaintext p;
+    if (pidptr != nullptr) {
+      auto* pid = static_cast<seal::parms_id_type*>(pidptr);
+      e->encode(data, *pid, scale, p);
+    } else {
+      e->encode(data, scale, p);
+    }
+    *out = (void*)new seal::Plaintext(p);
+  });
+}
+
+SEALError SEALCKKSEncoderDecodeVector(SEALCKKSEncoder k, SEALPlaintext p,
+                                      double* out) {
+  return guard([&] {
+    auto* e = static_cast<seal::CKKSEncoder*>(k);
+    auto* plain = static_cast<seal::Plaintext*>(p);
+    std::vector<double> data;
+    e->decode(*plain, data);
+    std::copy(data.begin(), data.end(), out);
+  });
+}
+
+SEALError SEALCKKSEncoderDecodeComplexVector(SEALCKKSEncoder k,
+                                             SEALPlaintext p, double* out) {
+  return guard([&] {
+    auto* e = static_cast<seal::CKKSEncoder*>(k);
+    auto* plain = static_cast<seal::Plaintext*>(p);
+    std::vector<std::complex<double>> data;
+    e->decode(*plain, data);
+    for (const auto& v : data) {
+      *out++ = v.real();
+      *out++ = v.imag();
+    }
+  });
+}
+
+SEALError SEALBatchEncoderInit(SEALContext c, SEALBatchEncoder* out) {
+  return guard([&] {
+    auto* ctx = static_cast<std::shared_ptr<seal::SEALContext>*>(c);
+    *out = (void*)new seal::BatchEncoder(*ctx);
+  });
+}
+
+void SEALBatchEncoderDelete(SEALBatchEncoder k) {
+  delete static_cast<seal::BatchEncoder*>(k);
+}
+
+size_t SEALBatchEncoderSlotCount(SEALBatchEncoder k) {
+  auto* e = static_cast<seal::BatchEncoder*>(k);
+  return e->slot_count();
+}
+
+SEALError SEALBatchEncoderEncodeUint64(SEALBatchEncoder k, uint64_t* values,
+                                       size_t count, SEALPlaintext* out) {
+  return guard([&] {
+    auto* e = static_cast<seal::BatchEncoder*>(k);
+    std::vector<std::uint64_t> data(values, values + count);
+    seal::Plaintext p;
+    e->encode(data, p);
+    *out = (void*)new seal::Plaintext(p);
+  });
+}
+
+SEALError SEALBatchEncoderEncodeInt64(SEALBatchEncoder k, int64_t* values,
+                                      size_t count, SEALPlaintext* out) {
+  return guard([&] {
+    auto* e = static_cast<seal::BatchEncoder*>(k);
+    std::vector<std::int64_t> data(values, values + count);
+    seal::Plaintext p;
+    e->encode(data, p);
+    *out = (void*)new seal::Plaintext(p);
+  });
+}
+
+SEALError SEALBatchEncoderDecodeUint64(SEALBatchEncoder k, SEALPlaintext p,
+                                       uint64_t* out) {
+  return guard([&] {
+    auto* e = static_cast<seal::BatchEncoder*>(k);
+    auto* plain = static_cast<seal::Plaintext*>(p);
+    std::vector<std::uint64_t> data;
+    e->decode(*plain, data);
+    std::copy(data.begin(), data.end(), out);
+  });
+}
+
+SEALError SEALBatchEncoderDecodeInt64(SEALBatchEncoder k, SEALPlaintext p,
+                                      int64_t* out) {
+  return guard([&] {
+    auto* e = static_cast<seal::BatchEncoder*>(k);
+    auto* plain = static_cast<seal::Plaintext*>(p);
+    std::vector<std::int64_t> data;
+    e->decode(*plain, data);
+    std::copy(data.begin(), data.end(), out);
+  });
+}
+
+SEALError SEALEncryptionParametersSave(SEALEncryptionParameters p, char** data,
+                                       size_t* size) {
+  return guard([&] {
+    std::ostringstream stream;
+    seal::EncryptionParameters::Save(
+        *static_cast<seal::EncryptionParameters*>(p), stream);
+    copyBuffer(stream.str(), data, size);
+  });
+}
+
+SEALError SEALEncryptionParametersLoad(char* data, size_t size,
+                                       SEALEncryptionParameters* out) {
+  return guard([&] {
+    std::istringstream stream(std::string(data, size));
+    stream.exceptions(std::ios_base::failbit | std::ios_base::badbit);
+    try {
+      *out = (void*)new seal::EncryptionParameters(
+          seal::EncryptionParameters::Load(stream));
+    } catch (const std::ios_base::failure&) {
+      throw std::invalid_argument("serialized data is truncated or corrupt");
+    }
+  });
+}
+
+SEALError SEALPublicKeySave(SEALPublicKey k, char** data, size_t* size) {
+  return saveObject<seal::PublicKey>(k, data, size);
+}
+
+SEALError SEALPublicKeyLoad(SEALContext c, char* data, size_t size,
+                            SEALPublicKey* out) {
+  return loadObject<seal::PublicKey>(c, data, size, out,
+                                     validateKey<seal::PublicKey>);
+}
+
+SEALError SEALSecretKeySave(SEALSecretKey k, char** data, size_t* size) {
+  return saveObject<seal::SecretKey>(k, data, size);
+}
+
+SEALError SEALSecretKeyLoad(SEALContext c, char* data, size_t size,
+                            SEALSecretKey* out) {
+  return loadObject<seal::SecretKey>(c, data, size, out,
+                                     validateKey<seal::SecretKey>);
+}
+
+SEALError SEALRelinKeysSave(SEALRelinKeys k, char** data, size_t* size) {
+  return saveObject<seal::RelinKeys>(k, data, size);
+}
+
+SEALError SEALRelinKeysLoad(SEALContext c, char* data, size_t size,
+                            SEALRelinKeys* out) {
+  return loadObject<seal::RelinKeys>(c, data, size, out,
+                                     validateKey<seal::RelinKeys>);
+}
+
+SEALError SEALGaloisKeysSave(SEALGaloisKeys k, char** data, size_t* size) {
+  return saveObject<seal::GaloisKeys>(k, data, size);
+}
+
+SEALError SEALGaloisKeysLoad(SEALContext c, char* data, size_t size,
+                             SEALGaloisKeys* out) {
+  return loadObject<seal::GaloisKeys>(c, data, size, out,
+                                      validateKey<seal::GaloisKeys>);
+}
+
+SEALError SEALCiphertextSave(SEALCiphertext k, char** data, size_t* size) {
+  return saveObject<seal::Ciphertext>(k, data, size);
+}
+
+SEALError SEALCiphertextLoad(SEALContext c, char* data, size_t size,
+                             SEALCiphertext* out) {
+  return loadObject<seal::Ciphertext>(
+      c, data, size, out, [](const ContextPtr& ctx, const seal::Ciphertext& ct) {
+        auto data = ctx->context_data(ct.parms_id());
+        if (!data ||
+            data->parms().poly_modulus_degree() != ct.poly_modulus_degree() ||
+            data->parms().coeff_modulus().size() != ct.coeff_mod_count()) {
+          throw std::invalid_argument(
+              "ciphertext is not valid for encryption parameters");
+        }
+      });
+}
+
+SEALError SEALPlaintextSave(SEALPlaintext k, char** data, size_t* size) {
+  return saveObject<seal::Plaintext>(k, data, size);
+}
+
+SEALError SEALPlaintextLoad(SEALContext c, char* data, size_t size,
+                            SEALPlaintext* out) {
+  return loadObject<seal::Plaintext>(
+      c, data, size, out, [](const ContextPtr& ctx, const seal::Plaintext& pt) {
+        if (pt.is_ntt_form()) {
+          if (!ctx->context_data(pt.parms_id())) {
+            throw std::invalid_argument(
+                "plaintext is not valid for encryption parameters");
+          }
+        } else if (pt.coeff_count() >
+                   ctx->context_data()->parms().poly_modulus_degree()) {
+          throw std::invalid_argument(
+              "plaintext is not valid for encryption parameters");
+        }
+      });
+}
+
+size_t SEALCiphertextByteCount(SEALCiphertext k) {
+  return byteCount(*static_cast<seal::Ciphertext*>(k));
+}
+
+size_t SEALPlaintextByteCount(SEALPlaintext k) {
+  return static_cast<seal::Plaintext*>(k)->capacity() * sizeof(std::uint64_t);
+}
+
+size_t SEALPublicKeyByteCount(SEALPublicKey k) {
+  return byteCount(static_cast<seal::PublicKey*>(k)->data());
+}
+
+size_t SEALSecretKeyByteCount(SEALSecretKey k) {
+  return static_cast<seal::SecretKey*>(k)->data().capacity() *
+         sizeof(std::uint64_t);
+}
+
+size_t SEALRelinKeysByteCount(SEALRelinKeys k) {
+  return byteCount(static_cast<seal::RelinKeys*>(k)->data());
+}
+
+size_t SEALGaloisKeysByteCount(SEALGaloisKeys k) {
+  return byteCount(static_cast<seal::GaloisKeys*>(k)->data());
+}
+
+SEALParmsID SEALPlaintextParmsID(SEALPlaintext k) {
+  auto* p = static_cast<seal::Plaintext*>(k);
+  return (void*)new seal::parms_id_type(p->parms_id());
+}
+
+SEALParmsID SEALContextFirstParmsID(SEALContext c) {
+  auto& ctx = *static_cast<ContextPtr*>(c);
+  return (void*)new seal::parms_id_type(ctx->first_parms_id());
+}
+
+SEALParmsID SEALContextLastParmsID(SEALContext c) {
+  auto& ctx = *static_cast<ContextPtr*>(c);
+  return (void*)new seal::parms_id_type(ctx->last_parms_id());
+}
+
+int SEALContextChainIndex(SEALContext c, SEALParmsID pptr) {
+  auto& ctx = *static_cast<ContextPtr*>(c);
+  auto* p = static_cast<seal::parms_id_type*>(pptr);
+  int index = -1;
+  int count = 0;
+  for (auto data = ctx->context_data(); data; data = data->next_context_data()) {
+    if (data->parms().parms_id() == *p) {
+      index = count;
+    }
+    count++;
   }
-  return (void*)new seal::Plaintext(p);
+  return index < 0 ? -1 : count - 1 - index;
 }
 
-double SEALCKKSEncoderDecode(SEALCKKSEncoder k, SEALPlaintext p) {
-  auto* e = static_cast<seal::CKKSEncoder*>(k);
-  auto* plain = static_cast<seal::Plaintext*>(p);
-  std::vector<double> data(1);
-  e->decode(*plain, data);
-  return data.at(0);
+size_t SEALContextCoeffModulusCount(SEALContext c, SEALParmsID pptr) {
+  auto& ctx = *static_cast<ContextPtr*>(c);
+  auto data = ctx->context_data(*static_cast<seal::parms_id_type*>(pptr));
+  return data ? data->parms().coeff_modulus().size() : 0;
+}
+
+void SEALContextCoeffModulus(SEALContext c, SEALParmsID pptr, uint64_t* out) {
+  auto& ctx = *static_cast<ContextPtr*>(c);
+  auto data = ctx->context_data(*static_cast<seal::parms_id_type*>(pptr));
+  for (const auto& m : data->parms().coeff_modulus()) {
+    *out++ = m.value();
+  }
+}
+
+namespace {
+
+// ChaCha20 keystream, as specified in RFC 8439, used to expand the public
+// half of symmetric ciphertexts from a seed. Seeds are used as keys with a
+// zero nonce and a block counter starting at zero.
+class ChaCha20 {
+ public:
+  using result_type = std::uint64_t;
+  using Seed = std::array<std::uint8_t, 32>;
+
+  explicit ChaCha20(const Seed& seed, std::uint32_t counter = 0,
+                    const std::uint8_t* nonce = nullptr) {
+    static const std::uint32_t sigma[4] = {0x61707865, 0x3320646e, 0x79622d32,
+                                           0x6b206574};
+    std::copy(sigma, sigma + 4, state_);
+    for (int i = 0; i < 8; i++) {
+      state_[4 + i] = load(&seed[4 * i]);
+    }
+    state_[12] = counter;
+    for (int i = 0; i < 3; i++) {
+      state_[13 + i] = nonce ? load(nonce + 4 * i) : 0;
+    }
+  }
+
+  static Seed RandomSeed() {
+    std::random_device rd;
+    Seed seed;
+    for (auto& b : seed) {
+      b = static_cast<std::uint8_t>(rd());
+    }
+    return seed;
+  }
+
+  static constexpr result_type min() { return 0; }
+  static constexpr result_type max() { return ~result_type(0); }
+
+  result_type operator()() {
+    if (pos_ >= 16) {
+      refill();
+    }
+    result_type r = result_type(block_[pos_]) << 32 | block_[pos_ + 1];
+    pos_ += 2;
+    return r;
+  }
+
+  // Uniform returns a uniform value below q by rejection sampling.
+  std::uint64_t Uniform(std::uint64_t q) {
+    std::uint64_t bound = (max() / q) * q;
+    std::uint64_t r;
+    do {
+      r = (*this)();
+    } while (r >= bound);
+    return r % q;
+  }
+
+  // Block writes the next 64-byte keystream block in RFC 8439 byte order.
+  void Block(std::uint8_t* out) {
+    refill();
+    for (int i = 0; i < 16; i++) {
+      for (int j = 0; j < 4; j++) {
+        out[4 * i + j] = static_cast<std::uint8_t>(block_[i] >> (8 * j));
+      }
+    }
+    pos_ = 16;
+  }
+
+ private:
+  static std::uint32_t load(const std::uint8_t* p) {
+    return std::uint32_t(p[0]) | std::uint32_t(p[1]) << 8 |
+           std::uint32_t(p[2]) << 16 | std::uint32_t(p[3]) << 24;
+  }
+
+  static std::uint32_t rotl(std::uint32_t v, int c) {
+    return (v << c) | (v >> (32 - c));
+  }
+
+  static void quarter(std::uint32_t* x, int a, int b, int c, int d) {
+    x[a] += x[b];
+    x[d] = rotl(x[d] ^ x[a], 16);
+    x[c] += x[d];
+    x[b] = rotl(x[b] ^ x[c], 12);
+    x[a] += x[b];
+    x[d] = rotl(x[d] ^ x[a], 8);
+    x[c] += x[d];
+    x[b] = rotl(x[b] ^ x[c], 7);
+  }
+
+  void refill() {
+    std::uint32_t x[16];
+    std::copy(state_, state_ + 16, x);
+    for (int i = 0; i < 10; i++) {
+      quarter(x, 0, 4, 8, 12);
+      quarter(x, 1, 5, 9, 13);
+      quarter(x, 2, 6, 10, 14);
+      quarter(x, 3, 7, 11, 15);
+      quarter(x, 0, 5, 10, 15);
+      quarter(x, 1, 6, 11, 12);
+      quarter(x, 2, 7, 8, 13);
+      quarter(x, 3, 4, 9, 14);
+    }
+    for (int i = 0; i < 16; i++) {
+      block_[i] = x[i] + state_[i];
+    }
+    state_[12]++;
+    pos_ = 0;
+  }
+
+  std::uint32_t state_[16];
+  std::uint32_t block_[16];
+  int pos_ = 16;
+};
+
+struct SymmetricEncryptor {
+  ContextPtr context;
+  seal::SecretKey key;
+  seal::Evaluator evaluator;
+};
+
+// SeededCiphertext is a ciphertext with its second polynomial replaced by
+// the seed it was expanded from.
+struct SeededCiphertext {
+  seal::Ciphertext c0;
+  ChaCha20::Seed seed;
+};
+
+// expandA writes the uniform polynomial a, in NTT form, for each prime at
+// the given level.
+void expandA(const seal::SEALContext::ContextData& data,
+             const ChaCha20::Seed& seed, std::uint64_t* out) {
+  ChaCha20 prng(seed);
+  auto& parms = data.parms();
+  std::size_t n = parms.poly_modulus_degree();
+  auto& moduli = parms.coeff_modulus();
+  for (std::size_t i = 0; i < moduli.size(); i++) {
+    for (std::size_t j = 0; j < n; j++) {
+      out[i * n + j] = prng.Uniform(moduli[i].value());
+    }
+  }
+}
+
+// toCoefficients leaves poly in coefficient form for schemes that do not
+// keep ciphertexts in NTT form.
+void toCoefficients(const seal::SEALContext::ContextData& data,
+                    std::uint64_t* poly) {
+  std::size_t n = data.parms().poly_modulus_degree();
+  for (std::size_t i = 0; i < data.parms().coeff_modulus().size(); i++) {
+    seal::util::inverse_ntt_negacyclic_harvey(poly + i * n,
+                                              data.small_ntt_tables()[i]);
+  }
+}
+
+// encryptZero returns (-(a*s + e), a) at parms_id, with a expanded from seed.
+// This mirrors how SEAL builds a public key.
+seal::Ciphertext encryptZero(SymmetricEncryptor& e,
+                             const seal::parms_id_type& parms_id,
+                             const ChaCha20::Seed& seed) {
+  auto data = e.context->context_data(parms_id);
+  if (!data) {
+    throw std::invalid_argument(
+        "parms_id is not valid for encryption parameters");
+  }
+  auto& parms = data->parms();
+  std::size_t n = parms.poly_modulus_degree();
+  auto& moduli = parms.coeff_modulus();
+
+  seal::Ciphertext ct;
+  ct.resize(e.context, parms_id, 2);
+  std::uint64_t* c0 = ct.data(0);
+  std::uint64_t* c1 = ct.data(1);
+  expandA(*data, seed, c1);
+
+  // The secret key is stored in NTT form at the first level; lower levels
+  // use a prefix of its primes.
+  const std::uint64_t* s = e.key.data().data();
+  // The noise is drawn the way SEAL's Encryptor draws it, from the
+  // parameters' random generator and SEAL's clipped normal sampler.
+  auto factory = parms.random_generator();
+  if (!factory) {
+    factory = seal::UniformRandomGeneratorFactory::default_factory();
+  }
+  seal::RandomToStandardAdapter engine(factory->create());
+  seal::util::ClippedNormalDistribution dist(
+      0, parms.noise_standard_deviation(), parms.noise_max_deviation());
+  std::vector<std::int64_t> err(n);
+  for (auto& v : err) {
+    v = static_cast<std::int64_t>(dist(engine));
+  }
+
+  for (std::size_t i = 0; i < moduli.size(); i++) {
+    std::uint64_t q = moduli[i].value();
+    std::uint64_t* ci = c0 + i * n;
+    for (std::size_t j = 0; j < n; j++) {
+      ci[j] = err[j] < 0 ? q - std::uint64_t(-err[j]) : std::uint64_t(err[j]);
+    }
+    seal::util::ntt_negacyclic_harvey(ci, data->small_ntt_tables()[i]);
+    const std::uint64_t* ai = c1 + i * n;
+    const std::uint64_t* si = s + i * n;
+    for (std::size_t j = 0; j < n; j++) {
+      unsigned __int128 as = (unsigned __int128)ai[j] * si[j];
+      std::uint64_t sum = std::uint64_t((as + ci[j]) % q);
+      ci[j] = sum ? q - sum : 0;
+    }
+  }
+
+  bool ntt = parms.scheme() == seal::scheme_type::CKKS;
+  if (!ntt) {
+    toCoefficients(*data, c0);
+    toCoefficients(*data, c1);
+  }
+  ct.is_ntt_form() = ntt;
+  return ct;
+}
+
+seal::Ciphertext encryptSymmetric(SymmetricEncryptor& e,
+                                  seal::Plaintext& plain,
+                                  const ChaCha20::Seed& seed) {
+  auto parms_id = plain.is_ntt_form() ? plain.parms_id()
+                                      : e.context->first_parms_id();
+  auto ct = encryptZero(e, parms_id, seed);
+  if (plain.is_ntt_form()) {
+    ct.scale() = plain.scale();
+  }
+  e.evaluator.add_plain_inplace(ct, plain);
+  return ct;
+}
+
+}  // namespace
+
+void SEALChaCha20Block(const uint8_t* key, uint32_t counter,
+                       const uint8_t* nonce, uint8_t* out) {
+  ChaCha20::Seed seed;
+  std::copy(key, key + seed.size(), seed.begin());
+  ChaCha20(seed, counter, nonce).Block(out);
+}
+
+SEALError SEALSymmetricExpandSeed(SEALContext c, SEALParmsID pptr,
+                                  const uint8_t* s, uint64_t* out) {
+  return guard([&] {
+    auto& ctx = *static_cast<ContextPtr*>(c);
+    auto data = ctx->context_data(*static_cast<seal::parms_id_type*>(pptr));
+    if (!data) {
+      throw std::invalid_argument(
+          "parms_id is not valid for encryption parameters");
+    }
+    ChaCha20::Seed seed;
+    std::copy(s, s + seed.size(), seed.begin());
+    expandA(*data, seed, out);
+  });
+}
+
+SEALError SEALSymmetricEncryptorInit(SEALContext c, SEALSecretKey k,
+                                     SEALSymmetricEncryptor* out) {
+  return guard([&] {
+    auto& ctx = *static_cast<ContextPtr*>(c);
+    auto* key = static_cast<seal::SecretKey*>(k);
+    if (key->parms_id() != ctx->first_parms_id()) {
+      throw std::invalid_argument(
+          "secret key is not valid for encryption parameters");
+    }
+    *out = (void*)new SymmetricEncryptor{ctx, *key, seal::Evaluator(ctx)};
+  });
+}
+
+void SEALSymmetricEncryptorDelete(SEALSymmetricEncryptor k) {
+  delete static_cast<SymmetricEncryptor*>(k);
+}
+
+SEALError SEALSymmetricEncryptorEncrypt(SEALSymmetricEncryptor k,
+                                        SEALPlaintext p, SEALCiphertext* out) {
+  return guard([&] {
+    auto* e = static_cast<SymmetricEncryptor*>(k);
+    auto* plain = static_cast<seal::Plaintext*>(p);
+    *out = (void*)new seal::Ciphertext(
+        encryptSymmetric(*e, *plain, ChaCha20::RandomSeed()));
+  });
+}
+
+SEALError SEALSymmetricEncryptorEncryptZero(SEALSymmetricEncryptor k,
+                                            SEALParmsID pptr,
+                                            SEALCiphertext* out) {
+  return guard([&] {
+    auto* e = static_cast<SymmetricEncryptor*>(k);
+    auto parms_id = pptr ? *static_cast<seal::parms_id_type*>(pptr)
+                         : e->context->first_parms_id();
+    *out = (void*)new seal::Ciphertext(
+        encryptZero(*e, parms_id, ChaCha20::RandomSeed()));
+  });
+}
+
+SEALError SEALSymmetricEncryptorEncryptSeeded(SEALSymmetricEncryptor k,
+                                              SEALPlaintext p,
+                                              SEALSeededCiphertext* out) {
+  return guard([&] {
+    auto* e = static_cast<SymmetricEncryptor*>(k);
+    auto* plain = static_cast<seal::Plaintext*>(p);
+    auto seeded = std::unique_ptr<SeededCiphertext>(new SeededCiphertext());
+    seeded->seed = ChaCha20::RandomSeed();
+    seeded->c0 = encryptSymmetric(*e, *plain, seeded->seed);
+    seeded->c0.resize(e->context, seeded->c0.parms_id(), 1);
+    *out = (void*)seeded.release();
+  });
+}
+
+void SEALSeededCiphertextDelete(SEALSeededCiphertext k) {
+  delete static_cast<SeededCiphertext*>(k);
+}
+
+SEALError SEALSeededCiphertextExpand(SEALContext c, SEALSeededCiphertext k,
+                                     SEALCiphertext* out) {
+  return guard([&] {
+    auto& ctx = *static_cast<ContextPtr*>(c);
+    auto* seeded = static_cast<SeededCiphertext*>(k);
+    auto data = ctx->context_data(seeded->c0.parms_id());
+    if (!data) {
+      throw std::invalid_argument(
+          "ciphertext is not valid for encryption parameters");
+    }
+    auto ct = std::unique_ptr<seal::Ciphertext>(
+        new seal::Ciphertext(seeded->c0));
+    ct->resize(ctx, ct->parms_id(), 2);
+    expandA(*data, seeded->seed, ct->data(1));
+    if (!ct->is_ntt_form()) {
+      toCoefficients(*data, ct->data(1));
+    }
+    *out = (void*)ct.release();
+  });
+}
+
+SEALError SEALSeededCiphertextSave(SEALSeededCiphertext k, char** data,
+                                   size_t* size) {
+  return guard([&] {
+    auto* seeded = static_cast<SeededCiphertext*>(k);
+    std::ostringstream stream;
+    stream.write(reinterpret_cast<const char*>(seeded->seed.data()),
+                 seeded->seed.size());
+    seeded->c0.save(stream);
+    copyBuffer(stream.str(), data, size);
+  });
+}
+
+SEALError SEALSeededCiphertextLoad(SEALContext c, char* data, size_t size,
+                                   SEALSeededCiphertext* out) {
+  return guard([&] {
+    std::istringstream stream(std::string(data, size));
+    stream.exceptions(std::ios_base::failbit | std::ios_base::badbit);
+    auto seeded = std::unique_ptr<SeededCiphertext>(new SeededCiphertext());
+    try {
+      stream.read(reinterpret_cast<char*>(seeded->seed.data()),
+                  seeded->seed.size());
+      seeded->c0.load(stream);
+    } catch (const std::ios_base::failure&) {
+      throw std::invalid_argument("serialized data is truncated or corrupt");
+    }
+    if (seeded->c0.size() != 1) {
+      throw std::invalid_argument("serialized data is truncated or corrupt");
+    }
+    if (c != nullptr) {
+      auto& ctx = *static_cast<ContextPtr*>(c);
+      auto cd = ctx->context_data(seeded->c0.parms_id());
+      if (!cd ||
+          cd->parms().poly_modulus_degree() !=
+              seeded->c0.poly_modulus_degree() ||
+          cd->parms().coeff_modulus().size() != seeded->c0.coeff_mod_count()) {
+        throw std::invalid_argument(
+            "ciphertext is not valid for encryption parameters");
+      }
+    }
+    *out = (void*)seeded.release();
+  });
+}
+
+size_t SEALSeededCiphertextByteCount(SEALSeededCiphertext k) {
+  auto* seeded = static_cast<SeededCiphertext*>(k);
+  return byteCount(seeded->c0) + seeded->seed.size();
 }
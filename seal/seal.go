@@ -6,351 +6,711 @@ package seal
 import "C"
 
 import (
+	"fmt"
 	"math"
 	"runtime"
 )
 
 type EncryptionParams struct {
 	ptr C.SEALEncryptionParameters
+	native
+	security SecurityLevel
 }
 
-func NewEncryptionParamsBFV() *EncryptionParams {
-	return newEncryptionParams(C.SEALEncryptionParametersBFV())
+func NewEncryptionParamsBFV() (*EncryptionParams, error) {
+	var ptr C.SEALEncryptionParameters
+	if err := checkError("NewEncryptionParamsBFV", C.SEALEncryptionParametersBFV(&ptr)); err != nil {
+		return nil, err
+	}
+	return newEncryptionParams(ptr), nil
 }
 
-func NewEncryptionParamsCKKS() *EncryptionParams {
-	return newEncryptionParams(C.SEALEncryptionParametersCKKS())
+func NewEncryptionParamsCKKS() (*EncryptionParams, error) {
+	var ptr C.SEALEncryptionParameters
+	if err := checkError("NewEncryptionParamsCKKS", C.SEALEncryptionParametersCKKS(&ptr)); err != nil {
+		return nil, err
+	}
+	return newEncryptionParams(ptr), nil
 }
 
 func newEncryptionParams(ptr C.SEALEncryptionParameters) *EncryptionParams {
 	c := &EncryptionParams{
-		ptr: ptr,
+		ptr:      ptr,
+		security: Security128,
 	}
-	runtime.SetFinalizer(c, func(c *EncryptionParams) {
-		C.SEALEncryptionParametersDelete(c.ptr)
-		c.ptr = nil
-	})
+	runtime.SetFinalizer(c, (*EncryptionParams).free)
+	track(c)
 	return c
 }
 
 type Context struct {
 	ptr C.SEALContext
+	native
 }
 
-func NewContext(params *EncryptionParams) *Context {
+func NewContext(params *EncryptionParams) (*Context, error) {
+	if err := checkOpen("NewContext", params); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALContext
+	err := checkError("NewContext", C.SEALContextInit(params.ptr, &ptr))
+	runtime.KeepAlive(params)
+	if err != nil {
+		return nil, err
+	}
 	c := &Context{
-		ptr: C.SEALContextInit(params.ptr),
+		ptr: ptr,
 	}
-	runtime.SetFinalizer(c, func(c *Context) {
-		C.SEALContextDelete(c.ptr)
-		c.ptr = nil
-	})
-	return c
+	runtime.SetFinalizer(c, (*Context).free)
+	track(c)
+	if err := c.validate(params.security); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
 }
 
 type KeyGenerator struct {
 	ptr C.SEALKeyGenerator
+	native
 }
 
-func NewKeyGenerator(c *Context) *KeyGenerator {
+func NewKeyGenerator(c *Context) (*KeyGenerator, error) {
+	if err := checkOpen("NewKeyGenerator", c); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALKeyGenerator
+	err := checkError("NewKeyGenerator", C.SEALKeyGeneratorInit(c.ptr, &ptr))
+	runtime.KeepAlive(c)
+	if err != nil {
+		return nil, err
+	}
 	g := &KeyGenerator{
-		ptr: C.SEALKeyGeneratorInit(c.ptr),
+		ptr: ptr,
 	}
-	runtime.SetFinalizer(g, func(g *KeyGenerator) {
-		C.SEALKeyGeneratorDelete(g.ptr)
-		g.ptr = nil
-	})
-	return g
+	runtime.SetFinalizer(g, (*KeyGenerator).free)
+	track(g)
+	return g, nil
 }
 
 type PublicKey struct {
 	ptr C.SEALPublicKey
+	native
 }
 
-func (g *KeyGenerator) PublicKey() *PublicKey {
+func (g *KeyGenerator) PublicKey() (*PublicKey, error) {
+	if err := checkOpen("KeyGenerator.PublicKey", g); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALPublicKey
+	err := checkError("KeyGenerator.PublicKey", C.SEALKeyGeneratorPublicKey(g.ptr, &ptr))
+	runtime.KeepAlive(g)
+	if err != nil {
+		return nil, err
+	}
 	k := &PublicKey{
-		ptr: C.SEALKeyGeneratorPublicKey(g.ptr),
+		ptr: ptr,
 	}
-	runtime.SetFinalizer(k, func(k *PublicKey) {
-		C.SEALPublicKeyDelete(k.ptr)
-		k.ptr = nil
-	})
-	return k
+	runtime.SetFinalizer(k, (*PublicKey).free)
+	track(k)
+	return k, nil
 }
 
 type SecretKey struct {
 	ptr C.SEALSecretKey
+	native
 }
 
-func (g *KeyGenerator) SecretKey() *SecretKey {
+func (g *KeyGenerator) SecretKey() (*SecretKey, error) {
+	if err := checkOpen("KeyGenerator.SecretKey", g); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALSecretKey
+	err := checkError("KeyGenerator.SecretKey", C.SEALKeyGeneratorSecretKey(g.ptr, &ptr))
+	runtime.KeepAlive(g)
+	if err != nil {
+		return nil, err
+	}
 	k := &SecretKey{
-		ptr: C.SEALKeyGeneratorSecretKey(g.ptr),
+		ptr: ptr,
 	}
-	runtime.SetFinalizer(k, func(k *SecretKey) {
-		C.SEALSecretKeyDelete(k.ptr)
-		k.ptr = nil
-	})
-	return k
+	runtime.SetFinalizer(k, (*SecretKey).free)
+	track(k)
+	return k, nil
 }
 
 type RelinKeys struct {
 	ptr C.SEALRelinKeys
+	native
 }
 
-func (g *KeyGenerator) RelinKeys(decomposition_bit_count, num int) *RelinKeys {
+func (g *KeyGenerator) RelinKeys(decomposition_bit_count, num int) (*RelinKeys, error) {
+	if err := checkOpen("KeyGenerator.RelinKeys", g); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALRelinKeys
+	err := checkError("KeyGenerator.RelinKeys", C.SEALKeyGeneratorRelinKeys(g.ptr, C.int(decomposition_bit_count), C.int(num), &ptr))
+	runtime.KeepAlive(g)
+	if err != nil {
+		return nil, err
+	}
 	k := &RelinKeys{
-		ptr: C.SEALKeyGeneratorRelinKeys(g.ptr, C.int(decomposition_bit_count), C.int(num)),
+		ptr: ptr,
 	}
-	runtime.SetFinalizer(k, func(k *RelinKeys) {
-		C.SEALRelinKeysDelete(k.ptr)
-		k.ptr = nil
-	})
-	return k
+	runtime.SetFinalizer(k, (*RelinKeys).free)
+	track(k)
+	return k, nil
 }
 
 type Encryptor struct {
 	ptr C.SEALEncryptor
+	native
 }
 
-func NewEncryptor(c *Context, key *PublicKey) *Encryptor {
+func NewEncryptor(c *Context, key *PublicKey) (*Encryptor, error) {
+	if err := checkOpen("NewEncryptor", c, key); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALEncryptor
+	err := checkError("NewEncryptor", C.SEALEncryptorInit(c.ptr, key.ptr, &ptr))
+	runtime.KeepAlive(c)
+	runtime.KeepAlive(key)
+	if err != nil {
+		return nil, err
+	}
 	e := &Encryptor{
-		ptr: C.SEALEncryptorInit(c.ptr, key.ptr),
+		ptr: ptr,
 	}
-	runtime.SetFinalizer(e, func(e *Encryptor) {
-		C.SEALEncryptorDelete(e.ptr)
-		e.ptr = nil
-	})
-	return e
+	runtime.SetFinalizer(e, (*Encryptor).free)
+	track(e)
+	return e, nil
 }
 
-func (e *Encryptor) Encrypt(p *Plaintext) *Ciphertext {
-	return newCiphertext(C.SEALEncryptorEncrypt(e.ptr, p.ptr))
+func (e *Encryptor) Encrypt(p *Plaintext) (*Ciphertext, error) {
+	if err := checkOpen("Encryptor.Encrypt", e, p); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALCiphertext
+	err := checkError("Encryptor.Encrypt", C.SEALEncryptorEncrypt(e.ptr, p.ptr, &ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(p)
+	if err != nil {
+		return nil, err
+	}
+	return newCiphertext(ptr), nil
 }
 
 type Ciphertext struct {
 	ptr C.SEALCiphertext
+	native
 }
 
 func newCiphertext(ptr C.SEALCiphertext) *Ciphertext {
 	e := &Ciphertext{
 		ptr: ptr,
 	}
-	runtime.SetFinalizer(e, func(e *Ciphertext) {
-		C.SEALCiphertextDelete(e.ptr)
-		e.ptr = nil
-	})
+	runtime.SetFinalizer(e, (*Ciphertext).free)
+	track(e)
 	return e
 }
 
+// Copy returns a deep copy of c. The copy of a closed ciphertext is closed
+// too.
 func (c *Ciphertext) Copy() *Ciphertext {
-	return newCiphertext(C.SEALCiphertextCopy(c.ptr))
+	if c.closed() {
+		return &Ciphertext{}
+	}
+	cp := newCiphertext(C.SEALCiphertextCopy(c.ptr))
+	runtime.KeepAlive(c)
+	return cp
 }
 
 func (c *Ciphertext) Scale() float64 {
-	return float64(C.SEALCiphertextScale(c.ptr))
+	if c.closed() {
+		return 0
+	}
+	scale := float64(C.SEALCiphertextScale(c.ptr))
+	runtime.KeepAlive(c)
+	return scale
+}
+
+// SetScale relabels the scale of c without touching its data, which changes
+// the decrypted value by the ratio of the scales.
+func (c *Ciphertext) SetScale(scale float64) {
+	if c.closed() {
+		return
+	}
+	C.SEALCiphertextSetScale(c.ptr, C.double(scale))
+	runtime.KeepAlive(c)
 }
 
 type ParmsID struct {
 	ptr C.SEALParmsID
+	native
+}
+
+// Size returns the number of polynomials in c: 2 for a fresh ciphertext and
+// 3 after a multiplication that has not been relinearized yet.
+func (c *Ciphertext) Size() int {
+	if c.closed() {
+		return 0
+	}
+	n := int(C.SEALCiphertextSize(c.ptr))
+	runtime.KeepAlive(c)
+	return n
+}
+
+func (c *Ciphertext) PolyModulusDegree() int {
+	if c.closed() {
+		return 0
+	}
+	n := int(C.SEALCiphertextPolyModulusDegree(c.ptr))
+	runtime.KeepAlive(c)
+	return n
+}
+
+// CoeffModulusCount returns the number of primes left in c's coefficient
+// modulus.
+func (c *Ciphertext) CoeffModulusCount() int {
+	if c.closed() {
+		return 0
+	}
+	n := int(C.SEALCiphertextCoeffModCount(c.ptr))
+	runtime.KeepAlive(c)
+	return n
+}
+
+// Level returns the number of rescales or modulus switches left. Each one
+// drops a prime, so it matches Context.ChainIndex without needing a context.
+func (c *Ciphertext) Level() int {
+	return c.CoeffModulusCount() - 1
+}
+
+// IsTransparent reports whether c is trivially decryptable, such as the
+// result of subtracting a ciphertext from itself.
+func (c *Ciphertext) IsTransparent() bool {
+	if c.closed() {
+		return false
+	}
+	ok := C.SEALCiphertextIsTransparent(c.ptr) != 0
+	runtime.KeepAlive(c)
+	return ok
+}
+
+// IsNTTForm reports whether c is stored in NTT form, as CKKS ciphertexts are.
+func (c *Ciphertext) IsNTTForm() bool {
+	if c.closed() {
+		return false
+	}
+	ok := C.SEALCiphertextIsNTTForm(c.ptr) != 0
+	runtime.KeepAlive(c)
+	return ok
+}
+
+func (c *Ciphertext) String() string {
+	if c.closed() {
+		return "Ciphertext(closed)"
+	}
+	return fmt.Sprintf("Ciphertext(size %d, level %d, scale 2^%.2f)", c.Size(), c.Level(), math.Log2(c.Scale()))
 }
 
+// ParmsID returns the parameters c is at, or an unset ParmsID if c is
+// closed.
 func (c *Ciphertext) ParmsID() *ParmsID {
+	if c.closed() {
+		return &ParmsID{}
+	}
+	id := newParmsID(C.SEALCiphertextParmsID(c.ptr))
+	runtime.KeepAlive(c)
+	return id
+}
+
+func newParmsID(ptr C.SEALParmsID) *ParmsID {
 	e := &ParmsID{
-		ptr: C.SEALCiphertextParmsID(c.ptr),
+		ptr: ptr,
 	}
-	runtime.SetFinalizer(e, func(e *ParmsID) {
-		C.SEALParmsIDDelete(e.ptr)
-		e.ptr = nil
-	})
+	runtime.SetFinalizer(e, (*ParmsID).free)
+	track(e)
 	return e
 }
 
+// Eq reports whether a and b identify the same parameters. Unset ParmsIDs
+// equal nothing.
 func (a *ParmsID) Eq(b *ParmsID) bool {
-	return C.SEALParmsIDEq(a.ptr, b.ptr) == 1
+	if a.closed() || b.closed() {
+		return false
+	}
+	eq := C.SEALParmsIDEq(a.ptr, b.ptr) == 1
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(b)
+	return eq
 }
 
 type Evaluator struct {
 	ptr C.SEALEvaluator
+	native
+	ctx *Context
 }
 
-func NewEvaluator(c *Context) *Evaluator {
+func NewEvaluator(c *Context) (*Evaluator, error) {
+	if err := checkOpen("NewEvaluator", c); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALEvaluator
+	err := checkError("NewEvaluator", C.SEALEvaluatorInit(c.ptr, &ptr))
+	runtime.KeepAlive(c)
+	if err != nil {
+		return nil, err
+	}
 	e := &Evaluator{
-		ptr: C.SEALEvaluatorInit(c.ptr),
+		ptr: ptr,
+		ctx: c,
 	}
-	runtime.SetFinalizer(e, func(e *Evaluator) {
-		C.SEALEvaluatorDelete(e.ptr)
-		e.ptr = nil
-	})
-	return e
+	runtime.SetFinalizer(e, (*Evaluator).free)
+	track(e)
+	return e, nil
 }
 
-func (e *Evaluator) Square(c *Ciphertext) *Ciphertext {
+func (e *Evaluator) Square(c *Ciphertext) (*Ciphertext, error) {
 	c = c.Copy()
-	e.SquareInplace(c)
-	return c
+	if err := e.SquareInplace(c); err != nil {
+		return nil, err
+	}
+	return c, nil
 }
 
-func (e *Evaluator) SquareInplace(c *Ciphertext) {
-	C.SEALEvaluatorSquareInplace(e.ptr, c.ptr)
+func (e *Evaluator) SquareInplace(c *Ciphertext) error {
+	if err := checkOpen("Evaluator.SquareInplace", e, c); err != nil {
+		return err
+	}
+	defer retrack(c)
+	err := checkError("Evaluator.SquareInplace", C.SEALEvaluatorSquareInplace(e.ptr, c.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(c)
+	return err
 }
 
-func (e *Evaluator) NegateInplace(c *Ciphertext) {
-	C.SEALEvaluatorNegateInplace(e.ptr, c.ptr)
+func (e *Evaluator) NegateInplace(c *Ciphertext) error {
+	if err := checkOpen("Evaluator.NegateInplace", e, c); err != nil {
+		return err
+	}
+	err := checkError("Evaluator.NegateInplace", C.SEALEvaluatorNegateInplace(e.ptr, c.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(c)
+	return err
 }
 
-func (e *Evaluator) Add(a *Ciphertext, b *Ciphertext) *Ciphertext {
+func (e *Evaluator) Add(a *Ciphertext, b *Ciphertext) (*Ciphertext, error) {
 	a = a.Copy()
-	e.AddInplace(a, b)
-	return a
-}
-
-func (e *Evaluator) AddInplace(a *Ciphertext, b *Ciphertext) {
-	C.SEALEvaluatorAddInplace(e.ptr, a.ptr, b.ptr)
+	if err := e.AddInplace(a, b); err != nil {
+		return nil, err
+	}
+	return a, nil
 }
 
-func (e *Evaluator) AddPlainInplace(a *Ciphertext, b *Plaintext) {
-	C.SEALEvaluatorAddPlainInplace(e.ptr, a.ptr, b.ptr)
+func (e *Evaluator) AddInplace(a *Ciphertext, b *Ciphertext) error {
+	if err := checkOpen("Evaluator.AddInplace", e, a, b); err != nil {
+		return err
+	}
+	// The sum has the size of the larger operand.
+	defer retrack(a)
+	err := checkError("Evaluator.AddInplace", C.SEALEvaluatorAddInplace(e.ptr, a.ptr, b.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(b)
+	return err
+}
+
+func (e *Evaluator) AddPlainInplace(a *Ciphertext, b *Plaintext) error {
+	if err := checkOpen("Evaluator.AddPlainInplace", e, a, b); err != nil {
+		return err
+	}
+	err := checkError("Evaluator.AddPlainInplace", C.SEALEvaluatorAddPlainInplace(e.ptr, a.ptr, b.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(b)
+	return err
 }
 
-func (e *Evaluator) Sub(a *Ciphertext, b *Ciphertext) *Ciphertext {
+func (e *Evaluator) Sub(a *Ciphertext, b *Ciphertext) (*Ciphertext, error) {
 	a = a.Copy()
-	e.SubInplace(a, b)
-	return a
-}
-
-func (e *Evaluator) SubInplace(a *Ciphertext, b *Ciphertext) {
-	C.SEALEvaluatorSubInplace(e.ptr, a.ptr, b.ptr)
+	if err := e.SubInplace(a, b); err != nil {
+		return nil, err
+	}
+	return a, nil
 }
 
-func (e *Evaluator) SubPlainInplace(a *Ciphertext, b *Plaintext) {
-	C.SEALEvaluatorSubPlainInplace(e.ptr, a.ptr, b.ptr)
+func (e *Evaluator) SubInplace(a *Ciphertext, b *Ciphertext) error {
+	if err := checkOpen("Evaluator.SubInplace", e, a, b); err != nil {
+		return err
+	}
+	// The sum has the size of the larger operand.
+	defer retrack(a)
+	err := checkError("Evaluator.SubInplace", C.SEALEvaluatorSubInplace(e.ptr, a.ptr, b.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(b)
+	return err
+}
+
+func (e *Evaluator) SubPlainInplace(a *Ciphertext, b *Plaintext) error {
+	if err := checkOpen("Evaluator.SubPlainInplace", e, a, b); err != nil {
+		return err
+	}
+	err := checkError("Evaluator.SubPlainInplace", C.SEALEvaluatorSubPlainInplace(e.ptr, a.ptr, b.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(b)
+	return err
 }
 
-func (e *Evaluator) Multiply(a *Ciphertext, b *Ciphertext) *Ciphertext {
+func (e *Evaluator) Multiply(a *Ciphertext, b *Ciphertext) (*Ciphertext, error) {
 	a = a.Copy()
-	e.MultiplyInplace(a, b)
-	return a
+	if err := e.MultiplyInplace(a, b); err != nil {
+		return nil, err
+	}
+	return a, nil
 }
 
-func (e *Evaluator) MultiplyInplace(a *Ciphertext, b *Ciphertext) {
-	C.SEALEvaluatorMultiplyInplace(e.ptr, a.ptr, b.ptr)
+func (e *Evaluator) MultiplyInplace(a *Ciphertext, b *Ciphertext) error {
+	if err := checkOpen("Evaluator.MultiplyInplace", e, a, b); err != nil {
+		return err
+	}
+	defer retrack(a)
+	err := checkError("Evaluator.MultiplyInplace", C.SEALEvaluatorMultiplyInplace(e.ptr, a.ptr, b.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(b)
+	return err
 }
 
-func (e *Evaluator) MultiplyPlain(a *Ciphertext, b *Plaintext) *Ciphertext {
+func (e *Evaluator) MultiplyPlain(a *Ciphertext, b *Plaintext) (*Ciphertext, error) {
 	a = a.Copy()
-	e.MultiplyPlainInplace(a, b)
-	return a
+	if err := e.MultiplyPlainInplace(a, b); err != nil {
+		return nil, err
+	}
+	return a, nil
 }
 
-func (e *Evaluator) MultiplyPlainInplace(a *Ciphertext, b *Plaintext) {
-	C.SEALEvaluatorMultiplyPlainInplace(e.ptr, a.ptr, b.ptr)
+func (e *Evaluator) MultiplyPlainInplace(a *Ciphertext, b *Plaintext) error {
+	if err := checkOpen("Evaluator.MultiplyPlainInplace", e, a, b); err != nil {
+		return err
+	}
+	err := checkError("Evaluator.MultiplyPlainInplace", C.SEALEvaluatorMultiplyPlainInplace(e.ptr, a.ptr, b.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(b)
+	return err
 }
 
-func (e *Evaluator) RelinearizeInplace(a *Ciphertext, b *RelinKeys) {
-	C.SEALEvaluatorRelinearizeInplace(e.ptr, a.ptr, b.ptr)
+func (e *Evaluator) RelinearizeInplace(a *Ciphertext, b *RelinKeys) error {
+	if err := checkOpen("Evaluator.RelinearizeInplace", e, a, b); err != nil {
+		return err
+	}
+	defer retrack(a)
+	err := checkError("Evaluator.RelinearizeInplace", C.SEALEvaluatorRelinearizeInplace(e.ptr, a.ptr, b.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(b)
+	return err
 }
 
-func (e *Evaluator) ExponentiateInplace(a *Ciphertext, power int64, b *RelinKeys) {
-	C.SEALEvaluatorExponentiateInplace(e.ptr, a.ptr, C.uint64_t(power), b.ptr)
+func (e *Evaluator) ExponentiateInplace(a *Ciphertext, power int64, b *RelinKeys) error {
+	if err := checkOpen("Evaluator.ExponentiateInplace", e, a, b); err != nil {
+		return err
+	}
+	defer retrack(a)
+	err := checkError("Evaluator.ExponentiateInplace", C.SEALEvaluatorExponentiateInplace(e.ptr, a.ptr, C.uint64_t(power), b.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(b)
+	return err
 }
 
-func (e *Evaluator) RescaleToNextInplace(a *Ciphertext) {
-	C.SEALEvaluatorRescaleToNextInplace(e.ptr, a.ptr)
+func (e *Evaluator) RescaleToNextInplace(a *Ciphertext) error {
+	if err := checkOpen("Evaluator.RescaleToNextInplace", e, a); err != nil {
+		return err
+	}
+	defer retrack(a)
+	err := checkError("Evaluator.RescaleToNextInplace", C.SEALEvaluatorRescaleToNextInplace(e.ptr, a.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(a)
+	return err
 }
 
-func (e *Evaluator) RescaleToInplace(a *Ciphertext, p *ParmsID) {
-	for !a.ParmsID().Eq(p) {
-		e.RescaleToNextInplace(a)
+func (e *Evaluator) RescaleToInplace(a *Ciphertext, p *ParmsID) error {
+	if err := checkOpen("Evaluator.RescaleToInplace", e, a); err != nil {
+		return err
 	}
-	//C.SEALEvaluatorRescaleToInplace(e.ptr, a.ptr, p.ptr)
+	if err := checkParmsID("Evaluator.RescaleToInplace", p); err != nil {
+		return err
+	}
+	defer retrack(a)
+	err := checkError("Evaluator.RescaleToInplace", C.SEALEvaluatorRescaleToInplace(e.ptr, a.ptr, p.ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(p)
+	return err
 }
 
 type Decryptor struct {
 	ptr C.SEALDecryptor
+	native
 }
 
-func NewDecryptor(c *Context, key *SecretKey) *Decryptor {
+func NewDecryptor(c *Context, key *SecretKey) (*Decryptor, error) {
+	if err := checkOpen("NewDecryptor", c, key); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALDecryptor
+	err := checkError("NewDecryptor", C.SEALDecryptorInit(c.ptr, key.ptr, &ptr))
+	runtime.KeepAlive(c)
+	runtime.KeepAlive(key)
+	if err != nil {
+		return nil, err
+	}
 	d := &Decryptor{
-		ptr: C.SEALDecryptorInit(c.ptr, key.ptr),
+		ptr: ptr,
 	}
-	runtime.SetFinalizer(d, func(d *Decryptor) {
-		C.SEALDecryptorDelete(d.ptr)
-		d.ptr = nil
-	})
-	return d
+	runtime.SetFinalizer(d, (*Decryptor).free)
+	track(d)
+	return d, nil
 }
 
-func (d *Decryptor) Decrypt(c *Ciphertext) *Plaintext {
-	return newPlaintext(C.SEALDecryptorDecrypt(d.ptr, c.ptr))
+func (d *Decryptor) Decrypt(c *Ciphertext) (*Plaintext, error) {
+	if err := checkOpen("Decryptor.Decrypt", d, c); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALPlaintext
+	err := checkError("Decryptor.Decrypt", C.SEALDecryptorDecrypt(d.ptr, c.ptr, &ptr))
+	runtime.KeepAlive(d)
+	runtime.KeepAlive(c)
+	if err != nil {
+		return nil, err
+	}
+	return newPlaintext(ptr), nil
 }
 
 type BinaryFractionalEncoder struct {
 	ptr C.SEALBinaryFractionalEncoder
+	native
 }
 
-func NewBinaryFractionalEncoder(params *EncryptionParams) *BinaryFractionalEncoder {
+func NewBinaryFractionalEncoder(params *EncryptionParams) (*BinaryFractionalEncoder, error) {
+	if err := checkOpen("NewBinaryFractionalEncoder", params); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALBinaryFractionalEncoder
+	err := checkError("NewBinaryFractionalEncoder", C.SEALBinaryFractionalEncoderInit(params.ptr, &ptr))
+	runtime.KeepAlive(params)
+	if err != nil {
+		return nil, err
+	}
 	d := &BinaryFractionalEncoder{
-		ptr: C.SEALBinaryFractionalEncoderInit(params.ptr),
+		ptr: ptr,
 	}
-	runtime.SetFinalizer(d, func(d *BinaryFractionalEncoder) {
-		C.SEALBinaryFractionalEncoderDelete(d.ptr)
-		d.ptr = nil
-	})
-	return d
+	runtime.SetFinalizer(d, (*BinaryFractionalEncoder).free)
+	track(d)
+	return d, nil
 }
 
-func (e *BinaryFractionalEncoder) Encode(a float64) *Plaintext {
-	return newPlaintext(C.SEALBinaryFractionalEncoderEncode(e.ptr, C.double(a)))
+func (e *BinaryFractionalEncoder) Encode(a float64) (*Plaintext, error) {
+	if err := checkOpen("BinaryFractionalEncoder.Encode", e); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALPlaintext
+	err := checkError("BinaryFractionalEncoder.Encode", C.SEALBinaryFractionalEncoderEncode(e.ptr, C.double(a), &ptr))
+	runtime.KeepAlive(e)
+	if err != nil {
+		return nil, err
+	}
+	return newPlaintext(ptr), nil
 }
 
-func (e *BinaryFractionalEncoder) Decode(p *Plaintext) float64 {
-	return float64(C.SEALBinaryFractionalEncoderDecode(e.ptr, p.ptr))
+func (e *BinaryFractionalEncoder) Decode(p *Plaintext) (float64, error) {
+	if err := checkOpen("BinaryFractionalEncoder.Decode", e, p); err != nil {
+		return 0, err
+	}
+	var out C.double
+	err := checkError("BinaryFractionalEncoder.Decode", C.SEALBinaryFractionalEncoderDecode(e.ptr, p.ptr, &out))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(p)
+	if err != nil {
+		return 0, err
+	}
+	return float64(out), nil
 }
 
 type Plaintext struct {
 	ptr C.SEALPlaintext
+	native
 }
 
 func newPlaintext(ptr C.SEALPlaintext) *Plaintext {
 	obj := &Plaintext{
 		ptr: ptr,
 	}
-	runtime.SetFinalizer(obj, func(obj *Plaintext) {
-		C.SEALPlaintextDelete(obj.ptr)
-		obj.ptr = nil
-	})
+	runtime.SetFinalizer(obj, (*Plaintext).free)
+	track(obj)
 	return obj
 }
 
 type CKKSEncoder struct {
 	ptr C.SEALCKKSEncoder
+	native
 }
 
-func NewCKKSEncoder(c *Context) *CKKSEncoder {
+func NewCKKSEncoder(c *Context) (*CKKSEncoder, error) {
+	if err := checkOpen("NewCKKSEncoder", c); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALCKKSEncoder
+	err := checkError("NewCKKSEncoder", C.SEALCKKSEncoderInit(c.ptr, &ptr))
+	runtime.KeepAlive(c)
+	if err != nil {
+		return nil, err
+	}
 	obj := &CKKSEncoder{
-		ptr: C.SEALCKKSEncoderInit(c.ptr),
+		ptr: ptr,
 	}
-	runtime.SetFinalizer(obj, func(obj *CKKSEncoder) {
-		C.SEALCKKSEncoderDelete(obj.ptr)
-		obj.ptr = nil
-	})
-	return obj
+	runtime.SetFinalizer(obj, (*CKKSEncoder).free)
+	track(obj)
+	return obj, nil
 }
 
-func (e *CKKSEncoder) Encode(num float64) *Plaintext {
-	// 60 bits
-	scale := math.Pow(2.0, 60)
-	return e.EncodeScale(num, scale)
+// Encode encodes num into every slot of a plaintext at a 60 bit scale.
+func (e *CKKSEncoder) Encode(num float64) (*Plaintext, error) {
+	return e.EncodeScale(num, DefaultScale)
 }
 
-func (e *CKKSEncoder) EncodeScale(num, scale float64) *Plaintext {
+func (e *CKKSEncoder) EncodeScale(num, scale float64) (*Plaintext, error) {
 	return e.EncodeParmsIDScale(num, &ParmsID{}, scale)
 }
 
-func (e *CKKSEncoder) EncodeParmsIDScale(num float64, p *ParmsID, scale float64) *Plaintext {
-	return newPlaintext(C.SEALCKKSEncoderEncode(e.ptr, C.double(num), p.ptr, C.double(scale)))
+func (e *CKKSEncoder) EncodeParmsIDScale(num float64, p *ParmsID, scale float64) (*Plaintext, error) {
+	if err := checkOpen("CKKSEncoder.EncodeParmsIDScale", e); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALPlaintext
+	err := checkError("CKKSEncoder.EncodeParmsIDScale", C.SEALCKKSEncoderEncode(e.ptr, C.double(num), p.cptr(), C.double(scale), &ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(p)
+	if err != nil {
+		return nil, err
+	}
+	return newPlaintext(ptr), nil
 }
 
-func (e *CKKSEncoder) Decode(p *Plaintext) float64 {
-	return float64(C.SEALCKKSEncoderDecode(e.ptr, p.ptr))
+func (e *CKKSEncoder) Decode(p *Plaintext) (float64, error) {
+	if err := checkOpen("CKKSEncoder.Decode", e, p); err != nil {
+		return 0, err
+	}
+	var out C.double
+	err := checkError("CKKSEncoder.Decode", C.SEALCKKSEncoderDecode(e.ptr, p.ptr, &out))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(p)
+	if err != nil {
+		return 0, err
+	}
+	return float64(out), nil
 }
@@ -1,5 +1,6 @@
 #pragma once
 
+#include <stddef.h>
 #include <stdint.h>
 
 #ifdef __cplusplus
@@ -21,75 +22,255 @@ typedef void* SEALCKKSEncoder;
 typedef void* SEALCiphertext;
 typedef void* SEALRelinKeys;
 typedef void* SEALParmsID;
+typedef void* SEALBatchEncoder;
+typedef void* SEALGaloisKeys;
+typedef void* SEALSymmetricEncryptor;
+typedef void* SEALSeededCiphertext;
 
-SEALEncryptionParameters SEALEncryptionParametersBFV(void);
-SEALEncryptionParameters SEALEncryptionParametersCKKS(void);
+// Exception classes caught at the shim boundary.
+#define SEAL_OK 0
+#define SEAL_ERROR_INVALID_ARGUMENT 1
+#define SEAL_ERROR_OUT_OF_RANGE 2
+#define SEAL_ERROR_LOGIC 3
+#define SEAL_ERROR_RUNTIME 4
+#define SEAL_ERROR_UNKNOWN 5
+
+// SEALError reports a C++ exception thrown by SEAL. message is malloc'd and
+// owned by the caller; it is NULL when code is SEAL_OK.
+typedef struct {
+  int code;
+  char* message;
+} SEALError;
+
+// Scheme identifiers, matching seal::scheme_type.
+#define SEAL_SCHEME_BFV 1
+#define SEAL_SCHEME_CKKS 2
+
+// Security levels understood by SEALEncryptionParametersSetDefaultCoeffModulus.
+#define SEAL_SECURITY_128 128
+#define SEAL_SECURITY_192 192
+#define SEAL_SECURITY_256 256
+
+typedef struct {
+  int parameters_set;
+  int enable_fft;
+  int enable_ntt;
+  int enable_batching;
+  int enable_fast_plain_lift;
+} SEALQualifiers;
+
+SEALError SEALEncryptionParametersInit(int, SEALEncryptionParameters*);
+SEALError SEALEncryptionParametersBFV(SEALEncryptionParameters*);
+SEALError SEALEncryptionParametersCKKS(SEALEncryptionParameters*);
 void SEALEncryptionParametersDelete(SEALEncryptionParameters);
+SEALError SEALEncryptionParametersSetPolyModulusDegree(SEALEncryptionParameters,
+                                                       uint64_t);
+SEALError SEALEncryptionParametersSetCoeffModulus(SEALEncryptionParameters,
+                                                  uint64_t*, size_t);
+SEALError SEALEncryptionParametersSetDefaultCoeffModulus(
+    SEALEncryptionParameters, int);
+SEALError SEALEncryptionParametersSetPlainModulus(SEALEncryptionParameters,
+                                                  uint64_t);
+int SEALEncryptionParametersScheme(SEALEncryptionParameters);
+uint64_t SEALEncryptionParametersPolyModulusDegree(SEALEncryptionParameters);
+size_t SEALEncryptionParametersCoeffModulusCount(SEALEncryptionParameters);
+void SEALEncryptionParametersCoeffModulus(SEALEncryptionParameters, uint64_t*);
+uint64_t SEALEncryptionParametersPlainModulus(SEALEncryptionParameters);
 
-SEALContext SEALContextInit(SEALEncryptionParameters);
+SEALError SEALContextInit(SEALEncryptionParameters, SEALContext*);
 void SEALContextDelete(SEALContext);
+SEALQualifiers SEALContextQualifiers(SEALContext);
+int SEALContextScheme(SEALContext);
+uint64_t SEALContextPolyModulusDegree(SEALContext);
+int SEALContextTotalCoeffModulusBitCount(SEALContext);
 
-SEALKeyGenerator SEALKeyGeneratorInit(SEALContext);
+SEALError SEALKeyGeneratorInit(SEALContext, SEALKeyGenerator*);
 void SEALKeyGeneratorDelete(SEALKeyGenerator);
-SEALPublicKey SEALKeyGeneratorPublicKey(SEALKeyGenerator);
-SEALSecretKey SEALKeyGeneratorSecretKey(SEALKeyGenerator);
-SEALRelinKeys SEALKeyGeneratorRelinKeys(SEALKeyGenerator, int, int);
+SEALError SEALKeyGeneratorPublicKey(SEALKeyGenerator, SEALPublicKey*);
+SEALError SEALKeyGeneratorSecretKey(SEALKeyGenerator, SEALSecretKey*);
+SEALError SEALKeyGeneratorRelinKeys(SEALKeyGenerator, int, int,
+                                    SEALRelinKeys*);
+// A zero step count generates keys for every power-of-two rotation.
+SEALError SEALKeyGeneratorGaloisKeys(SEALKeyGenerator, int, int*, size_t,
+                                     SEALGaloisKeys*);
 
 void SEALPublicKeyDelete(SEALPublicKey);
 void SEALSecretKeyDelete(SEALSecretKey);
 void SEALRelinKeysDelete(SEALRelinKeys);
+void SEALGaloisKeysDelete(SEALGaloisKeys);
 
-SEALEncryptor SEALEncryptorInit(SEALContext, SEALPublicKey);
+SEALError SEALEncryptorInit(SEALContext, SEALPublicKey, SEALEncryptor*);
 void SEALEncryptorDelete(SEALEncryptor);
-SEALCiphertext SEALEncryptorEncrypt(SEALEncryptor, SEALPlaintext);
+SEALError SEALEncryptorEncrypt(SEALEncryptor, SEALPlaintext, SEALCiphertext*);
 
-SEALEvaluator SEALEvaluatorInit(SEALContext);
+SEALError SEALEvaluatorInit(SEALContext, SEALEvaluator*);
 void SEALEvaluatorDelete(SEALEvaluator);
 
-void SEALEvaluatorSquareInplace(SEALEvaluator, SEALCiphertext);
-void SEALEvaluatorNegateInplace(SEALEvaluator, SEALCiphertext);
-void SEALEvaluatorAddInplace(SEALEvaluator, SEALCiphertext, SEALCiphertext);
-void SEALEvaluatorAddPlainInplace(SEALEvaluator, SEALCiphertext, SEALPlaintext);
-void SEALEvaluatorSubInplace(SEALEvaluator, SEALCiphertext, SEALCiphertext);
-void SEALEvaluatorSubPlainInplace(SEALEvaluator, SEALCiphertext, SEALPlaintext);
-void SEALEvaluatorMultiplyInplace(SEALEvaluator, SEALCiphertext,
+SEALError SEALEvaluatorSquareInplace(SEALEvaluator, SEALCiphertext);
+SEALError SEALEvaluatorNegateInplace(SEALEvaluator, SEALCiphertext);
+SEALError SEALEvaluatorAddInplace(SEALEvaluator, SEALCiphertext,
+                                  SEALCiphertext);
+SEALError SEALEvaluatorAddPlainInplace(SEALEvaluator, SEALCiphertext,
+                                       SEALPlaintext);
+SEALError SEALEvaluatorSubInplace(SEALEvaluator, SEALCiphertext,
                                   SEALCiphertext);
-void SEALEvaluatorMultiplyPlainInplace(SEALEvaluator, SEALCiphertext,
+SEALError SEALEvaluatorSubPlainInplace(SEALEvaluator, SEALCiphertext,
                                        SEALPlaintext);
-void SEALEvaluatorRelinearizeInplace(SEALEvaluator, SEALCiphertext,
-                                     SEALRelinKeys);
-void SEALEvaluatorRescaleToNextInplace(SEALEvaluator, SEALCiphertext);
-void SEALEvaluatorRescaleToInplace(SEALEvaluator, SEALCiphertext, SEALParmsID);
-void SEALEvaluatorExponentiateInplace(SEALEvaluator, SEALCiphertext, uint64_t,
-                                     SEALRelinKeys);
-
-SEALDecryptor SEALDecryptorInit(SEALContext, SEALSecretKey);
+SEALError SEALEvaluatorMultiplyInplace(SEALEvaluator, SEALCiphertext,
+                                       SEALCiphertext);
+SEALError SEALEvaluatorMultiplyPlainInplace(SEALEvaluator, SEALCiphertext,
+                                            SEALPlaintext);
+SEALError SEALEvaluatorRelinearizeInplace(SEALEvaluator, SEALCiphertext,
+                                          SEALRelinKeys);
+SEALError SEALEvaluatorRescaleToNextInplace(SEALEvaluator, SEALCiphertext);
+SEALError SEALEvaluatorRescaleToInplace(SEALEvaluator, SEALCiphertext,
+                                        SEALParmsID);
+SEALError SEALEvaluatorModSwitchToNextInplace(SEALEvaluator, SEALCiphertext);
+SEALError SEALEvaluatorModSwitchToInplace(SEALEvaluator, SEALCiphertext,
+                                          SEALParmsID);
+SEALError SEALEvaluatorModSwitchToNextPlainInplace(SEALEvaluator,
+                                                   SEALPlaintext);
+SEALError SEALEvaluatorModSwitchToPlainInplace(SEALEvaluator, SEALPlaintext,
+                                               SEALParmsID);
+SEALError SEALEvaluatorExponentiateInplace(SEALEvaluator, SEALCiphertext,
+                                           uint64_t, SEALRelinKeys);
+SEALError SEALEvaluatorRotateVectorInplace(SEALEvaluator, SEALCiphertext, int,
+                                           SEALGaloisKeys);
+SEALError SEALEvaluatorRotateRowsInplace(SEALEvaluator, SEALCiphertext, int,
+                                         SEALGaloisKeys);
+SEALError SEALEvaluatorRotateColumnsInplace(SEALEvaluator, SEALCiphertext,
+                                            SEALGaloisKeys);
+SEALError SEALEvaluatorComplexConjugateInplace(SEALEvaluator, SEALCiphertext,
+                                               SEALGaloisKeys);
+
+SEALError SEALDecryptorInit(SEALContext, SEALSecretKey, SEALDecryptor*);
 void SEALDecryptorDelete(SEALDecryptor);
-SEALPlaintext SEALDecryptorDecrypt(SEALDecryptor, SEALCiphertext);
+SEALError SEALDecryptorDecrypt(SEALDecryptor, SEALCiphertext, SEALPlaintext*);
+SEALError SEALDecryptorInvariantNoiseBudget(SEALDecryptor, SEALCiphertext,
+                                            int*);
 
-SEALBinaryFractionalEncoder SEALBinaryFractionalEncoderInit(
-    SEALEncryptionParameters);
+SEALError SEALBinaryFractionalEncoderInit(SEALEncryptionParameters,
+                                          SEALBinaryFractionalEncoder*);
 void SEALBinaryFractionalEncoderDelete(SEALBinaryFractionalEncoder);
-SEALPlaintext SEALBinaryFractionalEncoderEncode(SEALBinaryFractionalEncoder,
-                                                double);
-double SEALBinaryFractionalEncoderDecode(SEALBinaryFractionalEncoder,
-                                         SEALPlaintext);
+SEALError SEALBinaryFractionalEncoderEncode(SEALBinaryFractionalEncoder,
+                                            double, SEALPlaintext*);
+SEALError SEALBinaryFractionalEncoderDecode(SEALBinaryFractionalEncoder,
+                                            SEALPlaintext, double*);
 
 void SEALPlaintextDelete(SEALPlaintext);
 
-SEALCKKSEncoder SEALCKKSEncoderInit(SEALContext);
-SEALPlaintext SEALCKKSEncoderEncode(SEALCKKSEncoder, double, SEALParmsID, double);
-double SEALCKKSEncoderDecode(SEALCKKSEncoder, SEALPlaintext);
+SEALError SEALCKKSEncoderInit(SEALContext, SEALCKKSEncoder*);
+SEALError SEALCKKSEncoderEncode(SEALCKKSEncoder, double, SEALParmsID, double,
+                                SEALPlaintext*);
+SEALError SEALCKKSEncoderDecode(SEALCKKSEncoder, SEALPlaintext, double*);
+size_t SEALCKKSEncoderSlotCount(SEALCKKSEncoder);
+SEALError SEALCKKSEncoderEncodeVector(SEALCKKSEncoder, double*, size_t,
+                                      SEALParmsID, double, SEALPlaintext*);
+// Complex values are passed as interleaved real and imaginary parts.
+SEALError SEALCKKSEncoderEncodeComplexVector(SEALCKKSEncoder, double*, size_t,
+                                             SEALParmsID, double,
+                                             SEALPlaintext*);
+SEALError SEALCKKSEncoderDecodeVector(SEALCKKSEncoder, SEALPlaintext, double*);
+SEALError SEALCKKSEncoderDecodeComplexVector(SEALCKKSEncoder, SEALPlaintext,
+                                             double*);
 void SEALCKKSEncoderDelete(SEALCKKSEncoder);
 
+SEALError SEALBatchEncoderInit(SEALContext, SEALBatchEncoder*);
+void SEALBatchEncoderDelete(SEALBatchEncoder);
+size_t SEALBatchEncoderSlotCount(SEALBatchEncoder);
+SEALError SEALBatchEncoderEncodeUint64(SEALBatchEncoder, uint64_t*, size_t,
+                                       SEALPlaintext*);
+SEALError SEALBatchEncoderEncodeInt64(SEALBatchEncoder, int64_t*, size_t,
+                                      SEALPlaintext*);
+SEALError SEALBatchEncoderDecodeUint64(SEALBatchEncoder, SEALPlaintext,
+                                       uint64_t*);
+SEALError SEALBatchEncoderDecodeInt64(SEALBatchEncoder, SEALPlaintext,
+                                      int64_t*);
+
 void SEALCiphertextDelete(SEALCiphertext);
 SEALCiphertext SEALCiphertextCopy(SEALCiphertext);
 double SEALCiphertextScale(SEALCiphertext);
+void SEALCiphertextSetScale(SEALCiphertext, double);
+size_t SEALCiphertextSize(SEALCiphertext);
+size_t SEALCiphertextPolyModulusDegree(SEALCiphertext);
+size_t SEALCiphertextCoeffModCount(SEALCiphertext);
+int SEALCiphertextIsTransparent(SEALCiphertext);
+int SEALCiphertextIsNTTForm(SEALCiphertext);
 SEALParmsID SEALCiphertextParmsID(SEALCiphertext);
 
+SEALParmsID SEALPlaintextParmsID(SEALPlaintext);
+
+// The chain index counts the rescales or modulus switches left before the
+// last parameters, which have index 0. It is -1 for an unknown parms_id.
+SEALParmsID SEALContextFirstParmsID(SEALContext);
+SEALParmsID SEALContextLastParmsID(SEALContext);
+int SEALContextChainIndex(SEALContext, SEALParmsID);
+// The primes of the coefficient modulus at a parms_id; the count is 0 for an
+// unknown parms_id.
+size_t SEALContextCoeffModulusCount(SEALContext, SEALParmsID);
+void SEALContextCoeffModulus(SEALContext, SEALParmsID, uint64_t*);
+
 void SEALParmsIDDelete(SEALParmsID);
 int SEALParmsIDEq(SEALParmsID, SEALParmsID);
 
+// Serialization. Save functions return a malloc'd buffer owned by the caller.
+// Load functions check the object against the context unless it is NULL.
+SEALError SEALEncryptionParametersSave(SEALEncryptionParameters, char**,
+                                       size_t*);
+SEALError SEALEncryptionParametersLoad(char*, size_t,
+                                       SEALEncryptionParameters*);
+SEALError SEALPublicKeySave(SEALPublicKey, char**, size_t*);
+SEALError SEALPublicKeyLoad(SEALContext, char*, size_t, SEALPublicKey*);
+SEALError SEALSecretKeySave(SEALSecretKey, char**, size_t*);
+SEALError SEALSecretKeyLoad(SEALContext, char*, size_t, SEALSecretKey*);
+SEALError SEALRelinKeysSave(SEALRelinKeys, char**, size_t*);
+SEALError SEALRelinKeysLoad(SEALContext, char*, size_t, SEALRelinKeys*);
+SEALError SEALGaloisKeysSave(SEALGaloisKeys, char**, size_t*);
+SEALError SEALGaloisKeysLoad(SEALContext, char*, size_t, SEALGaloisKeys*);
+SEALError SEALCiphertextSave(SEALCiphertext, char**, size_t*);
+SEALError SEALCiphertextLoad(SEALContext, char*, size_t, SEALCiphertext*);
+SEALError SEALPlaintextSave(SEALPlaintext, char**, size_t*);
+SEALError SEALPlaintextLoad(SEALContext, char*, size_t, SEALPlaintext*);
+
+// Approximate size of the coefficient data held by an object, in bytes.
+size_t SEALCiphertextByteCount(SEALCiphertext);
+size_t SEALPlaintextByteCount(SEALPlaintext);
+size_t SEALPublicKeyByteCount(SEALPublicKey);
+size_t SEALSecretKeyByteCount(SEALSecretKey);
+size_t SEALRelinKeysByteCount(SEALRelinKeys);
+size_t SEALGaloisKeysByteCount(SEALGaloisKeys);
+
+// Secret-key encryption. SEAL 3.0 has no symmetric encryptor, so the shim
+// builds encryptions of zero the way SEAL builds public keys. Seeded
+// ciphertexts store a seed in place of their uniformly random polynomial.
+SEALError SEALSymmetricEncryptorInit(SEALContext, SEALSecretKey,
+                                     SEALSymmetricEncryptor*);
+void SEALSymmetricEncryptorDelete(SEALSymmetricEncryptor);
+SEALError SEALSymmetricEncryptorEncrypt(SEALSymmetricEncryptor, SEALPlaintext,
+                                        SEALCiphertext*);
+// A NULL parms_id encrypts at the first parameters.
+SEALError SEALSymmetricEncryptorEncryptZero(SEALSymmetricEncryptor,
+                                            SEALParmsID, SEALCiphertext*);
+SEALError SEALSymmetricEncryptorEncryptSeeded(SEALSymmetricEncryptor,
+                                              SEALPlaintext,
+                                              SEALSeededCiphertext*);
+void SEALSeededCiphertextDelete(SEALSeededCiphertext);
+SEALError SEALSeededCiphertextExpand(SEALContext, SEALSeededCiphertext,
+                                     SEALCiphertext*);
+SEALError SEALSeededCiphertextSave(SEALSeededCiphertext, char**, size_t*);
+SEALError SEALSeededCiphertextLoad(SEALContext, char*, size_t,
+                                   SEALSeededCiphertext*);
+size_t SEALSeededCiphertextByteCount(SEALSeededCiphertext);
+
+// Exposed for known-answer tests. SEALChaCha20Block writes the 64-byte
+// block for a 32-byte key, block counter and 12-byte nonce.
+// SEALSymmetricExpandSeed writes the NTT-form polynomial a expanded from a
+// 32-byte seed at parms_id, one row of poly_modulus_degree values per prime.
+void SEALChaCha20Block(const uint8_t* key, uint32_t counter,
+                       const uint8_t* nonce, uint8_t* out);
+SEALError SEALSymmetricExpandSeed(SEALContext, SEALParmsID,
+                                  const uint8_t* seed, uint64_t* out);
+
 #ifdef __cplusplus
 } /* end extern "C" */
 #endif
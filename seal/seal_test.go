@@ -1,156 +1,408 @@
 package seal
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"testing"
 )
 
-func TestEncryptionOps(t *testing.T) {
-	params := NewEncryptionParamsCKKS()
-	c := NewContext(params)
-	g := NewKeyGenerator(c)
-	pub := g.PublicKey()
-	sec := g.SecretKey()
+// ckksKit bundles the objects most tests need for a CKKS context.
+type ckksKit struct {
+	ctx       *Context
+	keygen    *KeyGenerator
+	encryptor *Encryptor
+	decryptor *Decryptor
+	eval      *Evaluator
+	enc       *CKKSEncoder
+}
+
+func newCKKSKit(t *testing.T) *ckksKit {
+	t.Helper()
+	params, err := NewEncryptionParamsCKKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewContext(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewKeyGenerator(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := g.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec, err := g.SecretKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptor, err := NewEncryptor(c, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decryptor, err := NewDecryptor(c, sec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eval, err := NewEvaluator(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := NewCKKSEncoder(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &ckksKit{
+		ctx:       c,
+		keygen:    g,
+		encryptor: encryptor,
+		decryptor: decryptor,
+		eval:      eval,
+		enc:       enc,
+	}
+}
+
+// bfvKit bundles the objects most tests need for a batching BFV context.
+type bfvKit struct {
+	ctx       *Context
+	keygen    *KeyGenerator
+	encryptor *Encryptor
+	decryptor *Decryptor
+	eval      *Evaluator
+	batch     *BatchEncoder
+}
+
+func newBFVKit(t *testing.T) *bfvKit {
+	t.Helper()
+	params, err := NewEncryptionParamsBFV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewContext(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewKeyGenerator(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := g.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec, err := g.SecretKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptor, err := NewEncryptor(c, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decryptor, err := NewDecryptor(c, sec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eval, err := NewEvaluator(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch, err := NewBatchEncoder(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &bfvKit{
+		ctx:       c,
+		keygen:    g,
+		encryptor: encryptor,
+		decryptor: decryptor,
+		eval:      eval,
+		batch:     batch,
+	}
+}
 
-	encryptor := NewEncryptor(c, pub)
-	eval := NewEvaluator(c)
-	decryptor := NewDecryptor(c, sec)
+func TestEncryptionOps(t *testing.T) {
+	k := newCKKSKit(t)
+	g, encryptor, decryptor, eval, enc := k.keygen, k.encryptor, k.decryptor, k.eval, k.enc
 
-	enc := NewCKKSEncoder(c)
+	encode := func(v float64) *Plaintext {
+		p, err := enc.Encode(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
 
 	encrypt := func(v float64) *Ciphertext {
-		a := enc.Encode(v)
-		return encryptor.Encrypt(a)
+		a, err := encryptor.Encrypt(encode(v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a
 	}
 
 	cases := []struct {
 		want float64
-		f    func() *Ciphertext
+		f    func() (*Ciphertext, error)
 	}{
 		{
 			want: 10.0,
-			f: func() *Ciphertext {
-				return encrypt(10.0)
+			f: func() (*Ciphertext, error) {
+				return encrypt(10.0), nil
 			},
 		},
 		{
 			want: 25.0,
-			f: func() *Ciphertext {
+			f: func() (*Ciphertext, error) {
 				c := encrypt(5.0)
-				eval.SquareInplace(c)
-				return c
+				return c, eval.SquareInplace(c)
 			},
 		},
 		{
 			want: -5.0,
-			f: func() *Ciphertext {
+			f: func() (*Ciphertext, error) {
 				c := encrypt(5.0)
-				eval.NegateInplace(c)
-				return c
+				return c, eval.NegateInplace(c)
 			},
 		},
 		{
 			want: 7.0,
-			f: func() *Ciphertext {
+			f: func() (*Ciphertext, error) {
 				a := encrypt(3.0)
 				b := encrypt(4.0)
-				eval.AddInplace(a, b)
-				return a
+				return a, eval.AddInplace(a, b)
 			},
 		},
 		{
 			want: 7.0,
-			f: func() *Ciphertext {
+			f: func() (*Ciphertext, error) {
 				a := encrypt(3.0)
-				b := enc.Encode(4.0)
-				eval.AddPlainInplace(a, b)
-				return a
+				b := encode(4.0)
+				return a, eval.AddPlainInplace(a, b)
 			},
 		},
 		{
 			want: -1.0,
-			f: func() *Ciphertext {
+			f: func() (*Ciphertext, error) {
 				a := encrypt(3.0)
 				b := encrypt(4.0)
-				eval.SubInplace(a, b)
-				return a
+				return a, eval.SubInplace(a, b)
 			},
 		},
 		{
 			want: -1.0,
-			f: func() *Ciphertext {
+			f: func() (*Ciphertext, error) {
 				a := encrypt(3.0)
-				b := enc.Encode(4.0)
-				eval.SubPlainInplace(a, b)
-				return a
+				b := encode(4.0)
+				return a, eval.SubPlainInplace(a, b)
 			},
 		},
 		{
 			want: 12.0,
-			f: func() *Ciphertext {
+			f: func() (*Ciphertext, error) {
 				a := encrypt(3.0)
 				b := encrypt(4.0)
-				eval.MultiplyInplace(a, b)
-				return a
+				return a, eval.MultiplyInplace(a, b)
 			},
 		},
 		{
 			want: 12.0,
-			f: func() *Ciphertext {
+			f: func() (*Ciphertext, error) {
 				a := encrypt(3.0)
-				b := enc.Encode(4.0)
-				eval.MultiplyPlainInplace(a, b)
-				return a
+				b := encode(4.0)
+				return a, eval.MultiplyPlainInplace(a, b)
 			},
 		},
 		{
 			want: 3.0,
-			f: func() *Ciphertext {
+			f: func() (*Ciphertext, error) {
 				a := encrypt(3.0)
-				relinKeys := g.RelinKeys(60, 1)
-				eval.RelinearizeInplace(a, relinKeys)
-				return a
+				relinKeys, err := g.RelinKeys(60, 1)
+				if err != nil {
+					return nil, err
+				}
+				return a, eval.RelinearizeInplace(a, relinKeys)
 			},
 		},
 		{
 			want: 3.0,
-			f: func() *Ciphertext {
+			f: func() (*Ciphertext, error) {
 				a := encrypt(3.0)
 				if !a.ParmsID().Eq(a.ParmsID()) {
 					t.Fatalf("paramsID not eq")
 				}
-				return a
+				return a, nil
 			},
 		},
 	}
 
 	for i, c := range cases {
-		ciphertext := c.f()
-		plain := decryptor.Decrypt(ciphertext)
-		out := enc.Decode(plain)
+		ciphertext, err := c.f()
+		if err != nil {
+			t.Fatal(i, err)
+		}
+		plain, err := decryptor.Decrypt(ciphertext)
+		if err != nil {
+			t.Fatal(i, err)
+		}
+		out, err := enc.Decode(plain)
+		if err != nil {
+			t.Fatal(i, err)
+		}
 		if math.Abs(c.want-out) > 0.00001 {
 			t.Fatal(i, "want != out", c.want, out)
 		}
 	}
 }
 
+func TestEvaluatorErrors(t *testing.T) {
+	k := newCKKSKit(t)
+	encryptor, eval, enc := k.encryptor, k.eval, k.enc
+
+	encrypt := func(v, scale float64) *Ciphertext {
+		p, err := enc.EncodeScale(v, scale)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, err := encryptor.Encrypt(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+
+	a := encrypt(3.0, math.Pow(2, 40))
+	b := encrypt(4.0, math.Pow(2, 50))
+	if err := eval.AddInplace(a, b); !errors.Is(err, ErrScaleMismatch) {
+		t.Fatalf("AddInplace with mismatched scales = %v; want ErrScaleMismatch", err)
+	}
+
+	a = encrypt(3.0, math.Pow(2, 40))
+	var err error
+	for {
+		err = eval.RescaleToNextInplace(a)
+		if err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, ErrEndOfModulusChain) {
+		t.Fatalf("RescaleToNextInplace past the last level = %v; want ErrEndOfModulusChain", err)
+	}
+	var serr *Error
+	if !errors.As(err, &serr) || serr.Op != "Evaluator.RescaleToNextInplace" {
+		t.Fatalf("error %v does not name the failing operation", err)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		msg  string
+		want error
+	}{
+		{"scale mismatch", ErrScaleMismatch},
+		{"encrypted1 and encrypted2 parameter mismatch", ErrParmsIDMismatch},
+		{"end of modulus switching chain reached", ErrEndOfModulusChain},
+		{"result ciphertext is transparent", ErrTransparentCiphertext},
+		{"encryption parameters are not set correctly", ErrInvalidParameters},
+		{"something unexpected", ErrNative},
+	}
+	for _, c := range cases {
+		if got := classify(-1, c.msg); got != c.want {
+			t.Errorf("classify(%q) = %v; want %v", c.msg, got, c.want)
+		}
+	}
+}
+
 func TestCKKSEncoder(t *testing.T) {
-	params := NewEncryptionParamsCKKS()
-	c := NewContext(params)
-	enc := NewCKKSEncoder(c)
+	params, err := NewEncryptionParamsCKKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewContext(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := NewCKKSEncoder(c)
+	if err != nil {
+		t.Fatal(err)
+	}
 	in := 10.0
-	out := enc.Decode(enc.Encode(in))
+	p, err := enc.Encode(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := enc.Decode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if math.Abs(in-out) > 0.00001 {
 		t.Fatal("in != out", in, out)
 	}
 }
 
 func TestBinaryFractionalEncoder(t *testing.T) {
-	params := NewEncryptionParamsBFV()
-	enc := NewBinaryFractionalEncoder(params)
+	params, err := NewEncryptionParamsBFV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := NewBinaryFractionalEncoder(params)
+	if err != nil {
+		t.Fatal(err)
+	}
 	in := 10.0
-	out := enc.Decode(enc.Encode(in))
+	p, err := enc.Encode(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := enc.Decode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if in != out {
 		t.Fatal("in != out")
 	}
 }
+
+func TestCiphertextMetadata(t *testing.T) {
+	k := newCKKSKit(t)
+	p, err := k.enc.Encode(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := a.Size(); got != 2 {
+		t.Errorf("Size() = %d; want 2", got)
+	}
+	if got, want := a.PolyModulusDegree(), k.ctx.PolyModulusDegree(); got != want {
+		t.Errorf("PolyModulusDegree() = %d; want %d", got, want)
+	}
+	level, err := k.eval.Level(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := a.Level(); got != level || a.CoeffModulusCount() != level+1 {
+		t.Errorf("Level() = %d, CoeffModulusCount() = %d; want %d, %d", got, a.CoeffModulusCount(), level, level+1)
+	}
+	if !a.IsNTTForm() || a.IsTransparent() {
+		t.Errorf("IsNTTForm() = %v, IsTransparent() = %v; want true, false", a.IsNTTForm(), a.IsTransparent())
+	}
+	if got, want := a.String(), fmt.Sprintf("Ciphertext(size 2, level %d, scale 2^60.00)", level); got != want {
+		t.Errorf("String() = %q; want %q", got, want)
+	}
+
+	if err := k.eval.SquareInplace(a); err != nil {
+		t.Fatal(err)
+	}
+	if got := a.Size(); got != 3 {
+		t.Errorf("Size() after squaring = %d; want 3", got)
+	}
+	a.Close()
+	if got := a.String(); got != "Ciphertext(closed)" {
+		t.Errorf("String() after Close = %q", got)
+	}
+}
@@ -0,0 +1,576 @@
+package seal
+
+// #include <stdlib.h>
+// #include "seal.h"
+import "C"
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"unsafe"
+)
+
+// maxFrameSize bounds the length prefix read by Load. It is the largest
+// object MarshalBinary can return; the buffer for a frame grows only as its
+// data arrives, so a corrupt prefix cannot trigger a large allocation.
+const maxFrameSize = math.MaxInt32
+
+type saveFunc func(**C.char, *C.size_t) C.SEALError
+
+type loadFunc func(*C.char, C.size_t) C.SEALError
+
+func marshal(op string, save saveFunc) ([]byte, error) {
+	var data *C.char
+	var size C.size_t
+	if err := checkError(op, save(&data, &size)); err != nil {
+		return nil, err
+	}
+	defer C.free(unsafe.Pointer(data))
+	if size > maxFrameSize {
+		return nil, &Error{
+			Op:      op,
+			Message: fmt.Sprintf("serialized object of %d bytes exceeds limit", size),
+			Err:     ErrOutOfRange,
+		}
+	}
+	return C.GoBytes(unsafe.Pointer(data), C.int(size)), nil
+}
+
+func unmarshal(op string, data []byte, load loadFunc) error {
+	if len(data) == 0 {
+		return &Error{Op: op, Message: "serialized data is empty", Err: ErrCorruptData}
+	}
+	return checkError(op, load((*C.char)(unsafe.Pointer(&data[0])), C.size_t(len(data))))
+}
+
+func writeFrame(w io.Writer, data []byte) error {
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(data)))
+	if _, err := w.Write(size[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+func readFrame(op string, r io.Reader) ([]byte, error) {
+	var size [8]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.LittleEndian.Uint64(size[:])
+	if n > maxFrameSize {
+		return nil, &Error{
+			Op:      op,
+			Message: fmt.Sprintf("serialized object of %d bytes exceeds limit", n),
+			Err:     ErrCorruptData,
+		}
+	}
+	data, err := io.ReadAll(io.LimitReader(r, int64(n)))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(data)) < n {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return data, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p *EncryptionParams) MarshalBinary() ([]byte, error) {
+	if err := checkOpen("EncryptionParams.MarshalBinary", p); err != nil {
+		return nil, err
+	}
+	data, err := marshal("EncryptionParams.MarshalBinary", func(data **C.char, size *C.size_t) C.SEALError {
+		return C.SEALEncryptionParametersSave(p.ptr, data, size)
+	})
+	runtime.KeepAlive(p)
+	return data, err
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. The security level
+// is not part of SEAL's format and is left unchanged.
+func (p *EncryptionParams) UnmarshalBinary(data []byte) error {
+	var ptr C.SEALEncryptionParameters
+	err := unmarshal("EncryptionParams.UnmarshalBinary", data, func(data *C.char, size C.size_t) C.SEALError {
+		return C.SEALEncryptionParametersLoad(data, size, &ptr)
+	})
+	if err != nil {
+		return err
+	}
+	if p.ptr == nil {
+		p.security = Security128
+		runtime.SetFinalizer(p, (*EncryptionParams).free)
+	} else {
+		p.free()
+	}
+	p.ptr = ptr
+	track(p)
+	return nil
+}
+
+// Save writes p as a little-endian uint64 length followed by the SEAL
+// serialization returned by MarshalBinary, so several objects can share one
+// stream. Every Save in this package uses the same framing; Load reads it
+// back and, for objects other than parameters, checks the object against a
+// Context, which UnmarshalBinary skips.
+func (p *EncryptionParams) Save(w io.Writer) error {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return writeFrame(w, data)
+}
+
+// Load replaces p with parameters written by Save.
+func (p *EncryptionParams) Load(r io.Reader) error {
+	data, err := readFrame("EncryptionParams.Load", r)
+	if err != nil {
+		return err
+	}
+	return p.UnmarshalBinary(data)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (k *PublicKey) MarshalBinary() ([]byte, error) {
+	if err := checkOpen("PublicKey.MarshalBinary", k); err != nil {
+		return nil, err
+	}
+	data, err := marshal("PublicKey.MarshalBinary", func(data **C.char, size *C.size_t) C.SEALError {
+		return C.SEALPublicKeySave(k.ptr, data, size)
+	})
+	runtime.KeepAlive(k)
+	return data, err
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler without checking the
+// data against a Context; prefer Load for untrusted input.
+func (k *PublicKey) UnmarshalBinary(data []byte) error {
+	return k.load("PublicKey.UnmarshalBinary", nil, data)
+}
+
+// Save writes k as one length-prefixed frame for Load.
+func (k *PublicKey) Save(w io.Writer) error {
+	data, err := k.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return writeFrame(w, data)
+}
+
+// Load replaces k with an object written by Save, returning ErrParmsIDMismatch
+// if it was made under parameters other than ctx's.
+func (k *PublicKey) Load(ctx *Context, r io.Reader) error {
+	data, err := readFrame("PublicKey.Load", r)
+	if err != nil {
+		return err
+	}
+	return k.load("PublicKey.Load", ctx, data)
+}
+
+func (k *PublicKey) load(op string, ctx *Context, data []byte) error {
+	var cctx C.SEALContext
+	if ctx != nil {
+		if err := checkOpen(op, ctx); err != nil {
+			return err
+		}
+		cctx = ctx.ptr
+	}
+	var ptr C.SEALPublicKey
+	err := unmarshal(op, data, func(data *C.char, size C.size_t) C.SEALError {
+		return C.SEALPublicKeyLoad(cctx, data, size, &ptr)
+	})
+	runtime.KeepAlive(ctx)
+	if err != nil {
+		return err
+	}
+	if k.ptr == nil {
+		runtime.SetFinalizer(k, (*PublicKey).free)
+	} else {
+		k.free()
+	}
+	k.ptr = ptr
+	track(k)
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (k *SecretKey) MarshalBinary() ([]byte, error) {
+	if err := checkOpen("SecretKey.MarshalBinary", k); err != nil {
+		return nil, err
+	}
+	data, err := marshal("SecretKey.MarshalBinary", func(data **C.char, size *C.size_t) C.SEALError {
+		return C.SEALSecretKeySave(k.ptr, data, size)
+	})
+	runtime.KeepAlive(k)
+	return data, err
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler without checking the
+// data against a Context; prefer Load for untrusted input.
+func (k *SecretKey) UnmarshalBinary(data []byte) error {
+	return k.load("SecretKey.UnmarshalBinary", nil, data)
+}
+
+// Save writes k as one length-prefixed frame for Load.
+func (k *SecretKey) Save(w io.Writer) error {
+	data, err := k.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return writeFrame(w, data)
+}
+
+// Load replaces k with an object written by Save, returning ErrParmsIDMismatch
+// if it was made under parameters other than ctx's.
+func (k *SecretKey) Load(ctx *Context, r io.Reader) error {
+	data, err := readFrame("SecretKey.Load", r)
+	if err != nil {
+		return err
+	}
+	return k.load("SecretKey.Load", ctx, data)
+}
+
+func (k *SecretKey) load(op string, ctx *Context, data []byte) error {
+	var cctx C.SEALContext
+	if ctx != nil {
+		if err := checkOpen(op, ctx); err != nil {
+			return err
+		}
+		cctx = ctx.ptr
+	}
+	var ptr C.SEALSecretKey
+	err := unmarshal(op, data, func(data *C.char, size C.size_t) C.SEALError {
+		return C.SEALSecretKeyLoad(cctx, data, size, &ptr)
+	})
+	runtime.KeepAlive(ctx)
+	if err != nil {
+		return err
+	}
+	if k.ptr == nil {
+		runtime.SetFinalizer(k, (*SecretKey).free)
+	} else {
+		k.free()
+	}
+	k.ptr = ptr
+	track(k)
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (k *RelinKeys) MarshalBinary() ([]byte, error) {
+	if err := checkOpen("RelinKeys.MarshalBinary", k); err != nil {
+		return nil, err
+	}
+	data, err := marshal("RelinKeys.MarshalBinary", func(data **C.char, size *C.size_t) C.SEALError {
+		return C.SEALRelinKeysSave(k.ptr, data, size)
+	})
+	runtime.KeepAlive(k)
+	return data, err
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler without checking the
+// data against a Context; prefer Load for untrusted input.
+func (k *RelinKeys) UnmarshalBinary(data []byte) error {
+	return k.load("RelinKeys.UnmarshalBinary", nil, data)
+}
+
+// Save writes k as one length-prefixed frame for Load.
+func (k *RelinKeys) Save(w io.Writer) error {
+	data, err := k.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return writeFrame(w, data)
+}
+
+// Load replaces k with an object written by Save, returning ErrParmsIDMismatch
+// if it was made under parameters other than ctx's.
+func (k *RelinKeys) Load(ctx *Context, r io.Reader) error {
+	data, err := readFrame("RelinKeys.Load", r)
+	if err != nil {
+		return err
+	}
+	return k.load("RelinKeys.Load", ctx, data)
+}
+
+func (k *RelinKeys) load(op string, ctx *Context, data []byte) error {
+	var cctx C.SEALContext
+	if ctx != nil {
+		if err := checkOpen(op, ctx); err != nil {
+			return err
+		}
+		cctx = ctx.ptr
+	}
+	var ptr C.SEALRelinKeys
+	err := unmarshal(op, data, func(data *C.char, size C.size_t) C.SEALError {
+		return C.SEALRelinKeysLoad(cctx, data, size, &ptr)
+	})
+	runtime.KeepAlive(ctx)
+	if err != nil {
+		return err
+	}
+	if k.ptr == nil {
+		runtime.SetFinalizer(k, (*RelinKeys).free)
+	} else {
+		k.free()
+	}
+	k.ptr = ptr
+	track(k)
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (k *GaloisKeys) MarshalBinary() ([]byte, error) {
+	if err := checkOpen("GaloisKeys.MarshalBinary", k); err != nil {
+		return nil, err
+	}
+	data, err := marshal("GaloisKeys.MarshalBinary", func(data **C.char, size *C.size_t) C.SEALError {
+		return C.SEALGaloisKeysSave(k.ptr, data, size)
+	})
+	runtime.KeepAlive(k)
+	return data, err
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler without checking the
+// data against a Context; prefer Load for untrusted input.
+func (k *GaloisKeys) UnmarshalBinary(data []byte) error {
+	return k.load("GaloisKeys.UnmarshalBinary", nil, data)
+}
+
+// Save writes k as one length-prefixed frame for Load.
+func (k *GaloisKeys) Save(w io.Writer) error {
+	data, err := k.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return writeFrame(w, data)
+}
+
+// Load replaces k with an object written by Save, returning ErrParmsIDMismatch
+// if it was made under parameters other than ctx's.
+func (k *GaloisKeys) Load(ctx *Context, r io.Reader) error {
+	data, err := readFrame("GaloisKeys.Load", r)
+	if err != nil {
+		return err
+	}
+	return k.load("GaloisKeys.Load", ctx, data)
+}
+
+func (k *GaloisKeys) load(op string, ctx *Context, data []byte) error {
+	var cctx C.SEALContext
+	if ctx != nil {
+		if err := checkOpen(op, ctx); err != nil {
+			return err
+		}
+		cctx = ctx.ptr
+	}
+	var ptr C.SEALGaloisKeys
+	err := unmarshal(op, data, func(data *C.char, size C.size_t) C.SEALError {
+		return C.SEALGaloisKeysLoad(cctx, data, size, &ptr)
+	})
+	runtime.KeepAlive(ctx)
+	if err != nil {
+		return err
+	}
+	if k.ptr == nil {
+		runtime.SetFinalizer(k, (*GaloisKeys).free)
+	} else {
+		k.free()
+	}
+	k.ptr = ptr
+	track(k)
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (c *Ciphertext) MarshalBinary() ([]byte, error) {
+	if err := checkOpen("Ciphertext.MarshalBinary", c); err != nil {
+		return nil, err
+	}
+	data, err := marshal("Ciphertext.MarshalBinary", func(data **C.char, size *C.size_t) C.SEALError {
+		return C.SEALCiphertextSave(c.ptr, data, size)
+	})
+	runtime.KeepAlive(c)
+	return data, err
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler without checking the
+// data against a Context; prefer Load for untrusted input.
+func (c *Ciphertext) UnmarshalBinary(data []byte) error {
+	return c.load("Ciphertext.UnmarshalBinary", nil, data)
+}
+
+// Save writes c as one length-prefixed frame for Load.
+func (c *Ciphertext) Save(w io.Writer) error {
+	data, err := c.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return writeFrame(w, data)
+}
+
+// Load replaces c with an object written by Save, returning ErrParmsIDMismatch
+// if it was made under parameters other than ctx's.
+func (c *Ciphertext) Load(ctx *Context, r io.Reader) error {
+	data, err := readFrame("Ciphertext.Load", r)
+	if err != nil {
+		return err
+	}
+	return c.load("Ciphertext.Load", ctx, data)
+}
+
+func (c *Ciphertext) load(op string, ctx *Context, data []byte) error {
+	var cctx C.SEALContext
+	if ctx != nil {
+		if err := checkOpen(op, ctx); err != nil {
+			return err
+		}
+		cctx = ctx.ptr
+	}
+	var ptr C.SEALCiphertext
+	err := unmarshal(op, data, func(data *C.char, size C.size_t) C.SEALError {
+		return C.SEALCiphertextLoad(cctx, data, size, &ptr)
+	})
+	runtime.KeepAlive(ctx)
+	if err != nil {
+		return err
+	}
+	if c.ptr == nil {
+		runtime.SetFinalizer(c, (*Ciphertext).free)
+	} else {
+		c.free()
+	}
+	c.ptr = ptr
+	track(c)
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p *Plaintext) MarshalBinary() ([]byte, error) {
+	if err := checkOpen("Plaintext.MarshalBinary", p); err != nil {
+		return nil, err
+	}
+	data, err := marshal("Plaintext.MarshalBinary", func(data **C.char, size *C.size_t) C.SEALError {
+		return C.SEALPlaintextSave(p.ptr, data, size)
+	})
+	runtime.KeepAlive(p)
+	return data, err
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler without checking the
+// data against a Context; prefer Load for untrusted input.
+func (p *Plaintext) UnmarshalBinary(data []byte) error {
+	return p.load("Plaintext.UnmarshalBinary", nil, data)
+}
+
+// Save writes p as one length-prefixed frame for Load.
+func (p *Plaintext) Save(w io.Writer) error {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return writeFrame(w, data)
+}
+
+// Load replaces p with an object written by Save, returning ErrParmsIDMismatch
+// if it was made under parameters other than ctx's.
+func (p *Plaintext) Load(ctx *Context, r io.Reader) error {
+	data, err := readFrame("Plaintext.Load", r)
+	if err != nil {
+		return err
+	}
+	return p.load("Plaintext.Load", ctx, data)
+}
+
+func (p *Plaintext) load(op string, ctx *Context, data []byte) error {
+	var cctx C.SEALContext
+	if ctx != nil {
+		if err := checkOpen(op, ctx); err != nil {
+			return err
+		}
+		cctx = ctx.ptr
+	}
+	var ptr C.SEALPlaintext
+	err := unmarshal(op, data, func(data *C.char, size C.size_t) C.SEALError {
+		return C.SEALPlaintextLoad(cctx, data, size, &ptr)
+	})
+	runtime.KeepAlive(ctx)
+	if err != nil {
+		return err
+	}
+	if p.ptr == nil {
+		runtime.SetFinalizer(p, (*Plaintext).free)
+	} else {
+		p.free()
+	}
+	p.ptr = ptr
+	track(p)
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (s *SeededCiphertext) MarshalBinary() ([]byte, error) {
+	if err := checkOpen("SeededCiphertext.MarshalBinary", s); err != nil {
+		return nil, err
+	}
+	data, err := marshal("SeededCiphertext.MarshalBinary", func(data **C.char, size *C.size_t) C.SEALError {
+		return C.SEALSeededCiphertextSave(s.ptr, data, size)
+	})
+	runtime.KeepAlive(s)
+	return data, err
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler without checking the
+// data against a Context; prefer Load for untrusted input.
+func (s *SeededCiphertext) UnmarshalBinary(data []byte) error {
+	return s.load("SeededCiphertext.UnmarshalBinary", nil, data)
+}
+
+// Save writes s as one length-prefixed frame for Load.
+func (s *SeededCiphertext) Save(w io.Writer) error {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return writeFrame(w, data)
+}
+
+// Load replaces s with an object written by Save, returning ErrParmsIDMismatch
+// if it was made under parameters other than ctx's.
+func (s *SeededCiphertext) Load(ctx *Context, r io.Reader) error {
+	data, err := readFrame("SeededCiphertext.Load", r)
+	if err != nil {
+		return err
+	}
+	return s.load("SeededCiphertext.Load", ctx, data)
+}
+
+func (s *SeededCiphertext) load(op string, ctx *Context, data []byte) error {
+	var cctx C.SEALContext
+	if ctx != nil {
+		if err := checkOpen(op, ctx); err != nil {
+			return err
+		}
+		cctx = ctx.ptr
+	}
+	var ptr C.SEALSeededCiphertext
+	err := unmarshal(op, data, func(data *C.char, size C.size_t) C.SEALError {
+		return C.SEALSeededCiphertextLoad(cctx, data, size, &ptr)
+	})
+	runtime.KeepAlive(ctx)
+	if err != nil {
+		return err
+	}
+	if s.ptr == nil {
+		runtime.SetFinalizer(s, (*SeededCiphertext).free)
+	} else {
+		s.free()
+	}
+	s.ptr = ptr
+	track(s)
+	return nil
+}
@@ -0,0 +1,155 @@
+package seal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestCiphertextSaveLoad(t *testing.T) {
+	k := newCKKSKit(t)
+	in := []float64{1.25, -3, 7.5}
+	p, err := k.enc.EncodeVector(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := a.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		var b Ciphertext
+		if err := b.Load(k.ctx, &buf); err != nil {
+			t.Fatal(err)
+		}
+		out := decryptVector(t, k, &b)
+		for j, want := range in {
+			if math.Abs(out[j]-want) > 1e-4 {
+				t.Fatalf("slot %d = %f; want %f", j, out[j], want)
+			}
+		}
+	}
+	var b Ciphertext
+	if err := b.Load(k.ctx, &buf); err != io.EOF {
+		t.Fatalf("Load() past the end = %v; want io.EOF", err)
+	}
+}
+
+func TestLoadRejectsOtherParameters(t *testing.T) {
+	k := newCKKSKit(t)
+	p, err := k.enc.Encode(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	relin, err := k.keygen.RelinKeys(60, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := newBFVKit(t)
+	var buf bytes.Buffer
+	if err := a.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := new(Ciphertext).Load(other.ctx, &buf); !errors.Is(err, ErrParmsIDMismatch) {
+		t.Errorf("Ciphertext.Load() = %v; want ErrParmsIDMismatch", err)
+	}
+	buf.Reset()
+	if err := relin.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := new(RelinKeys).Load(other.ctx, &buf); !errors.Is(err, ErrParmsIDMismatch) {
+		t.Errorf("RelinKeys.Load() = %v; want ErrParmsIDMismatch", err)
+	}
+}
+
+func TestUnmarshalCorrupt(t *testing.T) {
+	k := newCKKSKit(t)
+	pub, err := k.keygen.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := pub.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded PublicKey
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.UnmarshalBinary(data[:len(data)/2]); !errors.Is(err, ErrCorruptData) {
+		t.Errorf("UnmarshalBinary(truncated) = %v; want ErrCorruptData", err)
+	}
+	if err := loaded.UnmarshalBinary(nil); !errors.Is(err, ErrCorruptData) {
+		t.Errorf("UnmarshalBinary(nil) = %v; want ErrCorruptData", err)
+	}
+}
+
+func TestEncryptionParamsSaveLoad(t *testing.T) {
+	params, err := NewEncryptionParams(SchemeCKKS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := params.SetPolyModulusDegree(8192); err != nil {
+		t.Fatal(err)
+	}
+	if err := params.SetCoeffModulus([]int{60, 40, 40, 60}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := params.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var loaded EncryptionParams
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := loaded.Scheme(), SchemeCKKS; got != want {
+		t.Errorf("Scheme() = %v; want %v", got, want)
+	}
+	if got, want := loaded.PolyModulusDegree(), 8192; got != want {
+		t.Errorf("PolyModulusDegree() = %d; want %d", got, want)
+	}
+	want := params.CoeffModulus()
+	got := loaded.CoeffModulus()
+	if len(got) != len(want) {
+		t.Fatalf("CoeffModulus() = %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("CoeffModulus() = %v; want %v", got, want)
+		}
+	}
+	if _, err := NewContext(&loaded); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadFrameLimits(t *testing.T) {
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], 1<<30)
+	r := io.MultiReader(bytes.NewReader(size[:]), bytes.NewReader(make([]byte, 10)))
+	if _, err := readFrame("test", r); err != io.ErrUnexpectedEOF {
+		t.Errorf("readFrame(short frame) = %v; want io.ErrUnexpectedEOF", err)
+	}
+	binary.LittleEndian.PutUint64(size[:], 1<<40)
+	if _, err := readFrame("test", bytes.NewReader(size[:])); !errors.Is(err, ErrCorruptData) {
+		t.Errorf("readFrame(huge frame) = %v; want ErrCorruptData", err)
+	}
+}
@@ -0,0 +1,142 @@
+package seal
+
+// #include "seal.h"
+import "C"
+
+import "runtime"
+
+// SymmetricEncryptor encrypts with the secret key, for parties that both
+// encrypt and decrypt. Its ciphertexts carry less noise than public-key ones
+// and can be stored seeded, at about half the size.
+type SymmetricEncryptor struct {
+	ptr C.SEALSymmetricEncryptor
+	native
+}
+
+func NewSymmetricEncryptor(c *Context, key *SecretKey) (*SymmetricEncryptor, error) {
+	if err := checkOpen("NewSymmetricEncryptor", c, key); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALSymmetricEncryptor
+	err := checkError("NewSymmetricEncryptor", C.SEALSymmetricEncryptorInit(c.ptr, key.ptr, &ptr))
+	runtime.KeepAlive(c)
+	runtime.KeepAlive(key)
+	if err != nil {
+		return nil, err
+	}
+	e := &SymmetricEncryptor{
+		ptr: ptr,
+	}
+	runtime.SetFinalizer(e, (*SymmetricEncryptor).free)
+	track(e)
+	return e, nil
+}
+
+func (e *SymmetricEncryptor) Encrypt(p *Plaintext) (*Ciphertext, error) {
+	if err := checkOpen("SymmetricEncryptor.Encrypt", e, p); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALCiphertext
+	err := checkError("SymmetricEncryptor.Encrypt", C.SEALSymmetricEncryptorEncrypt(e.ptr, p.ptr, &ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(p)
+	if err != nil {
+		return nil, err
+	}
+	return newCiphertext(ptr), nil
+}
+
+// EncryptZero returns an encryption of zero at the first parameters.
+func (e *SymmetricEncryptor) EncryptZero() (*Ciphertext, error) {
+	if err := checkOpen("SymmetricEncryptor.EncryptZero", e); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALCiphertext
+	err := checkError("SymmetricEncryptor.EncryptZero", C.SEALSymmetricEncryptorEncryptZero(e.ptr, nil, &ptr))
+	runtime.KeepAlive(e)
+	if err != nil {
+		return nil, err
+	}
+	return newCiphertext(ptr), nil
+}
+
+// EncryptSeeded encrypts p into a SeededCiphertext, which replaces the
+// uniformly random half of the ciphertext by the seed it was generated from.
+func (e *SymmetricEncryptor) EncryptSeeded(p *Plaintext) (*SeededCiphertext, error) {
+	if err := checkOpen("SymmetricEncryptor.EncryptSeeded", e, p); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALSeededCiphertext
+	err := checkError("SymmetricEncryptor.EncryptSeeded", C.SEALSymmetricEncryptorEncryptSeeded(e.ptr, p.ptr, &ptr))
+	runtime.KeepAlive(e)
+	runtime.KeepAlive(p)
+	if err != nil {
+		return nil, err
+	}
+	return newSeededCiphertext(ptr), nil
+}
+
+// SeededCiphertext is a compact symmetric ciphertext meant for storage and
+// transfer. Expand it to compute on it.
+type SeededCiphertext struct {
+	ptr C.SEALSeededCiphertext
+	native
+}
+
+func newSeededCiphertext(ptr C.SEALSeededCiphertext) *SeededCiphertext {
+	s := &SeededCiphertext{
+		ptr: ptr,
+	}
+	runtime.SetFinalizer(s, (*SeededCiphertext).free)
+	track(s)
+	return s
+}
+
+// Expand regenerates the full ciphertext under c.
+func (s *SeededCiphertext) Expand(c *Context) (*Ciphertext, error) {
+	if err := checkOpen("SeededCiphertext.Expand", c, s); err != nil {
+		return nil, err
+	}
+	var ptr C.SEALCiphertext
+	err := checkError("SeededCiphertext.Expand", C.SEALSeededCiphertextExpand(c.ptr, s.ptr, &ptr))
+	runtime.KeepAlive(c)
+	runtime.KeepAlive(s)
+	if err != nil {
+		return nil, err
+	}
+	return newCiphertext(ptr), nil
+}
+
+// chacha20Block returns the RFC 8439 ChaCha20 block for key, counter and
+// nonce. It exists so tests can check the shim's keystream against known
+// answers.
+func chacha20Block(key [32]byte, counter uint32, nonce [12]byte) [64]byte {
+	var out [64]byte
+	C.SEALChaCha20Block((*C.uint8_t)(&key[0]), C.uint32_t(counter), (*C.uint8_t)(&nonce[0]), (*C.uint8_t)(&out[0]))
+	return out
+}
+
+// expandSeed returns the uniform polynomial a that a seeded ciphertext at p
+// expands from seed, one row per prime.
+func (c *Context) expandSeed(p *ParmsID, seed [32]byte) ([][]uint64, error) {
+	if err := checkOpen("Context.expandSeed", c); err != nil {
+		return nil, err
+	}
+	primes, err := c.CoeffModulus(p)
+	if err != nil {
+		return nil, err
+	}
+	n := c.PolyModulusDegree()
+	flat := make([]uint64, len(primes)*n)
+	err = checkError("Context.expandSeed", C.SEALSymmetricExpandSeed(c.ptr, p.ptr, (*C.uint8_t)(&seed[0]), (*C.uint64_t)(&flat[0])))
+	runtime.KeepAlive(c)
+	runtime.KeepAlive(p)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([][]uint64, len(primes))
+	for i := range rows {
+		rows[i] = flat[i*n : (i+1)*n : (i+1)*n]
+	}
+	return rows, nil
+}
@@ -0,0 +1,227 @@
+package seal
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSymmetricEncryptorCKKS(t *testing.T) {
+	k := newCKKSKit(t)
+	sec, err := k.keygen.SecretKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewSymmetricEncryptor(k.ctx, sec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := []float64{0.5, -4, 2.25}
+	p, err := k.enc.EncodeVector(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := e.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Symmetric and public-key ciphertexts mix freely.
+	b, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := k.eval.AddInplace(a, b); err != nil {
+		t.Fatal(err)
+	}
+	out := decryptVector(t, k, a)
+	for i, v := range in {
+		if math.Abs(out[i]-2*v) > 1e-4 {
+			t.Errorf("slot %d = %f; want %f", i, out[i], 2*v)
+		}
+	}
+
+	zero, err := e.EncryptZero()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range decryptVector(t, k, zero)[:4] {
+		if math.Abs(v) > 1e-4 {
+			t.Errorf("EncryptZero slot %d = %f; want 0", i, v)
+		}
+	}
+}
+
+func TestSeededCiphertext(t *testing.T) {
+	k := newCKKSKit(t)
+	sec, err := k.keygen.SecretKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewSymmetricEncryptor(k.ctx, sec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := k.enc.Encode(1.75)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seeded, err := e.EncryptSeeded(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, err := e.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var seededBuf, fullBuf bytes.Buffer
+	if err := seeded.Save(&seededBuf); err != nil {
+		t.Fatal(err)
+	}
+	if err := full.Save(&fullBuf); err != nil {
+		t.Fatal(err)
+	}
+	if got, limit := seededBuf.Len(), fullBuf.Len()*6/10; got > limit {
+		t.Errorf("seeded ciphertext is %d bytes; want at most %d", got, limit)
+	}
+
+	var loaded SeededCiphertext
+	if err := loaded.Load(k.ctx, &seededBuf); err != nil {
+		t.Fatal(err)
+	}
+	c, err := loaded.Expand(k.ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decryptVector(t, k, c)[0]; math.Abs(got-1.75) > 1e-4 {
+		t.Errorf("expanded ciphertext decrypts to %f; want 1.75", got)
+	}
+
+	other := newBFVKit(t)
+	if _, err := loaded.Expand(other.ctx); !errors.Is(err, ErrParmsIDMismatch) {
+		t.Errorf("Expand(other context) = %v; want ErrParmsIDMismatch", err)
+	}
+}
+
+func TestSymmetricEncryptorBFV(t *testing.T) {
+	k := newBFVKit(t)
+	sec, err := k.keygen.SecretKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewSymmetricEncryptor(k.ctx, sec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := []uint64{7, 3, 12}
+	p, err := k.batch.EncodeUint64(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seeded, err := e.EncryptSeeded(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := seeded.Expand(k.ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := decryptBatch(t, k, a)
+	for i, v := range in {
+		if out[i] != v {
+			t.Errorf("slot %d = %d; want %d", i, out[i], v)
+		}
+	}
+
+	ckks := newCKKSKit(t)
+	if _, err := NewSymmetricEncryptor(ckks.ctx, sec); !errors.Is(err, ErrParmsIDMismatch) {
+		t.Errorf("NewSymmetricEncryptor(other context) = %v; want ErrParmsIDMismatch", err)
+	}
+}
+
+// RFC 8439, sections 2.3.2 and A.1.
+func TestChaCha20Vectors(t *testing.T) {
+	var key [32]byte
+	for i := range key {
+		key[i] = byte(i)
+	}
+	tests := []struct {
+		key     [32]byte
+		counter uint32
+		nonce   [12]byte
+		want    string
+	}{
+		{
+			key: key, counter: 1,
+			nonce: [12]byte{0, 0, 0, 0x09, 0, 0, 0, 0x4a},
+			want: "10f1e7e4d13b5915500fdd1fa32071c4c7d1f4c733c068030422aa9ac3d46c4e" +
+				"d2826446079faa0914c2d705d98b02a2b5129cd1de164eb9cbd083e8a2503c4e",
+		},
+		{
+			want: "76b8e0ada0f13d90405d6ae55386bd28bdd219b8a08ded1aa836efcc8b770dc7" +
+				"da41597c5157488d7724e03fb8d84a376a43b8f41518a11cc387b669b2ee6586",
+		},
+		{
+			counter: 1,
+			want: "9f07e7be5551387a98ba977c732d080dcb0f29a048e3656912c6533e32ee7aed" +
+				"29b721769ce64e43d57133b074d839d531ed1f28510afb45ace10a1f4b794d6f",
+		},
+	}
+	for _, tt := range tests {
+		out := chacha20Block(tt.key, tt.counter, tt.nonce)
+		if got := hex.EncodeToString(out[:]); got != tt.want {
+			t.Errorf("chacha20Block(counter %d) = %s; want %s", tt.counter, got, tt.want)
+		}
+	}
+}
+
+// TestSeedExpansionPinned pins the polynomial a expanded from a fixed seed,
+// so seeded ciphertexts stay readable across releases.
+func TestSeedExpansionPinned(t *testing.T) {
+	params, err := NewEncryptionParams(SchemeCKKS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := params.SetPolyModulusDegree(1024); err != nil {
+		t.Fatal(err)
+	}
+	if err := params.SetCoeffModulus([]int{30, 30}); err != nil {
+		t.Fatal(err)
+	}
+	if err := params.SetSecurityLevel(SecurityNone); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewContext(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var seed [32]byte
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	a, err := c.expandSeed(c.FirstParmsID(), seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := params.CoeffModulus(), []uint64{1073707009, 1073698817}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("CoeffModulus() = %v; want %v", got, want)
+	}
+	want := [][]uint64{
+		{616204541, 963713763, 937007587, 210693600, 657854179},
+		{610757218, 597433332, 190027716, 640447515, 292323994},
+	}
+	for i, row := range a {
+		got := append(append([]uint64(nil), row[:4]...), row[len(row)-1])
+		for j, v := range got {
+			if v != want[i][j] {
+				t.Errorf("a[%d] = %v (first four and last); want %v", i, got, want[i])
+				break
+			}
+		}
+	}
+}
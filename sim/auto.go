@@ -0,0 +1,272 @@
+package sim
+
+import (
+	"math"
+)
+
+const (
+	// DefaultScale is the scale CKKSEncoder.Encode encodes at.
+	DefaultScale = 1 << 60
+	// DefaultScaleTolerance is the relative scale difference AutoEvaluator
+	// absorbs by relabelling a scale.
+	DefaultScaleTolerance = 1e-3
+	// minScaleBridge is the smallest scale ratio AutoEvaluator bridges by
+	// multiplying with an encoded one directly; smaller ratios cost a rescale.
+	minScaleBridge = 1 << 20
+)
+
+// AutoEvaluator mirrors seal.AutoEvaluator: operands at different levels or
+// scales are aligned, and products are relinearized and rescaled. Its
+// methods never modify their arguments.
+type AutoEvaluator struct {
+	Evaluator *Evaluator
+	Encoder   *CKKSEncoder
+	RelinKeys *RelinKeys
+	// GaloisKeys are needed by Rotate only.
+	GaloisKeys *GaloisKeys
+	Scale      float64
+	Tolerance  float64
+}
+
+func NewAutoEvaluator(c *Context, relin *RelinKeys) (*AutoEvaluator, error) {
+	eval, err := NewEvaluator(c)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := NewCKKSEncoder(c)
+	if err != nil {
+		return nil, err
+	}
+	return &AutoEvaluator{
+		Evaluator: eval,
+		Encoder:   enc,
+		RelinKeys: relin,
+		Scale:     DefaultScale,
+		Tolerance: DefaultScaleTolerance,
+	}, nil
+}
+
+func (a *AutoEvaluator) Add(x, y *Ciphertext) (*Ciphertext, error) {
+	x, y, err := a.align("AutoEvaluator.Add", x, y, true)
+	if err != nil {
+		return nil, err
+	}
+	return a.Evaluator.Add(x, y)
+}
+
+func (a *AutoEvaluator) Sub(x, y *Ciphertext) (*Ciphertext, error) {
+	x, y, err := a.align("AutoEvaluator.Sub", x, y, true)
+	if err != nil {
+		return nil, err
+	}
+	return a.Evaluator.Sub(x, y)
+}
+
+func (a *AutoEvaluator) Mul(x, y *Ciphertext) (*Ciphertext, error) {
+	x, y, err := a.align("AutoEvaluator.Mul", x, y, false)
+	if err != nil {
+		return nil, err
+	}
+	r, err := a.Evaluator.Multiply(x, y)
+	if err != nil {
+		return nil, err
+	}
+	return r, a.finish(r)
+}
+
+func (a *AutoEvaluator) Square(x *Ciphertext) (*Ciphertext, error) {
+	r, err := a.Evaluator.Square(x)
+	if err != nil {
+		return nil, err
+	}
+	return r, a.finish(r)
+}
+
+// Relinearize returns x relinearized to size 2; x is copied unchanged if it
+// already has size 2.
+func (a *AutoEvaluator) Relinearize(x *Ciphertext) (*Ciphertext, error) {
+	r := x.Copy()
+	if r.Size() <= 2 {
+		return r, nil
+	}
+	return r, a.relinearize(r)
+}
+
+// Rescale returns x rescaled while its scale stays at or above half the
+// working scale.
+func (a *AutoEvaluator) Rescale(x *Ciphertext) (*Ciphertext, error) {
+	r := x.Copy()
+	return r, a.rescale(r)
+}
+
+func (a *AutoEvaluator) Negate(x *Ciphertext) (*Ciphertext, error) {
+	r := x.Copy()
+	if err := a.Evaluator.NegateInplace(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (a *AutoEvaluator) AddConst(x *Ciphertext, v float64) (*Ciphertext, error) {
+	p, err := a.Encoder.EncodeParmsIDScale(v, x.ParmsID(), x.Scale())
+	if err != nil {
+		return nil, err
+	}
+	r := x.Copy()
+	if err := a.Evaluator.AddPlainInplace(r, p); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// AddVector adds the values of v to the slots of x; missing values are zero.
+func (a *AutoEvaluator) AddVector(x *Ciphertext, v []float64) (*Ciphertext, error) {
+	p, err := a.Encoder.EncodeVectorParmsIDScale(v, x.ParmsID(), x.Scale())
+	if err != nil {
+		return nil, err
+	}
+	r := x.Copy()
+	if err := a.Evaluator.AddPlainInplace(r, p); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// MulConst multiplies every slot of x by v. The constant is encoded at the
+// last prime of x's modulus, so rescaling by that prime restores x's scale.
+func (a *AutoEvaluator) MulConst(x *Ciphertext, v float64) (*Ciphertext, error) {
+	return a.mulPlain(x, func(scale float64) (*Plaintext, error) {
+		return a.Encoder.EncodeParmsIDScale(v, x.ParmsID(), scale)
+	})
+}
+
+// MulVector multiplies the slots of x by the values of v, as MulConst does;
+// missing values are zero.
+func (a *AutoEvaluator) MulVector(x *Ciphertext, v []float64) (*Ciphertext, error) {
+	return a.mulPlain(x, func(scale float64) (*Plaintext, error) {
+		return a.Encoder.EncodeVectorParmsIDScale(v, x.ParmsID(), scale)
+	})
+}
+
+func (a *AutoEvaluator) mulPlain(x *Ciphertext, encode func(scale float64) (*Plaintext, error)) (*Ciphertext, error) {
+	scale := a.Scale
+	if x.level > 0 {
+		scale = a.lastPrime(x)
+	}
+	p, err := encode(scale)
+	if err != nil {
+		return nil, err
+	}
+	r, err := a.Evaluator.MultiplyPlain(x, p)
+	if err != nil {
+		return nil, err
+	}
+	if x.level == 0 {
+		return r, nil
+	}
+	if err := a.Evaluator.RescaleToNextInplace(r); err != nil {
+		return nil, err
+	}
+	r.SetScale(x.scale)
+	return r, nil
+}
+
+// Rotate rotates the slots of x left by steps with a.GaloisKeys.
+func (a *AutoEvaluator) Rotate(x *Ciphertext, steps int) (*Ciphertext, error) {
+	return a.Evaluator.RotateVector(x, steps, a.GaloisKeys)
+}
+
+// lastPrime returns the prime a rescale of c divides by.
+func (a *AutoEvaluator) lastPrime(c *Ciphertext) float64 {
+	return math.Exp2(float64(a.Evaluator.ctx.params.CoeffModulusBits[c.level]))
+}
+
+func (a *AutoEvaluator) align(op string, x, y *Ciphertext, matchScale bool) (*Ciphertext, *Ciphertext, error) {
+	x, y, err := a.matchLevels(x, y)
+	if err != nil || !matchScale {
+		return x, y, err
+	}
+
+	sx, sy := x.scale, y.scale
+	switch ratio := sx / sy; {
+	case ratio == 1:
+		return x, y, nil
+	case math.Abs(ratio-1) <= a.Tolerance:
+		y = y.Copy()
+		y.SetScale(sx)
+		return x, y, nil
+	case ratio > 1:
+		y, err = a.raiseScale(op, y, sx)
+	default:
+		x, err = a.raiseScale(op, x, sy)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return a.matchLevels(x, y)
+}
+
+func (a *AutoEvaluator) matchLevels(x, y *Ciphertext) (*Ciphertext, *Ciphertext, error) {
+	var err error
+	if x.level > y.level {
+		if x, err = a.Evaluator.ModSwitchTo(x, y.ParmsID()); err != nil {
+			return nil, nil, err
+		}
+	} else if y.level > x.level {
+		if y, err = a.Evaluator.ModSwitchTo(y, x.ParmsID()); err != nil {
+			return nil, nil, err
+		}
+	}
+	return x, y, nil
+}
+
+func (a *AutoEvaluator) raiseScale(op string, c *Ciphertext, scale float64) (*Ciphertext, error) {
+	ratio := scale / c.scale
+	bridge := ratio < minScaleBridge
+	if bridge {
+		if c.level == 0 {
+			return nil, errorf(op, ErrScaleMismatch, "scale mismatch at the last level: %g and %g", c.scale, scale)
+		}
+		ratio *= a.lastPrime(c)
+	}
+	p, err := a.Encoder.EncodeParmsIDScale(1, c.ParmsID(), ratio)
+	if err != nil {
+		return nil, err
+	}
+	r, err := a.Evaluator.MultiplyPlain(c, p)
+	if err != nil {
+		return nil, err
+	}
+	if bridge {
+		if err := a.Evaluator.RescaleToNextInplace(r); err != nil {
+			return nil, err
+		}
+	}
+	r.SetScale(scale)
+	return r, nil
+}
+
+func (a *AutoEvaluator) finish(r *Ciphertext) error {
+	if err := a.relinearize(r); err != nil {
+		return err
+	}
+	return a.rescale(r)
+}
+
+func (a *AutoEvaluator) relinearize(r *Ciphertext) error {
+	if a.RelinKeys == nil {
+		return errorf("AutoEvaluator.Relinearize", ErrMissingKeys, "no relinearization keys")
+	}
+	return a.Evaluator.RelinearizeInplace(r, a.RelinKeys)
+}
+
+// rescale divides r by the primes at the end of its modulus while the scale
+// stays at or above half the working scale.
+func (a *AutoEvaluator) rescale(r *Ciphertext) error {
+	for r.level > 0 && r.scale/a.lastPrime(r) >= a.Scale/2 {
+		if err := a.Evaluator.RescaleToNextInplace(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+)
+
+// ScaleReport describes how much room a ciphertext has left, as in package
+// seal.
+type ScaleReport struct {
+	Level         int
+	ScaleBits     float64
+	ModulusBits   float64
+	RemainingBits float64
+}
+
+func (r ScaleReport) String() string {
+	return fmt.Sprintf("level %d, scale 2^%.1f, modulus 2^%.1f, %.1f bits remaining",
+		r.Level, r.ScaleBits, r.ModulusBits, r.RemainingBits)
+}
+
+type Evaluator struct {
+	ctx *Context
+}
+
+func NewEvaluator(c *Context) (*Evaluator, error) {
+	return &Evaluator{ctx: c}, nil
+}
+
+func (e *Evaluator) Level(a *Ciphertext) (int, error) {
+	return a.level, nil
+}
+
+func (e *Evaluator) Square(c *Ciphertext) (*Ciphertext, error) {
+	c = c.Copy()
+	if err := e.SquareInplace(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (e *Evaluator) SquareInplace(c *Ciphertext) error {
+	return e.MultiplyInplace(c, c.Copy())
+}
+
+func (e *Evaluator) NegateInplace(c *Ciphertext) error {
+	for i := range c.values {
+		c.values[i] = -c.values[i]
+	}
+	return nil
+}
+
+func (e *Evaluator) Add(a, b *Ciphertext) (*Ciphertext, error) {
+	a = a.Copy()
+	if err := e.AddInplace(a, b); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (e *Evaluator) AddInplace(a, b *Ciphertext) error {
+	if err := e.checkSame("Evaluator.AddInplace", a.level, a.scale, b.level, b.scale); err != nil {
+		return err
+	}
+	for i := range a.values {
+		a.values[i] += b.values[i]
+	}
+	if b.size > a.size {
+		a.size = b.size
+	}
+	e.ctx.checkOverflow(a)
+	return nil
+}
+
+func (e *Evaluator) AddPlainInplace(a *Ciphertext, b *Plaintext) error {
+	if err := e.checkSame("Evaluator.AddPlainInplace", a.level, a.scale, b.level, b.scale); err != nil {
+		return err
+	}
+	for i := range a.values {
+		a.values[i] += b.values[i]
+	}
+	e.ctx.checkOverflow(a)
+	return nil
+}
+
+func (e *Evaluator) Sub(a, b *Ciphertext) (*Ciphertext, error) {
+	a = a.Copy()
+	if err := e.SubInplace(a, b); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (e *Evaluator) SubInplace(a, b *Ciphertext) error {
+	if err := e.checkSame("Evaluator.SubInplace", a.level, a.scale, b.level, b.scale); err != nil {
+		return err
+	}
+	for i := range a.values {
+		a.values[i] -= b.values[i]
+	}
+	if b.size > a.size {
+		a.size = b.size
+	}
+	e.ctx.checkOverflow(a)
+	return nil
+}
+
+func (e *Evaluator) SubPlainInplace(a *Ciphertext, b *Plaintext) error {
+	if err := e.checkSame("Evaluator.SubPlainInplace", a.level, a.scale, b.level, b.scale); err != nil {
+		return err
+	}
+	for i := range a.values {
+		a.values[i] -= b.values[i]
+	}
+	e.ctx.checkOverflow(a)
+	return nil
+}
+
+func (e *Evaluator) Multiply(a, b *Ciphertext) (*Ciphertext, error) {
+	a = a.Copy()
+	if err := e.MultiplyInplace(a, b); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (e *Evaluator) MultiplyInplace(a, b *Ciphertext) error {
+	if err := e.multiply("Evaluator.MultiplyInplace", a, b.values, b.level, b.scale); err != nil {
+		return err
+	}
+	a.size += b.size - 1
+	return nil
+}
+
+func (e *Evaluator) MultiplyPlain(a *Ciphertext, b *Plaintext) (*Ciphertext, error) {
+	a = a.Copy()
+	if err := e.MultiplyPlainInplace(a, b); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (e *Evaluator) MultiplyPlainInplace(a *Ciphertext, b *Plaintext) error {
+	return e.multiply("Evaluator.MultiplyPlainInplace", a, b.values, b.level, b.scale)
+}
+
+func (e *Evaluator) multiply(op string, a *Ciphertext, values []float64, level int, scale float64) error {
+	if a.level != level {
+		return errorf(op, ErrParmsIDMismatch, "encrypted1 and encrypted2 parameter mismatch")
+	}
+	product := a.scale * scale
+	if math.Log2(product) >= float64(e.ctx.modulusBits(a.level)) {
+		return errorf(op, ErrScaleOutOfBounds, "scale out of bounds")
+	}
+	for i := range a.values {
+		a.values[i] *= values[i]
+	}
+	a.scale = product
+	e.ctx.checkOverflow(a)
+	return nil
+}
+
+func (e *Evaluator) RelinearizeInplace(a *Ciphertext, k *RelinKeys) error {
+	if k == nil {
+		return errorf("Evaluator.RelinearizeInplace", ErrMissingKeys, "relinearization keys are missing")
+	}
+	a.size = 2
+	return nil
+}
+
+func (e *Evaluator) ExponentiateInplace(a *Ciphertext, power int64, k *RelinKeys) error {
+	if power < 1 {
+		return errorf("Evaluator.ExponentiateInplace", ErrInvalidArgument, "exponent must be positive")
+	}
+	base := a.Copy()
+	for i := int64(1); i < power; i++ {
+		if err := e.MultiplyInplace(a, base); err != nil {
+			return err
+		}
+		if err := e.RelinearizeInplace(a, k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RescaleToNextInplace divides the scale by the last prime and adds the
+// rounding error of the division.
+func (e *Evaluator) RescaleToNextInplace(a *Ciphertext) error {
+	if a.level == 0 {
+		return errorf("Evaluator.RescaleToNextInplace", ErrEndOfModulusChain, "end of modulus switching chain reached")
+	}
+	a.scale /= math.Exp2(float64(e.ctx.params.CoeffModulusBits[a.level]))
+	a.level--
+	for i := range a.values {
+		a.values[i] += e.ctx.noise(a.scale)
+	}
+	e.ctx.checkOverflow(a)
+	return nil
+}
+
+func (e *Evaluator) RescaleToInplace(a *Ciphertext, p *ParmsID) error {
+	level, err := e.ctx.ChainIndex(p)
+	if err != nil {
+		return err
+	}
+	if level > a.level {
+		return errorf("Evaluator.RescaleToInplace", ErrEndOfModulusChain, "cannot switch to higher level modulus")
+	}
+	for a.level > level {
+		if err := e.RescaleToNextInplace(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ModSwitchToNextInplace drops the last prime without changing the scale.
+func (e *Evaluator) ModSwitchToNextInplace(a *Ciphertext) error {
+	if a.level == 0 {
+		return errorf("Evaluator.ModSwitchToNextInplace", ErrEndOfModulusChain, "end of modulus switching chain reached")
+	}
+	a.level--
+	e.ctx.checkOverflow(a)
+	return nil
+}
+
+func (e *Evaluator) ModSwitchToNext(a *Ciphertext) (*Ciphertext, error) {
+	a = a.Copy()
+	if err := e.ModSwitchToNextInplace(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (e *Evaluator) ModSwitchToInplace(a *Ciphertext, p *ParmsID) error {
+	level, err := e.ctx.ChainIndex(p)
+	if err != nil {
+		return err
+	}
+	if level > a.level {
+		return errorf("Evaluator.ModSwitchToInplace", ErrEndOfModulusChain, "cannot switch to higher level modulus")
+	}
+	a.level = level
+	e.ctx.checkOverflow(a)
+	return nil
+}
+
+func (e *Evaluator) ModSwitchTo(a *Ciphertext, p *ParmsID) (*Ciphertext, error) {
+	a = a.Copy()
+	if err := e.ModSwitchToInplace(a, p); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (e *Evaluator) ModSwitchToNextPlainInplace(a *Plaintext) error {
+	if a.level == 0 {
+		return errorf("Evaluator.ModSwitchToNextPlainInplace", ErrEndOfModulusChain, "end of modulus switching chain reached")
+	}
+	a.level--
+	return nil
+}
+
+func (e *Evaluator) ModSwitchToPlainInplace(a *Plaintext, p *ParmsID) error {
+	level, err := e.ctx.ChainIndex(p)
+	if err != nil {
+		return err
+	}
+	if level > a.level {
+		return errorf("Evaluator.ModSwitchToPlainInplace", ErrEndOfModulusChain, "cannot switch to higher level modulus")
+	}
+	a.level = level
+	return nil
+}
+
+// MatchLevels switches whichever of a and b is higher in the modulus chain
+// down to the other's level.
+func (e *Evaluator) MatchLevels(a, b *Ciphertext) error {
+	if a.level > b.level {
+		return e.ModSwitchToInplace(a, b.ParmsID())
+	}
+	if b.level > a.level {
+		return e.ModSwitchToInplace(b, a.ParmsID())
+	}
+	return nil
+}
+
+// ScaleReport reports the level and scale headroom of a.
+func (e *Evaluator) ScaleReport(a *Ciphertext) (ScaleReport, error) {
+	modulus := float64(e.ctx.modulusBits(a.level))
+	scale := math.Log2(a.scale)
+	return ScaleReport{
+		Level:         a.level,
+		ScaleBits:     scale,
+		ModulusBits:   modulus,
+		RemainingBits: modulus - scale,
+	}, nil
+}
+
+// checkSame applies SEAL's rule that added operands share parms_id and scale.
+func (e *Evaluator) checkSame(op string, la int, sa float64, lb int, sb float64) error {
+	if la != lb {
+		return errorf(op, ErrParmsIDMismatch, "encrypted1 and encrypted2 parameter mismatch")
+	}
+	if sa != sb {
+		return errorf(op, ErrScaleMismatch, "scale mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package sim
+
+import "github.com/d4l3k/go-fheml/poly"
+
+// Polynomial and its bases are defined in package poly, which holds the
+// evaluation plan shared with package seal.
+type (
+	Polynomial = poly.Polynomial
+	Basis      = poly.Basis
+)
+
+const (
+	Monomial  = poly.Monomial
+	Chebyshev = poly.Chebyshev
+)
+
+// EvaluatePolynomial evaluates p on every slot of x with baby-step
+// giant-step evaluation, which needs p.Depth() levels and about 2*sqrt(d)
+// ciphertext multiplications for degree d. Products are relinearized and
+// rescaled as they are formed.
+func (a *AutoEvaluator) EvaluatePolynomial(x *Ciphertext, p *Polynomial) (*Ciphertext, error) {
+	if err := p.Check(); err != nil {
+		return nil, errorf("AutoEvaluator.EvaluatePolynomial", ErrInvalidArgument, "%v", err)
+	}
+	r, err := poly.Evaluate(polyEvaluator{a}, x, p)
+	if err != nil {
+		return nil, err
+	}
+	return r.(*Ciphertext), nil
+}
+
+// polyEvaluator adapts an AutoEvaluator to poly.Evaluator.
+type polyEvaluator struct {
+	a *AutoEvaluator
+}
+
+func (e polyEvaluator) Mul(x, y poly.Ciphertext) (poly.Ciphertext, error) {
+	return e.a.Mul(x.(*Ciphertext), y.(*Ciphertext))
+}
+
+func (e polyEvaluator) Square(x poly.Ciphertext) (poly.Ciphertext, error) {
+	return e.a.Square(x.(*Ciphertext))
+}
+
+func (e polyEvaluator) Add(x, y poly.Ciphertext) (poly.Ciphertext, error) {
+	return e.a.Add(x.(*Ciphertext), y.(*Ciphertext))
+}
+
+func (e polyEvaluator) Sub(x, y poly.Ciphertext) (poly.Ciphertext, error) {
+	return e.a.Sub(x.(*Ciphertext), y.(*Ciphertext))
+}
+
+func (e polyEvaluator) MulConst(x poly.Ciphertext, v float64) (poly.Ciphertext, error) {
+	return e.a.MulConst(x.(*Ciphertext), v)
+}
+
+func (e polyEvaluator) AddConst(x poly.Ciphertext, v float64) (poly.Ciphertext, error) {
+	return e.a.AddConst(x.(*Ciphertext), v)
+}
@@ -0,0 +1,86 @@
+package sim
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func deepParams() Params {
+	bits := make([]int, 12)
+	for i := range bits {
+		bits[i] = 60
+	}
+	return Params{PolyModulusDegree: 16, CoeffModulusBits: bits, NoiseStdDev: 3.19}
+}
+
+func TestPolynomialEvaluate(t *testing.T) {
+	p := &Polynomial{Basis: Chebyshev, Coeffs: []float64{0.5, -1, 0.25, 2, 0, 0}}
+	for _, x := range []float64{-1, -0.3, 0, 0.8} {
+		want := 0.0
+		for i, c := range p.Coeffs {
+			want += c * math.Cos(float64(i)*math.Acos(x))
+		}
+		if got := p.Evaluate(x); math.Abs(got-want) > 1e-12 {
+			t.Errorf("Evaluate(%g) = %g; want %g", x, got, want)
+		}
+	}
+	if d := p.Degree(); d != 3 {
+		t.Errorf("Degree() = %d; want 3", d)
+	}
+}
+
+func TestEvaluatePolynomial(t *testing.T) {
+	in := []float64{-3.5, -1, 0, 0.25, 2, 4}
+	tests := []*Polynomial{
+		{Coeffs: []float64{1.5}},
+		{Coeffs: []float64{0.5, -2}},
+		{Coeffs: []float64{1, 0.5, -0.25, 0.125}},
+		{Coeffs: []float64{0.5, 0.197, 0, -0.004, 0, 0.00001, 0, 0}},
+		{Coeffs: []float64{1, -1, 1, -1, 1, -1, 1, -1, 1, -1, 0.5}},
+		{Basis: Chebyshev, A: -4, B: 4, Coeffs: []float64{0.5, 0.6, 0, -0.1, 0, 0.02, 0, -0.003}},
+		{Basis: Chebyshev, Coeffs: []float64{0, 1, 0.5, 0.25, 0.125}},
+	}
+	for _, p := range tests {
+		k := newKit(t, deepParams())
+		x := k.encrypt(t, in...)
+		xs := in
+		if p.Basis == Chebyshev && p.A == 0 {
+			xs = []float64{-1, -0.5, 0, 0.25, 0.75, 1}
+			x = k.encrypt(t, xs...)
+		}
+		r, err := k.auto.EvaluatePolynomial(x, p)
+		if err != nil {
+			t.Errorf("%v %v: %v", p.Basis, p.Coeffs, err)
+			continue
+		}
+		if used := x.Level() - r.Level(); used != p.Depth() {
+			t.Errorf("%v %v: used %d levels; Depth() = %d", p.Basis, p.Coeffs, used, p.Depth())
+		}
+		out := k.decrypt(t, r)
+		for i, v := range xs {
+			if want := p.Evaluate(v); math.Abs(out[i]-want) > 1e-6*math.Max(1, math.Abs(want)) {
+				t.Errorf("%v %v: p(%g) = %g; want %g", p.Basis, p.Coeffs, v, out[i], want)
+			}
+		}
+	}
+}
+
+func TestEvaluatePolynomialErrors(t *testing.T) {
+	k := newKit(t, deepParams())
+	x := k.encrypt(t, 1)
+	for _, p := range []*Polynomial{
+		{},
+		{Basis: Basis(7), Coeffs: []float64{1}},
+		{Basis: Chebyshev, A: 2, B: 1, Coeffs: []float64{1}},
+	} {
+		if _, err := k.auto.EvaluatePolynomial(x, p); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("EvaluatePolynomial(%+v) = %v; want ErrInvalidArgument", p, err)
+		}
+	}
+	deep := &Polynomial{Coeffs: make([]float64, 1<<12)}
+	deep.Coeffs[len(deep.Coeffs)-1] = 1
+	if _, err := k.auto.EvaluatePolynomial(x, deep); err == nil {
+		t.Error("EvaluatePolynomial(degree 4095) succeeded on 11 levels")
+	}
+}
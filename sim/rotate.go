@@ -0,0 +1,84 @@
+package sim
+
+// GaloisKeys allow the Evaluator to rotate slots.
+type GaloisKeys struct {
+	// steps holds the generated rotations; nil stands for the powers of two.
+	steps map[int]bool
+}
+
+func (k *GaloisKeys) has(step int) bool {
+	if k.steps != nil {
+		return k.steps[step]
+	}
+	if step < 0 {
+		step = -step
+	}
+	return step&(step-1) == 0
+}
+
+// naf returns the non-adjacent form of n as signed powers of two, which is
+// how SEAL composes a rotation it has no key for.
+func naf(n int) []int {
+	var terms []int
+	for bit := 1; n != 0; bit *= 2 {
+		if n%2 != 0 {
+			// Pick the digit that leaves n divisible by four.
+			d := 2 - ((n%4)+4)%4
+			terms = append(terms, d*bit)
+			n -= d
+		}
+		n /= 2
+	}
+	return terms
+}
+
+// RotateVectorInplace cyclically rotates the CKKS slots of a left by steps;
+// negative steps rotate right. Like SEAL it fails with ErrMissingKeys if k
+// has neither a key for steps nor for every term of its non-adjacent form.
+func (e *Evaluator) RotateVectorInplace(a *Ciphertext, steps int, k *GaloisKeys) error {
+	if k == nil {
+		return errorf("Evaluator.RotateVectorInplace", ErrMissingKeys, "Galois keys are missing")
+	}
+	n := len(a.values)
+	if steps%n == 0 {
+		return nil
+	}
+	if !k.has(steps) {
+		for _, t := range naf(steps) {
+			if !k.has(t) {
+				return errorf("Evaluator.RotateVectorInplace", ErrMissingKeys, "Galois key not present")
+			}
+		}
+	}
+	shift := ((steps % n) + n) % n
+	rotated := make([]float64, n)
+	for i := range rotated {
+		rotated[i] = a.values[(i+shift)%n]
+	}
+	a.values = rotated
+	return nil
+}
+
+func (e *Evaluator) RotateVector(a *Ciphertext, steps int, k *GaloisKeys) (*Ciphertext, error) {
+	a = a.Copy()
+	if err := e.RotateVectorInplace(a, steps, k); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// SumSlots returns a ciphertext in which every slot holds the sum of all
+// slots of a.
+func (e *Evaluator) SumSlots(a *Ciphertext, k *GaloisKeys) (*Ciphertext, error) {
+	sum := a.Copy()
+	for step := 1; step < e.ctx.SlotCount(); step *= 2 {
+		rotated, err := e.RotateVector(sum, step, k)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.AddInplace(sum, rotated); err != nil {
+			return nil, err
+		}
+	}
+	return sum, nil
+}
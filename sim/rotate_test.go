@@ -0,0 +1,77 @@
+package sim
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestRotateVector(t *testing.T) {
+	k := newKit(t, Params{PolyModulusDegree: 16, CoeffModulusBits: []int{60, 60}})
+	keygen, _ := NewKeyGenerator(k.ctx)
+	a := k.encrypt(t, 1, 2, 3, 4)
+
+	all, _ := keygen.GaloisKeys(60)
+	tests := []struct {
+		steps int
+		want  []float64
+	}{
+		{1, []float64{2, 3, 4, 0, 0, 0, 0, 1}},
+		{3, []float64{4, 0, 0, 0, 0, 1, 2, 3}},
+		{-2, []float64{0, 0, 1, 2, 3, 4, 0, 0}},
+	}
+	for _, tt := range tests {
+		r, err := k.eval.RotateVector(a, tt.steps, all)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := k.decrypt(t, r)
+		for i, w := range tt.want {
+			if math.Abs(out[i]-w) > 1e-9 {
+				t.Errorf("rotate %d: slot %d = %g; want %g", tt.steps, i, out[i], w)
+			}
+		}
+	}
+
+	sum, err := k.eval.SumSlots(a, all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := k.decrypt(t, sum); math.Abs(out[5]-10) > 1e-9 {
+		t.Errorf("SumSlots slot 5 = %g; want 10", out[5])
+	}
+
+	// 3 = 4 - 1, so keys for 4 and -1 compose it but keys for 2 do not.
+	some, _ := keygen.GaloisKeys(60, 4, -1)
+	if _, err := k.eval.RotateVector(a, 3, some); err != nil {
+		t.Errorf("RotateVector(3) with keys for 4 and -1 = %v", err)
+	}
+	two, _ := keygen.GaloisKeys(60, 2)
+	if _, err := k.eval.RotateVector(a, 3, two); !errors.Is(err, ErrMissingKeys) {
+		t.Errorf("RotateVector(3) with keys for 2 = %v; want ErrMissingKeys", err)
+	}
+	if _, err := k.auto.Rotate(a, 1); !errors.Is(err, ErrMissingKeys) {
+		t.Errorf("Rotate(no keys) = %v; want ErrMissingKeys", err)
+	}
+}
+
+func TestVectorConstants(t *testing.T) {
+	k := newKit(t, Params{PolyModulusDegree: 16, CoeffModulusBits: []int{60, 60, 60}})
+	a := k.encrypt(t, 1, 2, 3)
+	r, err := k.auto.MulVector(a, []float64{2, 0, -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, err = k.auto.AddVector(r, []float64{0, 1}); err != nil {
+		t.Fatal(err)
+	}
+	if r.Level() != a.Level()-1 || r.Scale() != a.Scale() {
+		t.Errorf("MulVector() = %v; want level %d at scale %g", r, a.Level()-1, a.Scale())
+	}
+	out := k.decrypt(t, r)
+	for i, w := range []float64{2, 1, -3} {
+		if math.Abs(out[i]-w) > 1e-9 {
+			t.Errorf("slot %d = %g; want %g", i, out[i], w)
+		}
+	}
+}
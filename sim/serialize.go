@@ -0,0 +1,140 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// maxFrameSize bounds the length prefix read by Load, as in package seal.
+// The buffer for a frame grows only as its data arrives, so a corrupt
+// prefix cannot trigger a large allocation.
+const maxFrameSize = math.MaxInt32
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (c *Ciphertext) MarshalBinary() ([]byte, error) {
+	var b bytes.Buffer
+	put := func(v interface{}) {
+		// Writes to a bytes.Buffer cannot fail.
+		binary.Write(&b, binary.LittleEndian, v)
+	}
+	params := c.ctx.params
+	put(uint64(params.PolyModulusDegree))
+	put(uint32(len(params.CoeffModulusBits)))
+	for _, bits := range params.CoeffModulusBits {
+		put(uint32(bits))
+	}
+	put(uint32(c.level))
+	put(uint32(c.size))
+	put(c.scale)
+	put(c.overflowed)
+	put(uint32(len(c.values)))
+	put(c.values)
+	return b.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler without checking the
+// data against a Context; prefer Load for untrusted input.
+func (c *Ciphertext) UnmarshalBinary(data []byte) error {
+	return c.load("Ciphertext.UnmarshalBinary", nil, data)
+}
+
+// Save writes a ciphertext as a little-endian uint64 length followed by
+// MarshalBinary's encoding, like package seal. The encoding records the
+// simulated parameters so Load can check them against a Context.
+func (c *Ciphertext) Save(w io.Writer) error {
+	data, err := c.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(data)))
+	if _, err := w.Write(size[:]); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Load replaces c with a ciphertext written by Save, returning
+// ErrParmsIDMismatch if it was made under parameters other than ctx's.
+func (c *Ciphertext) Load(ctx *Context, r io.Reader) error {
+	var size [8]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return err
+	}
+	n := binary.LittleEndian.Uint64(size[:])
+	if n > maxFrameSize {
+		return errorf("Ciphertext.Load", ErrCorruptData, "serialized object of %d bytes exceeds limit", n)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, int64(n)))
+	if err != nil {
+		return err
+	}
+	if uint64(len(data)) < n {
+		return io.ErrUnexpectedEOF
+	}
+	return c.load("Ciphertext.Load", ctx, data)
+}
+
+func (c *Ciphertext) load(op string, ctx *Context, data []byte) error {
+	corrupt := errorf(op, ErrCorruptData, "serialized data is truncated or corrupt")
+	r := bytes.NewReader(data)
+	var fail bool
+	get := func(v interface{}) {
+		if !fail && binary.Read(r, binary.LittleEndian, v) != nil {
+			fail = true
+		}
+	}
+	var degree uint64
+	var nbits, level, size, nvalues uint32
+	get(&degree)
+	get(&nbits)
+	if fail || nbits > 64 {
+		return corrupt
+	}
+	bits := make([]uint32, nbits)
+	get(bits)
+	var loaded Ciphertext
+	get(&level)
+	get(&size)
+	get(&loaded.scale)
+	get(&loaded.overflowed)
+	get(&nvalues)
+	if fail || int(nvalues)*8 > r.Len() || int(level) >= len(bits) || size < 2 {
+		return corrupt
+	}
+	loaded.values = make([]float64, nvalues)
+	get(loaded.values)
+	if fail || r.Len() != 0 || math.IsNaN(loaded.scale) {
+		return corrupt
+	}
+	loaded.level, loaded.size = int(level), int(size)
+
+	params := Params{PolyModulusDegree: int(degree)}
+	for _, b := range bits {
+		params.CoeffModulusBits = append(params.CoeffModulusBits, int(b))
+	}
+	if ctx == nil {
+		var err error
+		if ctx, err = NewContext(params); err != nil {
+			return corrupt
+		}
+	} else {
+		same := params.PolyModulusDegree == ctx.params.PolyModulusDegree &&
+			len(params.CoeffModulusBits) == len(ctx.params.CoeffModulusBits)
+		for i := 0; same && i < len(params.CoeffModulusBits); i++ {
+			same = params.CoeffModulusBits[i] == ctx.params.CoeffModulusBits[i]
+		}
+		if !same {
+			return errorf(op, ErrParmsIDMismatch, "ciphertext data is not valid for encryption parameters")
+		}
+	}
+	if len(loaded.values) != ctx.SlotCount() {
+		return corrupt
+	}
+	loaded.ctx = ctx
+	*c = loaded
+	return nil
+}
@@ -0,0 +1,60 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestCiphertextSaveLoad(t *testing.T) {
+	params := Params{PolyModulusDegree: 16, CoeffModulusBits: []int{60, 60, 60}}
+	k := newKit(t, params)
+	a, err := k.auto.MulConst(k.encrypt(t, 1.5, -2), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := a.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	var b Ciphertext
+	if err := b.Load(k.ctx, bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if b.Level() != a.Level() || b.Scale() != a.Scale() {
+		t.Errorf("loaded %v; want %v", &b, a)
+	}
+	out := k.decrypt(t, &b)
+	for i, w := range []float64{4.5, -6} {
+		if math.Abs(out[i]-w) > 1e-9 {
+			t.Errorf("slot %d = %g; want %g", i, out[i], w)
+		}
+	}
+
+	other := newKit(t, Params{PolyModulusDegree: 16, CoeffModulusBits: []int{60, 60}})
+	if err := b.Load(other.ctx, bytes.NewReader(data)); !errors.Is(err, ErrParmsIDMismatch) {
+		t.Errorf("Load(other context) = %v; want ErrParmsIDMismatch", err)
+	}
+	if err := b.Load(k.ctx, bytes.NewReader(data[:len(data)-3])); err != io.ErrUnexpectedEOF {
+		t.Errorf("Load(truncated) = %v; want io.ErrUnexpectedEOF", err)
+	}
+	// A large length prefix is not allocated up front.
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], 1<<30)
+	r := io.MultiReader(bytes.NewReader(size[:]), bytes.NewReader(make([]byte, 10)))
+	if err := b.Load(k.ctx, r); err != io.ErrUnexpectedEOF {
+		t.Errorf("Load(short frame) = %v; want io.ErrUnexpectedEOF", err)
+	}
+	binary.LittleEndian.PutUint64(size[:], 1<<40)
+	if err := b.Load(k.ctx, bytes.NewReader(size[:])); !errors.Is(err, ErrCorruptData) {
+		t.Errorf("Load(huge frame) = %v; want ErrCorruptData", err)
+	}
+	if err := b.UnmarshalBinary(data[8:20]); !errors.Is(err, ErrCorruptData) {
+		t.Errorf("UnmarshalBinary(truncated) = %v; want ErrCorruptData", err)
+	}
+}
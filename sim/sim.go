@@ -0,0 +1,395 @@
+// Package sim simulates CKKS on cleartext float64 vectors. It mirrors the
+// method set of package seal, so code written against seal can be run and
+// inspected here in a fraction of the time: ciphertexts carry their slot
+// values in the clear, while levels, scale growth, encoding and rescaling
+// error, and modulus overflow are simulated.
+//
+// sim is a debugging aid and provides no security whatsoever.
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Errors mirroring the typed causes in package seal.
+var (
+	ErrScaleMismatch     = errors.New("sim: scale mismatch")
+	ErrScaleOutOfBounds  = errors.New("sim: scale out of bounds")
+	ErrParmsIDMismatch   = errors.New("sim: parms_id mismatch")
+	ErrEndOfModulusChain = errors.New("sim: end of modulus switching chain reached")
+	ErrMissingKeys       = errors.New("sim: required keys are missing")
+	ErrInvalidParameters = errors.New("sim: encryption parameters are not valid")
+	ErrInvalidArgument   = errors.New("sim: invalid argument")
+	ErrCorruptData       = errors.New("sim: serialized data is truncated or corrupt")
+)
+
+func errorf(op string, err error, format string, args ...interface{}) error {
+	return fmt.Errorf("sim: %s: %s: %w", op, fmt.Sprintf(format, args...), err)
+}
+
+// Params describes the simulated encryption parameters.
+type Params struct {
+	PolyModulusDegree int
+	// CoeffModulusBits holds the bit size of each prime of the coefficient
+	// modulus. Rescaling divides by the last remaining prime, taken to be
+	// exactly 2^bits.
+	CoeffModulusBits []int
+	// NoiseStdDev is the standard deviation of the fresh encryption error
+	// polynomial, 3.19 in SEAL. Zero disables simulated noise.
+	NoiseStdDev float64
+	// Seed seeds the noise source so runs are reproducible.
+	Seed int64
+}
+
+// DefaultParams mirrors the default CKKS parameters of package seal.
+func DefaultParams() Params {
+	return Params{
+		PolyModulusDegree: 16384,
+		CoeffModulusBits:  []int{55, 54, 54, 54, 55, 55, 55, 55},
+		NoiseStdDev:       3.19,
+	}
+}
+
+type Context struct {
+	params Params
+	rand   *rand.Rand
+}
+
+func NewContext(params Params) (*Context, error) {
+	n := params.PolyModulusDegree
+	if n < 2 || n&(n-1) != 0 {
+		return nil, errorf("NewContext", ErrInvalidParameters, "poly modulus degree %d is not a power of two", n)
+	}
+	if len(params.CoeffModulusBits) == 0 {
+		return nil, errorf("NewContext", ErrInvalidParameters, "coefficient modulus is empty")
+	}
+	for _, b := range params.CoeffModulusBits {
+		if b <= 0 || b > 60 {
+			return nil, errorf("NewContext", ErrInvalidParameters, "coefficient modulus bit size %d out of range", b)
+		}
+	}
+	params.CoeffModulusBits = append([]int(nil), params.CoeffModulusBits...)
+	return &Context{
+		params: params,
+		rand:   rand.New(rand.NewSource(params.Seed)),
+	}, nil
+}
+
+func (c *Context) PolyModulusDegree() int {
+	return c.params.PolyModulusDegree
+}
+
+func (c *Context) SlotCount() int {
+	return c.params.PolyModulusDegree / 2
+}
+
+func (c *Context) FirstParmsID() *ParmsID {
+	return &ParmsID{level: len(c.params.CoeffModulusBits) - 1, set: true}
+}
+
+func (c *Context) LastParmsID() *ParmsID {
+	return &ParmsID{level: 0, set: true}
+}
+
+// ChainIndex returns how many rescales are left at p.
+func (c *Context) ChainIndex(p *ParmsID) (int, error) {
+	if !p.set || p.level < 0 || p.level >= len(c.params.CoeffModulusBits) {
+		return 0, errorf("Context.ChainIndex", ErrParmsIDMismatch, "parms_id is not valid for encryption parameters")
+	}
+	return p.level, nil
+}
+
+// modulusBits returns the size of the coefficient modulus at a level.
+func (c *Context) modulusBits(level int) int {
+	bits := 0
+	for _, b := range c.params.CoeffModulusBits[:level+1] {
+		bits += b
+	}
+	return bits
+}
+
+// noise returns the slot error of a fresh encryption or a rescale at scale.
+func (c *Context) noise(scale float64) float64 {
+	if c.params.NoiseStdDev == 0 {
+		return 0
+	}
+	return c.rand.NormFloat64() * c.params.NoiseStdDev * math.Sqrt(float64(c.params.PolyModulusDegree)) / scale
+}
+
+// ParmsID identifies a level of the modulus chain. The zero value stands
+// for the first parameters, as in package seal.
+type ParmsID struct {
+	level int
+	set   bool
+}
+
+func (a *ParmsID) Eq(b *ParmsID) bool {
+	return a.set == b.set && a.level == b.level
+}
+
+func (a *ParmsID) Close() error {
+	return nil
+}
+
+type Plaintext struct {
+	values []float64
+	level  int
+	scale  float64
+}
+
+func (p *Plaintext) ParmsID() *ParmsID {
+	return &ParmsID{level: p.level, set: true}
+}
+
+func (p *Plaintext) Scale() float64 {
+	return p.scale
+}
+
+func (p *Plaintext) Close() error {
+	return nil
+}
+
+// Ciphertext holds its slot values in the clear.
+type Ciphertext struct {
+	// ctx is the context the ciphertext was made under, for serialization.
+	ctx        *Context
+	values     []float64
+	level      int
+	scale      float64
+	size       int
+	overflowed bool
+}
+
+func (c *Ciphertext) Copy() *Ciphertext {
+	r := *c
+	r.values = append([]float64(nil), c.values...)
+	return &r
+}
+
+func (c *Ciphertext) Scale() float64 {
+	return c.scale
+}
+
+// SetScale relabels the scale, which changes the decrypted values by the
+// ratio of the scales as it would in SEAL.
+func (c *Ciphertext) SetScale(scale float64) {
+	ratio := c.scale / scale
+	for i := range c.values {
+		c.values[i] *= ratio
+	}
+	c.scale = scale
+}
+
+func (c *Ciphertext) ParmsID() *ParmsID {
+	return &ParmsID{level: c.level, set: true}
+}
+
+func (c *Ciphertext) Size() int {
+	return c.size
+}
+
+func (c *Ciphertext) Level() int {
+	return c.level
+}
+
+func (c *Ciphertext) CoeffModulusCount() int {
+	return c.level + 1
+}
+
+// Values returns the simulated slot values without decrypting, including
+// whatever error has accumulated.
+func (c *Ciphertext) Values() []float64 {
+	return append([]float64(nil), c.values...)
+}
+
+// Overflowed reports whether a value outgrew the coefficient modulus at
+// some point, after which SEAL would decrypt garbage.
+func (c *Ciphertext) Overflowed() bool {
+	return c.overflowed
+}
+
+func (c *Ciphertext) Close() error {
+	return nil
+}
+
+func (c *Ciphertext) String() string {
+	return fmt.Sprintf("Ciphertext(size %d, level %d, scale 2^%.2f)", c.size, c.level, math.Log2(c.scale))
+}
+
+// checkOverflow marks c once its largest value times its scale no longer
+// fits in the coefficient modulus.
+func (ctx *Context) checkOverflow(c *Ciphertext) {
+	max := 0.0
+	for _, v := range c.values {
+		max = math.Max(max, math.Abs(v))
+	}
+	if max > 0 && math.Log2(max*c.scale)+1 >= float64(ctx.modulusBits(c.level)) {
+		c.overflowed = true
+	}
+}
+
+// Key types exist so code written against package seal ports unchanged.
+type (
+	PublicKey struct{}
+	SecretKey struct{}
+	RelinKeys struct{}
+)
+
+type KeyGenerator struct{}
+
+func NewKeyGenerator(c *Context) (*KeyGenerator, error) {
+	return &KeyGenerator{}, nil
+}
+
+func (g *KeyGenerator) PublicKey() (*PublicKey, error) {
+	return &PublicKey{}, nil
+}
+
+func (g *KeyGenerator) SecretKey() (*SecretKey, error) {
+	return &SecretKey{}, nil
+}
+
+func (g *KeyGenerator) RelinKeys(decompositionBitCount, num int) (*RelinKeys, error) {
+	return &RelinKeys{}, nil
+}
+
+// GaloisKeys records the rotation steps it allows. As in package seal, no
+// steps means every power-of-two rotation in either direction.
+func (g *KeyGenerator) GaloisKeys(decompositionBitCount int, steps ...int) (*GaloisKeys, error) {
+	k := &GaloisKeys{}
+	if len(steps) > 0 {
+		k.steps = make(map[int]bool)
+		for _, s := range steps {
+			k.steps[s] = true
+		}
+	}
+	return k, nil
+}
+
+type Encryptor struct {
+	ctx *Context
+}
+
+func NewEncryptor(c *Context, key *PublicKey) (*Encryptor, error) {
+	return &Encryptor{ctx: c}, nil
+}
+
+// Encrypt adds fresh encryption error to the plaintext's slots.
+func (e *Encryptor) Encrypt(p *Plaintext) (*Ciphertext, error) {
+	c := &Ciphertext{
+		ctx:    e.ctx,
+		values: make([]float64, len(p.values)),
+		level:  p.level,
+		scale:  p.scale,
+		size:   2,
+	}
+	for i, v := range p.values {
+		c.values[i] = v + e.ctx.noise(p.scale)
+	}
+	e.ctx.checkOverflow(c)
+	return c, nil
+}
+
+type Decryptor struct {
+	ctx *Context
+}
+
+func NewDecryptor(c *Context, key *SecretKey) (*Decryptor, error) {
+	return &Decryptor{ctx: c}, nil
+}
+
+// Decrypt returns the slot values, or random garbage of the size SEAL would
+// produce if the ciphertext overflowed.
+func (d *Decryptor) Decrypt(c *Ciphertext) (*Plaintext, error) {
+	if c.size != 2 && c.size != 3 {
+		return nil, errorf("Decryptor.Decrypt", ErrInvalidArgument, "ciphertext of size %d", c.size)
+	}
+	p := &Plaintext{
+		values: append([]float64(nil), c.values...),
+		level:  c.level,
+		scale:  c.scale,
+	}
+	if c.overflowed {
+		bound := math.Exp2(float64(d.ctx.modulusBits(c.level)-1)) / c.scale
+		for i := range p.values {
+			p.values[i] = (2*d.ctx.rand.Float64() - 1) * bound
+		}
+	}
+	return p, nil
+}
+
+type CKKSEncoder struct {
+	ctx *Context
+}
+
+func NewCKKSEncoder(c *Context) (*CKKSEncoder, error) {
+	return &CKKSEncoder{ctx: c}, nil
+}
+
+func (e *CKKSEncoder) SlotCount() int {
+	return e.ctx.SlotCount()
+}
+
+func (e *CKKSEncoder) Encode(num float64) (*Plaintext, error) {
+	return e.EncodeScale(num, DefaultScale)
+}
+
+func (e *CKKSEncoder) EncodeScale(num, scale float64) (*Plaintext, error) {
+	return e.EncodeParmsIDScale(num, &ParmsID{}, scale)
+}
+
+// EncodeParmsIDScale fills every slot with num, as the encoder of package
+// seal does for a scalar.
+func (e *CKKSEncoder) EncodeParmsIDScale(num float64, p *ParmsID, scale float64) (*Plaintext, error) {
+	values := make([]float64, e.ctx.SlotCount())
+	for i := range values {
+		values[i] = num
+	}
+	return e.encode("CKKSEncoder.EncodeParmsIDScale", values, p, scale)
+}
+
+func (e *CKKSEncoder) EncodeVector(values []float64) (*Plaintext, error) {
+	return e.EncodeVectorScale(values, DefaultScale)
+}
+
+func (e *CKKSEncoder) EncodeVectorScale(values []float64, scale float64) (*Plaintext, error) {
+	return e.EncodeVectorParmsIDScale(values, &ParmsID{}, scale)
+}
+
+func (e *CKKSEncoder) EncodeVectorParmsIDScale(values []float64, p *ParmsID, scale float64) (*Plaintext, error) {
+	if len(values) > e.ctx.SlotCount() {
+		return nil, errorf("CKKSEncoder.EncodeVector", ErrInvalidArgument, "%d values for %d slots", len(values), e.ctx.SlotCount())
+	}
+	padded := make([]float64, e.ctx.SlotCount())
+	copy(padded, values)
+	return e.encode("CKKSEncoder.EncodeVector", padded, p, scale)
+}
+
+// encode rounds values to the precision the scale allows.
+func (e *CKKSEncoder) encode(op string, values []float64, p *ParmsID, scale float64) (*Plaintext, error) {
+	level := len(e.ctx.params.CoeffModulusBits) - 1
+	if p.set {
+		var err error
+		if level, err = e.ctx.ChainIndex(p); err != nil {
+			return nil, err
+		}
+	}
+	if scale <= 0 || math.Log2(scale) >= float64(e.ctx.modulusBits(level)) {
+		return nil, errorf(op, ErrScaleOutOfBounds, "scale 2^%.1f at level %d", math.Log2(scale), level)
+	}
+	for i, v := range values {
+		values[i] = math.Round(v*scale) / scale
+	}
+	return &Plaintext{values: values, level: level, scale: scale}, nil
+}
+
+func (e *CKKSEncoder) Decode(p *Plaintext) (float64, error) {
+	return p.values[0], nil
+}
+
+func (e *CKKSEncoder) DecodeVector(p *Plaintext) ([]float64, error) {
+	return append([]float64(nil), p.values...), nil
+}
@@ -0,0 +1,154 @@
+package sim
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+type kit struct {
+	ctx       *Context
+	enc       *CKKSEncoder
+	encryptor *Encryptor
+	decryptor *Decryptor
+	eval      *Evaluator
+	auto      *AutoEvaluator
+}
+
+func newKit(t *testing.T, params Params) *kit {
+	t.Helper()
+	ctx, err := NewContext(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keygen, _ := NewKeyGenerator(ctx)
+	pub, _ := keygen.PublicKey()
+	sec, _ := keygen.SecretKey()
+	relin, _ := keygen.RelinKeys(60, 1)
+	k := &kit{ctx: ctx}
+	k.enc, _ = NewCKKSEncoder(ctx)
+	k.encryptor, _ = NewEncryptor(ctx, pub)
+	k.decryptor, _ = NewDecryptor(ctx, sec)
+	k.eval, _ = NewEvaluator(ctx)
+	if k.auto, err = NewAutoEvaluator(ctx, relin); err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func (k *kit) encrypt(t *testing.T, values ...float64) *Ciphertext {
+	t.Helper()
+	p, err := k.enc.EncodeVector(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func (k *kit) decrypt(t *testing.T, c *Ciphertext) []float64 {
+	t.Helper()
+	p, err := k.decryptor.Decrypt(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := k.enc.DecodeVector(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestRoundTrip(t *testing.T) {
+	k := newKit(t, DefaultParams())
+	in := []float64{0.5, -3, 1e6}
+	out := k.decrypt(t, k.encrypt(t, in...))
+	if len(out) != k.enc.SlotCount() {
+		t.Fatalf("decoded %d slots; want %d", len(out), k.enc.SlotCount())
+	}
+	for i, v := range in {
+		if math.Abs(out[i]-v) > 1e-9 {
+			t.Errorf("slot %d = %g; want %g", i, out[i], v)
+		}
+	}
+}
+
+func TestLevelsAndScale(t *testing.T) {
+	k := newKit(t, DefaultParams())
+	a := k.encrypt(t, 1.5, -2)
+	top := a.Level()
+	b, err := k.eval.Multiply(a, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Size() != 3 || b.Scale() != math.Exp2(120) {
+		t.Errorf("product = %v; want size 3 at scale 2^120", b)
+	}
+	if err := k.eval.AddInplace(a, b); !errors.Is(err, ErrScaleMismatch) {
+		t.Errorf("AddInplace(mismatched scales) = %v; want ErrScaleMismatch", err)
+	}
+
+	sq, err := k.auto.Square(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sq.Size() != 2 || sq.Level() != top-1 {
+		t.Errorf("auto square = %v; want size 2 at level %d", sq, top-1)
+	}
+	cube, err := k.auto.Mul(sq, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{1.5 * 1.5 * 1.5, -8}
+	out := k.decrypt(t, cube)
+	for i, w := range want {
+		if math.Abs(out[i]-w) > 1e-6 {
+			t.Errorf("slot %d = %g; want %g", i, out[i], w)
+		}
+	}
+
+	// The product's scale drifts from 2^60, since the primes are smaller.
+	sum, err := k.auto.Add(sq, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := k.decrypt(t, sum); math.Abs(out[0]-3.75) > 1e-6 {
+		t.Errorf("x^2 + x = %g; want 3.75", out[0])
+	}
+
+	for a.Level() > 0 {
+		if err := k.eval.ModSwitchToNextInplace(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := k.eval.RescaleToNextInplace(a); !errors.Is(err, ErrEndOfModulusChain) {
+		t.Errorf("RescaleToNextInplace(level 0) = %v; want ErrEndOfModulusChain", err)
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	k := newKit(t, Params{PolyModulusDegree: 8, CoeffModulusBits: []int{40, 30}})
+	p, err := k.enc.EncodeVectorScale([]float64{1 << 20}, 1<<30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := k.encryptor.Encrypt(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Overflowed() {
+		t.Fatal("fresh ciphertext overflowed")
+	}
+	if err := k.eval.ModSwitchToNextInplace(a); err != nil {
+		t.Fatal(err)
+	}
+	if !a.Overflowed() {
+		t.Error("2^50 in a 40-bit modulus did not overflow")
+	}
+	if out := k.decrypt(t, a); out[0] == 1<<20 {
+		t.Error("overflowed ciphertext decrypted correctly")
+	}
+}